@@ -0,0 +1,102 @@
+package go_raptor
+
+/** the time it takes to get to the from stop - 0 when no access durations were passed */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) GetAccessDurationInSeconds(unique_stop_id ID) TimestampInSeconds {
+	return TimestampInSeconds(prepared_input.Input.AccessDurationsInSecondsByUniqueStopId[unique_stop_id])
+}
+
+/** the time it takes to get from the to stop to the final destination - 0 when no egress durations were passed */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) GetEgressDurationInSeconds(unique_stop_id ID) TimestampInSeconds {
+	return TimestampInSeconds(prepared_input.Input.EgressDurationsInSecondsByUniqueStopId[unique_stop_id])
+}
+
+/**
+ * completes the journeys found by any of the engines - setting the mode, bike and estimated flags, transfer paths and intermediate stops of the legs
+ * and including the access and egress durations in the departure and arrival times - after which the departure times are compressed and they are ranked.
+ * the mode is the direction of the search which found the journeys - which is not necessarily the mode of the input when calling an engine directly
+ */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) finalizeJourneys(journeys []Journey[ID], mode RaptorMode) []Journey[ID] {
+	journeys = prepared_input.annotateJourneyModes(journeys)
+	for index := range journeys {
+		journey := &journeys[index]
+		for leg_index := range journey.Legs {
+			leg := &journey.Legs[leg_index]
+			leg.WithBike = prepared_input.Input.BikeProfile.Enabled
+			if leg.ViaTrip == nil {
+				leg.Path = prepared_input.getTransferPath(*leg)
+			} else if prepared_input.Filters.EstimatedUniqueTripServiceIds[leg.ViaTrip.UniqueTripServiceID] || prepared_input.Input.IncludeIntermediateStops {
+				/* the trip is shared with the segments so it is copied before updating it */
+				via_trip := *leg.ViaTrip
+				via_trip.IsEstimated = prepared_input.Filters.EstimatedUniqueTripServiceIds[via_trip.UniqueTripServiceID]
+				if prepared_input.Input.IncludeIntermediateStops {
+					via_trip.IntermediateStops = prepared_input.GetIntermediateStopCalls(via_trip)
+				}
+				leg.ViaTrip = &via_trip
+			}
+		}
+		journey.AccessDurationInSeconds = prepared_input.GetAccessDurationInSeconds(journey.FromUniqueStopID)
+		journey.EgressDurationInSeconds = prepared_input.GetEgressDurationInSeconds(journey.ToUniqueStopID)
+		journey.DepartureTimeInSeconds -= journey.AccessDurationInSeconds
+		journey.ArrivalTimeInSeconds += journey.EgressDurationInSeconds
+	}
+	if prepared_input.Input.CompressDepartureTimes && mode == RaptorModeDepartAt {
+		journeys = prepared_input.compressDepartureTimes(journeys)
+	}
+	return RankJourneys(prepared_input.filterJourneysWithinSlack(journeys, mode), prepared_input.Input.Ranking)
+}
+
+/** drops the journeys arriving more than the slack after the best arrival (departing more than the slack before the best departure for arrive by) */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) filterJourneysWithinSlack(journeys []Journey[ID], mode RaptorMode) []Journey[ID] {
+	if !prepared_input.Input.PruneByBestArrival || prepared_input.Input.BestArrivalSlackInSeconds == 0 || len(journeys) == 0 {
+		return journeys
+	}
+	best_arrival_time, best_departure_time := journeys[0].ArrivalTimeInSeconds, journeys[0].DepartureTimeInSeconds
+	for _, journey := range journeys {
+		best_arrival_time = min(best_arrival_time, journey.ArrivalTimeInSeconds)
+		best_departure_time = max(best_departure_time, journey.DepartureTimeInSeconds)
+	}
+	journeys_within_slack := []Journey[ID]{}
+	for _, journey := range journeys {
+		if mode == RaptorModeArriveBy && journey.DepartureTimeInSeconds >= best_departure_time-prepared_input.Input.BestArrivalSlackInSeconds ||
+			mode == RaptorModeDepartAt && journey.ArrivalTimeInSeconds <= best_arrival_time+prepared_input.Input.BestArrivalSlackInSeconds {
+			journeys_within_slack = append(journeys_within_slack, journey)
+		}
+	}
+	return journeys_within_slack
+}
+
+/**
+ * gets the path of the transfer walked for the leg. the arrive by searches walk a transfer backwards when there is no transfer
+ * in the other direction - there is no path for the leg then as the path of the transfer leads the other way
+ */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) getTransferPath(leg RoundSegmentSpan[ID]) []TransferPathStep[ID] {
+	if leg.TransferIndex < 0 || leg.TransferIndex >= len(prepared_input.Input.Transfers) {
+		return nil
+	}
+	transfer := prepared_input.Input.Transfers[leg.TransferIndex]
+	if transfer.GetFromUniqueStopID() != leg.FromUniqueStopID || transfer.GetToUniqueStopID() != leg.ToUniqueStopID {
+		return nil
+	}
+	if transfer_with_path, has_path := any(transfer).(GtfsTransferWithPath[ID]); has_path {
+		return transfer_with_path.GetPath()
+	}
+	return nil
+}
+
+/** gets the calls of the trip between the from and to stop of the leg from the prepared stop times */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) GetIntermediateStopCalls(via_trip ViaTrip[ID]) []StopCall[ID] {
+	stop_calls := []StopCall[ID]{}
+	for _, stop_time_index := range prepared_input.StopTimesByUniqueTripServiceId[via_trip.UniqueTripServiceID] {
+		stop_time := prepared_input.Input.StopTimes[stop_time_index]
+		if stop_time.GetStopSequence() <= via_trip.FromStopSequenceInTrip || stop_time.GetStopSequence() >= via_trip.ToStopSequenceInTrip {
+			continue
+		}
+		stop_calls = append(stop_calls, StopCall[ID]{
+			UniqueStopID:           stop_time.GetUniqueStopID(),
+			StopSequence:           stop_time.GetStopSequence(),
+			ArrivalTimeInSeconds:   stop_time.GetArrivalTimeInSeconds(),
+			DepartureTimeInSeconds: stop_time.GetDepartureTimeInSeconds(),
+		})
+	}
+	return stop_calls
+}
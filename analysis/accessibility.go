@@ -0,0 +1,154 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+/** travel time used in the results when a stop could not be reached for a given percentile */
+const UnreachableTravelTimeInSeconds raptor.TimestampInSeconds = -1
+
+type AccessibilityWindowInput[ID raptor.UniqueGtfsIdLike, StopType raptor.GtfsStop[ID], TransferType raptor.GtfsTransfer[ID], StopTimeType raptor.GtfsStopTime[ID]] struct {
+	/* the base raptor input - the TimeInSeconds, Mode and ToStops are ignored since every sample is a one-to-all depart at search */
+	Input raptor.SimpleRaptorInput[ID, StopType, TransferType, StopTimeType]
+
+	/* the departure time window to sample - both ends are inclusive */
+	WindowStartInSeconds raptor.TimestampInSeconds
+	WindowEndInSeconds   raptor.TimestampInSeconds
+	/* the time between two sampled departure times - defaults to 60 seconds */
+	SampleIntervalInSeconds raptor.TimestampInSeconds
+
+	/* the travel time percentiles to compute per stop (0-100) - defaults to the median */
+	Percentiles []float64
+	/* a stop counts as reachable for a sample if it can be reached within this travel time (0 means no limit) */
+	TravelTimeThresholdInSeconds raptor.TimestampInSeconds
+	/* optional weights (ie jobs, population) per stop used to compute the cumulative opportunities */
+	OpportunitiesByUniqueStopId map[ID]float64
+}
+
+type StopAccessibility[ID raptor.UniqueGtfsIdLike] struct {
+	UniqueStopID ID
+	/* number of samples in which the stop was reachable within the threshold */
+	ReachableSamples int
+	/* share of the samples in which the stop was reachable within the threshold (0-1) */
+	ReachableFraction float64
+	/* travel time for each requested percentile in the same order as the input (regardless of the threshold) - UnreachableTravelTimeInSeconds if not reachable */
+	TravelTimePercentilesInSeconds []raptor.TimestampInSeconds
+}
+
+type AccessibilityResult[ID raptor.UniqueGtfsIdLike] struct {
+	/* the sampled departure times */
+	DepartureTimesInSeconds []raptor.TimestampInSeconds
+	Percentiles             []float64
+	StopsByUniqueStopId     map[ID]StopAccessibility[ID]
+	/* sum of the opportunities of all stops whose percentile travel time is within the threshold - in the same order as the percentiles */
+	CumulativeOpportunitiesByPercentile []float64
+	/* average over all samples of the sum of the opportunities reachable within the threshold */
+	AverageCumulativeOpportunities float64
+}
+
+/**
+ * samples every departure minute (or configured interval) in the window with a one-to-all search
+ * and aggregates the travel times per reached stop
+ */
+func ComputeAccessibility[ID raptor.UniqueGtfsIdLike, StopType raptor.GtfsStop[ID], TransferType raptor.GtfsTransfer[ID], StopTimeType raptor.GtfsStopTime[ID]](
+	input AccessibilityWindowInput[ID, StopType, TransferType, StopTimeType],
+) AccessibilityResult[ID] {
+	sample_interval := input.SampleIntervalInSeconds
+	if sample_interval <= 0 {
+		sample_interval = 60
+	}
+	percentiles := input.Percentiles
+	if len(percentiles) == 0 {
+		percentiles = []float64{50}
+	}
+
+	/* prepare the lookup maps once so each sample can reuse them */
+	base_input := input.Input
+	base_input.Mode = raptor.RaptorModeDepartAt
+	base_input.ToStops = nil
+	base_input = base_input.WithPreparedInput(raptor.PrepareRaptorInput(base_input))
+
+	departure_times := []raptor.TimestampInSeconds{}
+	for departure_time := input.WindowStartInSeconds; departure_time <= input.WindowEndInSeconds; departure_time += sample_interval {
+		departure_times = append(departure_times, departure_time)
+	}
+
+	/* travel times per stop - only samples in which the stop was reached are recorded */
+	travel_times_by_unique_stop_id := map[ID][]raptor.TimestampInSeconds{}
+	total_cumulative_opportunities := 0.0
+	for _, departure_time := range departure_times {
+		sample_input := base_input
+		sample_input.TimeInSeconds = departure_time
+		segments := raptor.SimpleRaptorDepartAtOneToAll(sample_input)
+
+		for unique_stop_id, segment := range segments {
+			travel_time := segment.ArrivalTimeInSeconds - departure_time
+			travel_times_by_unique_stop_id[unique_stop_id] = append(travel_times_by_unique_stop_id[unique_stop_id], travel_time)
+			if isWithinThreshold(travel_time, input.TravelTimeThresholdInSeconds) {
+				total_cumulative_opportunities += input.OpportunitiesByUniqueStopId[unique_stop_id]
+			}
+		}
+	}
+
+	result := AccessibilityResult[ID]{
+		DepartureTimesInSeconds:             departure_times,
+		Percentiles:                         percentiles,
+		StopsByUniqueStopId:                 make(map[ID]StopAccessibility[ID], len(travel_times_by_unique_stop_id)),
+		CumulativeOpportunitiesByPercentile: make([]float64, len(percentiles)),
+	}
+	if len(departure_times) == 0 {
+		return result
+	}
+	result.AverageCumulativeOpportunities = total_cumulative_opportunities / float64(len(departure_times))
+
+	for unique_stop_id, travel_times := range travel_times_by_unique_stop_id {
+		sort.Slice(travel_times, func(i, j int) bool { return travel_times[i] < travel_times[j] })
+		reachable_samples := 0
+		for _, travel_time := range travel_times {
+			if isWithinThreshold(travel_time, input.TravelTimeThresholdInSeconds) {
+				reachable_samples++
+			}
+		}
+		stop_accessibility := StopAccessibility[ID]{
+			UniqueStopID:                   unique_stop_id,
+			ReachableSamples:               reachable_samples,
+			ReachableFraction:              float64(reachable_samples) / float64(len(departure_times)),
+			TravelTimePercentilesInSeconds: make([]raptor.TimestampInSeconds, len(percentiles)),
+		}
+		for index, percentile := range percentiles {
+			travel_time := GetTravelTimePercentile(travel_times, len(departure_times), percentile)
+			stop_accessibility.TravelTimePercentilesInSeconds[index] = travel_time
+			if travel_time != UnreachableTravelTimeInSeconds && isWithinThreshold(travel_time, input.TravelTimeThresholdInSeconds) {
+				result.CumulativeOpportunitiesByPercentile[index] += input.OpportunitiesByUniqueStopId[unique_stop_id]
+			}
+		}
+		result.StopsByUniqueStopId[unique_stop_id] = stop_accessibility
+	}
+
+	return result
+}
+
+/**
+ * gets the nearest-rank percentile of the travel times where the samples which are missing
+ * (sample_count - len(sorted_travel_times)) are treated as unreachable (infinitely long)
+ */
+func GetTravelTimePercentile(sorted_travel_times []raptor.TimestampInSeconds, sample_count int, percentile float64) raptor.TimestampInSeconds {
+	if sample_count == 0 {
+		return UnreachableTravelTimeInSeconds
+	}
+	rank := int(math.Ceil(percentile / 100 * float64(sample_count)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted_travel_times) {
+		return UnreachableTravelTimeInSeconds
+	}
+	return sorted_travel_times[rank-1]
+}
+
+func isWithinThreshold(travel_time raptor.TimestampInSeconds, threshold raptor.TimestampInSeconds) bool {
+	return threshold <= 0 || travel_time <= threshold
+}
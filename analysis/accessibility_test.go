@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeAccessibility(t *testing.T) {
+	var epoch_20250823_070000_edt int64 = 1755946800
+
+	/* a train every 10 minutes from High St to Franklin Av taking 20 minutes */
+	stop_times := []raptor.GtfsStopTimeStruct[string]{}
+	for index := range 12 {
+		trip_id := fmt.Sprintf("A_%d", index)
+		departure_time := epoch_20250823_070000_edt + int64(index*600)
+		stop_times = append(stop_times,
+			raptor.GtfsStopTimeStruct[string]{UniqueStopID: "High St", UniqueTripID: trip_id, UniqueTripServiceID: trip_id, StopSequence: 1, ArrivalTimeInSeconds: departure_time, DepartureTimeInSeconds: departure_time},
+			raptor.GtfsStopTimeStruct[string]{UniqueStopID: "Franklin Av", UniqueTripID: trip_id, UniqueTripServiceID: trip_id, StopSequence: 2, ArrivalTimeInSeconds: departure_time + 1200, DepartureTimeInSeconds: departure_time + 1200},
+		)
+	}
+
+	result := ComputeAccessibility(AccessibilityWindowInput[string, raptor.GtfsStopStruct[string], raptor.GtfsTransferStruct[string], raptor.GtfsStopTimeStruct[string]]{
+		Input: raptor.SimpleRaptorInput[string, raptor.GtfsStopStruct[string], raptor.GtfsTransferStruct[string], raptor.GtfsStopTimeStruct[string]]{
+			FromStops:        []raptor.GtfsStopStruct[string]{{UniqueID: "High St"}},
+			Transfers:        []raptor.GtfsTransferStruct[string]{},
+			StopTimes:        stop_times,
+			MaximumTransfers: 4,
+		},
+		WindowStartInSeconds:         epoch_20250823_070000_edt,
+		WindowEndInSeconds:           epoch_20250823_070000_edt + 540,
+		Percentiles:                  []float64{0, 50, 100},
+		TravelTimeThresholdInSeconds: 1500,
+		OpportunitiesByUniqueStopId:  map[string]float64{"Franklin Av": 100},
+	})
+
+	assert.Len(t, result.DepartureTimesInSeconds, 10)
+
+	franklin_av := result.StopsByUniqueStopId["Franklin Av"]
+	/* departing at minute 0 takes 20 minutes, departing at minute 1 up to 9 means waiting for the next train */
+	assert.Equal(t, []raptor.TimestampInSeconds{1200, 1440, 1740}, franklin_av.TravelTimePercentilesInSeconds)
+	/* only departures at minute 0 and 5 up to 9 are within 25 minutes */
+	assert.Equal(t, 6, franklin_av.ReachableSamples)
+	assert.InDelta(t, 0.6, franklin_av.ReachableFraction, 0.0001)
+	assert.Equal(t, []float64{100, 100, 0}, result.CumulativeOpportunitiesByPercentile)
+	assert.InDelta(t, 60, result.AverageCumulativeOpportunities, 0.0001)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/gtfs"
+)
+
+type raptorInput = raptor.SimpleRaptorInput[string, gtfs.Stop, gtfs.Transfer, raptor.GtfsStopTimeStruct[string]]
+
+/** the number of service days of which the prepared inputs are kept in memory */
+const maximumCachedServiceDays = 7
+
+/** a loaded feed with everything needed to answer the requests - it is replaced as a whole when reloading */
+type dataset struct {
+	feed     *gtfs.Feed
+	store    *gtfs.FeedMetadataStore
+	location *time.Location
+	/* ordered by name for the stop listing */
+	stops                   []gtfs.Stop
+	child_stops_by_stop_id  map[string][]gtfs.Stop
+	transfers               []gtfs.Transfer
+	trips_by_unique_trip_id map[string]raptor.GtfsTrip[string]
+	routes_by_unique_id     map[string]raptor.GtfsRoute[string]
+	/* the engine of which the preprocessing is cached with the prepared inputs */
+	engine raptor.RaptorEngine
+
+	/* the prepared inputs are built on the first request for a service day - the mutex only guards the map and not the building */
+	inputs_mutex           sync.Mutex
+	inputs_by_service_date map[string]*cachedInput
+}
+
+/** a prepared input which is being built by the first request for its service day - the other requests wait for it to be ready */
+type cachedInput struct {
+	ready chan struct{}
+	input raptorInput
+	err   error
+}
+
+func newDataset(feed *gtfs.Feed, engine raptor.RaptorEngine) (*dataset, error) {
+	location, err := feed.GetLocation()
+	if err != nil {
+		return nil, err
+	}
+	data := &dataset{
+		feed:                    feed,
+		store:                   gtfs.NewFeedMetadataStore(feed),
+		location:                location,
+		stops:                   append([]gtfs.Stop{}, feed.Stops...),
+		child_stops_by_stop_id:  map[string][]gtfs.Stop{},
+		transfers:               feed.GetFootpathTransfers(),
+		trips_by_unique_trip_id: make(map[string]raptor.GtfsTrip[string], len(feed.Trips)),
+		routes_by_unique_id:     make(map[string]raptor.GtfsRoute[string], len(feed.Routes)),
+		engine:                  engine,
+		inputs_by_service_date:  map[string]*cachedInput{},
+	}
+	sort.SliceStable(data.stops, func(i, j int) bool { return data.stops[i].StopName < data.stops[j].StopName })
+	for _, stop := range feed.Stops {
+		if stop.ParentStation != "" && stop.LocationType == gtfs.LocationTypeStop {
+			data.child_stops_by_stop_id[stop.ParentStation] = append(data.child_stops_by_stop_id[stop.ParentStation], stop)
+		}
+	}
+	for _, trip := range feed.Trips {
+		data.trips_by_unique_trip_id[trip.TripID] = trip
+	}
+	for _, route := range feed.Routes {
+		data.routes_by_unique_id[route.RouteID] = route
+	}
+	return data, nil
+}
+
+/** gets the stops to route from or to - stations are expanded into their platforms */
+func (data *dataset) getStops(stop_id string) ([]gtfs.Stop, error) {
+	if child_stops, has_child_stops := data.child_stops_by_stop_id[stop_id]; has_child_stops {
+		return child_stops, nil
+	}
+	stop, has_stop := data.store.StopsByID[stop_id]
+	if !has_stop {
+		return nil, fmt.Errorf("unknown stop %q", stop_id)
+	}
+	return []gtfs.Stop{stop}, nil
+}
+
+/** finds the stops of which the name contains the query - all stops for an empty query */
+func (data *dataset) findStops(query string) []gtfs.Stop {
+	query = strings.ToLower(strings.TrimSpace(query))
+	stops := []gtfs.Stop{}
+	for _, stop := range data.stops {
+		if strings.Contains(strings.ToLower(stop.StopName), query) {
+			stops = append(stops, stop)
+		}
+	}
+	return stops
+}
+
+/**
+ * gets the prepared input for searches at the time - this contains the trips of the service day of the time and the surrounding days
+ * since trips can run past midnight (or searches can continue into the next day). the connections or transfers of the csa and trip based
+ * engines are prepared along with it since they are expensive to calculate on every request. each service day is only built once at a time
+ * and the requests for the other service days are not blocked while it is
+ */
+func (data *dataset) getInput(at time.Time) (raptorInput, error) {
+	at = at.In(data.location)
+	service_date := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, data.location)
+	key := service_date.Format(gtfs.DateLayout)
+
+	data.inputs_mutex.Lock()
+	if cached_input, has_cached_input := data.inputs_by_service_date[key]; has_cached_input {
+		data.inputs_mutex.Unlock()
+		<-cached_input.ready
+		return cached_input.input, cached_input.err
+	}
+	if len(data.inputs_by_service_date) >= maximumCachedServiceDays {
+		data.inputs_by_service_date = map[string]*cachedInput{}
+	}
+	cached_input := &cachedInput{ready: make(chan struct{})}
+	data.inputs_by_service_date[key] = cached_input
+	data.inputs_mutex.Unlock()
+
+	cached_input.input, cached_input.err = data.buildInput(service_date)
+	if cached_input.err != nil {
+		/* the next request tries again */
+		data.inputs_mutex.Lock()
+		if data.inputs_by_service_date[key] == cached_input {
+			delete(data.inputs_by_service_date, key)
+		}
+		data.inputs_mutex.Unlock()
+	}
+	close(cached_input.ready)
+	return cached_input.input, cached_input.err
+}
+
+func (data *dataset) buildInput(service_date time.Time) (raptorInput, error) {
+	timetable, err := data.feed.BuildTimetable([]time.Time{service_date.AddDate(0, 0, -1), service_date, service_date.AddDate(0, 0, 1)})
+	if err != nil {
+		return raptorInput{}, err
+	}
+	input := raptorInput{
+		Transfers:                     data.transfers,
+		StopTimes:                     timetable.StopTimes,
+		EstimatedUniqueTripServiceIDs: timetable.EstimatedUniqueTripServiceIDs,
+		TripsByUniqueTripId:           data.trips_by_unique_trip_id,
+		RoutesByUniqueRouteId:         data.routes_by_unique_id,
+		IncludeIntermediateStops:      true,
+	}
+	prepared_input := raptor.PrepareRaptorInput(input)
+	input = input.WithPreparedInput(prepared_input)
+	switch data.engine {
+	case raptor.RaptorEngineCsa:
+		connections := raptor.PrepareCsaConnections(prepared_input)
+		input.CsaConnections = &connections
+	case raptor.RaptorEngineTripBased:
+		transfers := raptor.PrepareTripBasedTransfers(prepared_input)
+		input.TripBasedTransfers = &transfers
+	}
+	return input, nil
+}
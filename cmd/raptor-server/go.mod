@@ -0,0 +1,26 @@
+module github.com/liammartens/go-raptor/cmd/raptor-server
+
+go 1.24.5
+
+require (
+	github.com/liammartens/go-raptor v0.0.0-00010101000000-000000000000
+	github.com/liammartens/go-raptor/rpc v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.10.0
+	google.golang.org/grpc v1.75.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.41.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
+	google.golang.org/protobuf v1.36.10 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/liammartens/go-raptor => ../../
+	github.com/liammartens/go-raptor/rpc => ../../rpc
+)
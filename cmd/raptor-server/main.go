@@ -0,0 +1,106 @@
+/**
+ * raptor-server serves journey planning over HTTP for a GTFS zip, directory or snapshot
+ *
+ *	GET  /plan?from=&to=&time=&arrive_by=&max_transfers=
+ *	GET  /isochrone?from=&time=&max_duration=&max_transfers=
+ *	GET  /stops?q=
+ *	POST /reload (only with -http-reload)
+ *	GET  /otp/routers/default/plan?fromPlace=&toPlace=&date=&time=&arriveBy=&maxTransfers=
+ *
+ * the RaptorService gRPC API (see rpc/raptorpb/raptor.proto) is served as well when passing -grpc-addr
+ *
+ * the feed is reloaded without downtime on SIGHUP (or POST /reload) - and the server shuts down gracefully on SIGINT or SIGTERM
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/gtfs"
+	"github.com/liammartens/go-raptor/rpc/raptorpb"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	feed_path := flag.String("feed", "", "path to a GTFS zip, directory or snapshot")
+	address := flag.String("addr", ":8080", "address to listen on")
+	grpc_address := flag.String("grpc-addr", "", "address to serve the gRPC API on - disabled when empty")
+	engine := flag.String("engine", string(raptor.RaptorEngineRaptor), "routing engine (raptor, csa or trip_based)")
+	otp_feed_id := flag.String("otp-feed-id", "", "feed ID which prefixes the IDs of the OpenTripPlanner plan responses")
+	http_reload := flag.Bool("http-reload", false, "serve POST /reload - which is not authenticated so only enable it when the server is not exposed")
+	shutdown_timeout := flag.Duration("shutdown-timeout", 10*time.Second, "time to wait for the requests in progress when shutting down")
+	flag.Parse()
+	if *feed_path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s, err := newServer(func() (*gtfs.Feed, error) { return gtfs.LoadFeedPath(*feed_path) }, raptor.RaptorEngine(*engine))
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.otp_feed_id = *otp_feed_id
+	s.http_reload = *http_reload
+	http_server := &http.Server{Addr: *address, Handler: s.handler()}
+
+	reload_signals := make(chan os.Signal, 1)
+	signal.Notify(reload_signals, syscall.SIGHUP)
+	go func() {
+		for range reload_signals {
+			if err := s.reload(); err != nil {
+				log.Printf("reload failed: %v", err)
+			}
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		log.Printf("listening on %s", *address)
+		if err := http_server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	var grpc_server *grpc.Server
+	if *grpc_address != "" {
+		listener, err := net.Listen("tcp", *grpc_address)
+		if err != nil {
+			log.Fatal(err)
+		}
+		grpc_server = grpc.NewServer()
+		raptorpb.RegisterRaptorServiceServer(grpc_server, s.rpcServer())
+		go func() {
+			log.Printf("serving gRPC on %s", *grpc_address)
+			if err := grpc_server.Serve(listener); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	/* the requests in progress are finished before exiting */
+	<-ctx.Done()
+	log.Printf("shutting down")
+	shutdown_ctx, cancel := context.WithTimeout(context.Background(), *shutdown_timeout)
+	defer cancel()
+	if grpc_server != nil {
+		go func() {
+			<-shutdown_ctx.Done()
+			grpc_server.Stop()
+		}()
+		grpc_server.GracefulStop()
+	}
+	if err := http_server.Shutdown(shutdown_ctx); err != nil {
+		log.Printf("shutdown failed: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/otp"
+)
+
+var (
+	otpDateLayouts = []string{"2006-01-02", "01-02-2006"}
+	otpTimeLayouts = []string{"15:04:05", "15:04", "3:04pm", "3:04 pm", "3:04PM", "3:04 PM"}
+)
+
+/**
+ * plans journeys like the OpenTripPlanner REST plan API - the places have to be stop IDs (optionally prefixed by a name and "::"
+ * and by the OTP feed ID) since the journeys are planned between stops
+ */
+func (s *server) handleOtpPlan(writer http.ResponseWriter, request *http.Request) {
+	data := s.dataset.Load()
+	query := request.URL.Query()
+	request_parameters := map[string]string{}
+	for key := range query {
+		request_parameters[key] = query.Get(key)
+	}
+
+	from_stops, err := data.getStops(s.getOtpStopID(query.Get("fromPlace")))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	to_stops, err := data.getStops(s.getOtpStopID(query.Get("toPlace")))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	at, err := parseOtpDateTime(query.Get("date"), query.Get("time"), s.now().In(data.location))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	maximum_transfers, err := parseInt(query.Get("maxTransfers"), defaultMaximumTransfers)
+	if err != nil || maximum_transfers < 0 || maximum_transfers > maximumMaximumTransfers {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("maxTransfers should be between 0 and %d", maximumMaximumTransfers))
+		return
+	}
+
+	input, err := data.getInput(at)
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err)
+		return
+	}
+	input.FromStops = from_stops
+	input.ToStops = to_stops
+	input.TimeInSeconds = at.Unix()
+	input.MaximumTransfers = maximum_transfers
+	input.Engine = s.engine
+	input.Mode = raptor.RaptorModeDepartAt
+	if query.Get("arriveBy") == "true" {
+		input.Mode = raptor.RaptorModeArriveBy
+	}
+	writeJSON(writer, http.StatusOK, otp.NewPlanResponse(raptor.SimpleRaptor(input), data.store, otp.Options[string]{
+		FeedID:            s.otp_feed_id,
+		GeometryStore:     data.store,
+		RequestParameters: request_parameters,
+	}))
+}
+
+func (s *server) getOtpStopID(place string) string {
+	if separator_index := strings.LastIndex(place, "::"); separator_index != -1 {
+		place = place[separator_index+2:]
+	}
+	if s.otp_feed_id != "" {
+		place = strings.TrimPrefix(place, s.otp_feed_id+":")
+	}
+	return place
+}
+
+/** parses the OTP date and time in the timezone of the feed - the missing parts default to the current date or time */
+func parseOtpDateTime(date_value string, time_value string, now time.Time) (time.Time, error) {
+	date := now
+	if date_value != "" {
+		parsed_date, err := parseWithLayouts(date_value, otpDateLayouts, now.Location())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", date_value)
+		}
+		date = parsed_date
+	}
+	if time_value == "" {
+		return time.Date(date.Year(), date.Month(), date.Day(), now.Hour(), now.Minute(), now.Second(), 0, now.Location()), nil
+	}
+	parsed_time, err := parseWithLayouts(time_value, otpTimeLayouts, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", time_value)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), parsed_time.Hour(), parsed_time.Minute(), parsed_time.Second(), 0, now.Location()), nil
+}
+
+func parseWithLayouts(value string, layouts []string, location *time.Location) (time.Time, error) {
+	var err error
+	for _, layout := range layouts {
+		var parsed time.Time
+		if parsed, err = time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/gtfs"
+	"github.com/liammartens/go-raptor/rpc"
+)
+
+const (
+	defaultMaximumTransfers           = 4
+	maximumMaximumTransfers           = 8
+	defaultIsochroneDurationInSeconds = 3600
+)
+
+/**
+ * serves the journey planning endpoints - the dataset is swapped atomically when reloading
+ * so the requests which are in progress keep using the previous dataset
+ */
+type server struct {
+	load_feed func() (*gtfs.Feed, error)
+	engine    raptor.RaptorEngine
+	/* the feed ID which prefixes the IDs of the OpenTripPlanner responses */
+	otp_feed_id string
+	/* whether POST /reload is served - it is unauthenticated so the feed is otherwise only reloaded on SIGHUP */
+	http_reload  bool
+	dataset      atomic.Pointer[dataset]
+	reload_mutex sync.Mutex
+	/* used as the default time of the searches */
+	now func() time.Time
+}
+
+type stopResponse struct {
+	StopID        string  `json:"stop_id"`
+	Name          string  `json:"name"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	LocationType  int     `json:"location_type"`
+	ParentStation string  `json:"parent_station,omitempty"`
+}
+
+type isochroneStopResponse struct {
+	stopResponse
+	ArrivalTimeInSeconds raptor.TimestampInSeconds `json:"arrival_time"`
+	DurationInSeconds    raptor.TimestampInSeconds `json:"duration"`
+	Transfers            int                       `json:"transfers"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newServer(load_feed func() (*gtfs.Feed, error), engine raptor.RaptorEngine) (*server, error) {
+	if !slices.Contains([]raptor.RaptorEngine{raptor.RaptorEngineRaptor, raptor.RaptorEngineCsa, raptor.RaptorEngineTripBased}, engine) {
+		return nil, fmt.Errorf("unknown engine %q - expected raptor, csa or trip_based", engine)
+	}
+	s := &server{load_feed: load_feed, engine: engine, now: time.Now}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+/**
+ * loads the feed again and starts using it once it is ready - the previous dataset is kept when loading fails. the input of the
+ * current service day is prepared before switching so the requests after a reload do not have to wait for it
+ */
+func (s *server) reload() error {
+	s.reload_mutex.Lock()
+	defer s.reload_mutex.Unlock()
+	started_at := time.Now()
+	feed, err := s.load_feed()
+	if err != nil {
+		return fmt.Errorf("failed to load the feed: %w", err)
+	}
+	data, err := newDataset(feed, s.engine)
+	if err != nil {
+		return fmt.Errorf("failed to prepare the feed: %w", err)
+	}
+	if _, err := data.getInput(s.now()); err != nil {
+		return fmt.Errorf("failed to prepare the input: %w", err)
+	}
+	s.dataset.Store(data)
+	log.Printf("loaded %d stops, %d trips and %d stop times in %s", len(feed.Stops), len(feed.Trips), len(feed.StopTimes), time.Since(started_at))
+	return nil
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /plan", s.handlePlan)
+	mux.HandleFunc("GET /isochrone", s.handleIsochrone)
+	mux.HandleFunc("GET /stops", s.handleStops)
+	if s.http_reload {
+		mux.HandleFunc("POST /reload", s.handleReload)
+	}
+	mux.HandleFunc("GET /otp/routers/{router}/plan", s.handleOtpPlan)
+	return mux
+}
+
+/** the gRPC RaptorService using the current dataset - the times of its requests are unix timestamps */
+func (s *server) rpcServer() *rpc.Server[gtfs.Stop, gtfs.Transfer, raptor.GtfsStopTimeStruct[string]] {
+	return &rpc.Server[gtfs.Stop, gtfs.Transfer, raptor.GtfsStopTimeStruct[string]]{
+		GetInput: func(at raptor.TimestampInSeconds) (raptorInput, error) {
+			return s.dataset.Load().getInput(time.Unix(at, 0))
+		},
+		GetStops: func(stop_id string) ([]gtfs.Stop, error) {
+			return s.dataset.Load().getStops(stop_id)
+		},
+		Engine:           s.engine,
+		MaximumTransfers: defaultMaximumTransfers,
+	}
+}
+
+/** plans journeys between two stops - the stations are expanded into their platforms */
+func (s *server) handlePlan(writer http.ResponseWriter, request *http.Request) {
+	data := s.dataset.Load()
+	query := request.URL.Query()
+	from_stops, err := data.getStops(query.Get("from"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	to_stops, err := data.getStops(query.Get("to"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	at, err := s.parseTime(query.Get("time"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	maximum_transfers, err := parseInt(query.Get("max_transfers"), defaultMaximumTransfers)
+	if err != nil || maximum_transfers < 0 || maximum_transfers > maximumMaximumTransfers {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("max_transfers should be between 0 and %d", maximumMaximumTransfers))
+		return
+	}
+	arrive_by := query.Get("arrive_by") == "true" || query.Get("arrive_by") == "1"
+
+	input, err := data.getInput(at)
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err)
+		return
+	}
+	input.FromStops = from_stops
+	input.ToStops = to_stops
+	input.TimeInSeconds = at.Unix()
+	input.MaximumTransfers = maximum_transfers
+	input.Engine = s.engine
+	input.Mode = raptor.RaptorModeDepartAt
+	if arrive_by {
+		input.Mode = raptor.RaptorModeArriveBy
+	}
+	journeys := raptor.SimpleRaptor(input)
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"journeys": raptor.EnrichJourneys(journeys, data.store),
+	})
+}
+
+/** finds the earliest arrival at every stop reachable from the stop within the duration */
+func (s *server) handleIsochrone(writer http.ResponseWriter, request *http.Request) {
+	data := s.dataset.Load()
+	query := request.URL.Query()
+	from_stops, err := data.getStops(query.Get("from"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	at, err := s.parseTime(query.Get("time"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	maximum_duration, err := parseInt(query.Get("max_duration"), defaultIsochroneDurationInSeconds)
+	if err != nil || maximum_duration <= 0 {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("max_duration should be a positive number of seconds"))
+		return
+	}
+	maximum_transfers, err := parseInt(query.Get("max_transfers"), defaultMaximumTransfers)
+	if err != nil || maximum_transfers < 0 || maximum_transfers > maximumMaximumTransfers {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("max_transfers should be between 0 and %d", maximumMaximumTransfers))
+		return
+	}
+
+	input, err := data.getInput(at)
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err)
+		return
+	}
+	input.FromStops = from_stops
+	input.TimeInSeconds = at.Unix()
+	input.MaximumTransfers = maximum_transfers
+	input.Mode = raptor.RaptorModeDepartAt
+	input.StopTimeCutOffTimestamp = at.Unix() + raptor.TimestampInSeconds(maximum_duration)
+
+	stops := []isochroneStopResponse{}
+	for unique_stop_id, segment := range raptor.SimpleRaptorDepartAtOneToAll(input) {
+		duration := segment.ArrivalTimeInSeconds - input.TimeInSeconds
+		if duration > raptor.TimestampInSeconds(maximum_duration) {
+			continue
+		}
+		transfers := 0
+		for _, span := range segment.Spans {
+			if span.ViaTrip != nil {
+				transfers++
+			}
+		}
+		if transfers > 0 {
+			transfers--
+		}
+		stops = append(stops, isochroneStopResponse{
+			stopResponse:         toStopResponse(data.store.StopsByID[unique_stop_id]),
+			ArrivalTimeInSeconds: segment.ArrivalTimeInSeconds,
+			DurationInSeconds:    duration,
+			Transfers:            transfers,
+		})
+	}
+	sort.Slice(stops, func(i, j int) bool {
+		if stops[i].DurationInSeconds != stops[j].DurationInSeconds {
+			return stops[i].DurationInSeconds < stops[j].DurationInSeconds
+		}
+		return stops[i].StopID < stops[j].StopID
+	})
+	writeJSON(writer, http.StatusOK, map[string]any{"stops": stops})
+}
+
+/** lists the stops - optionally filtered by the q name query */
+func (s *server) handleStops(writer http.ResponseWriter, request *http.Request) {
+	data := s.dataset.Load()
+	stops := []stopResponse{}
+	for _, stop := range data.findStops(request.URL.Query().Get("q")) {
+		stops = append(stops, toStopResponse(stop))
+	}
+	writeJSON(writer, http.StatusOK, map[string]any{"stops": stops})
+}
+
+func (s *server) handleReload(writer http.ResponseWriter, request *http.Request) {
+	if err := s.reload(); err != nil {
+		writeError(writer, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, map[string]any{"reloaded": true})
+}
+
+/** parses the time as unix seconds or RFC3339 - defaulting to now */
+func (s *server) parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return s.now(), nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time should be unix seconds or RFC3339: %q", value)
+	}
+	return at, nil
+}
+
+func parseInt(value string, default_value int) (int, error) {
+	if value == "" {
+		return default_value, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func toStopResponse(stop gtfs.Stop) stopResponse {
+	return stopResponse{
+		StopID:        stop.StopID,
+		Name:          stop.StopName,
+		Latitude:      stop.Latitude,
+		Longitude:     stop.Longitude,
+		LocationType:  stop.LocationType,
+		ParentStation: stop.ParentStation,
+	}
+}
+
+func writeJSON(writer http.ResponseWriter, status int, body any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(body); err != nil {
+		log.Printf("failed to write the response: %v", err)
+	}
+}
+
+func writeError(writer http.ResponseWriter, status int, err error) {
+	writeJSON(writer, status, errorResponse{Error: err.Error()})
+}
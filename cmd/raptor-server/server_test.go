@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/gtfs"
+	"github.com/liammartens/go-raptor/otp"
+	"github.com/liammartens/go-raptor/rpc/raptorpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestFeed(t *testing.T, trip_headsign string) *gtfs.Feed {
+	feed, err := gtfs.LoadFeed(fstest.MapFS{
+		"agency.txt": {Data: []byte("agency_id,agency_name,agency_url,agency_timezone\nMTA,MTA,https://mta.info,America/New_York\n")},
+		"stops.txt": {Data: []byte("stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station\n" +
+			"S,Astoria Station,40.1,-73.1,1,\n" +
+			"A,Astoria,40.1,-73.1,0,S\n" +
+			"B,Broadway,40.2,-73.2,0,\n" +
+			"C,Canal St,40.3,-73.3,0,\n" +
+			"D,Delancey St,40.31,-73.31,0,\n")},
+		"routes.txt":   {Data: []byte("route_id,agency_id,route_short_name,route_long_name,route_type\nN,MTA,N,Broadway Express,1\n")},
+		"trips.txt":    {Data: []byte("route_id,service_id,trip_id,trip_headsign\nN,WKD,N1," + trip_headsign + "\n")},
+		"calendar.txt": {Data: []byte("service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\nWKD,1,1,1,1,1,0,0,20250101,20251231\n")},
+		"transfers.txt": {Data: []byte("from_stop_id,to_stop_id,transfer_type,min_transfer_time\n" +
+			"C,D,2,120\n" +
+			"D,C,2,120\n")},
+		"stop_times.txt": {Data: []byte("trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"N1,08:00:00,08:00:00,A,1\n" +
+			"N1,08:05:00,08:06:00,B,2\n" +
+			"N1,08:10:00,08:10:00,C,3\n")},
+	})
+	assert.NoError(t, err)
+	return feed
+}
+
+func doRequest(t *testing.T, handler http.Handler, method string, target string, response any) int {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+	return recorder.Code
+}
+
+func TestServer(t *testing.T) {
+	headsign := "Coney Island"
+	s, err := newServer(func() (*gtfs.Feed, error) { return getTestFeed(t, headsign), nil }, raptor.RaptorEngineRaptor)
+	assert.NoError(t, err)
+	location, _ := time.LoadLocation("America/New_York")
+	s.now = func() time.Time { return time.Date(2025, 7, 3, 7, 30, 0, 0, location) }
+	handler := s.handler()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/reload", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "should only serve the reload endpoint when enabled")
+	s.http_reload = true
+	handler = s.handler()
+
+	var plan struct {
+		Journeys []raptor.EnrichedJourney[string]
+	}
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "GET", "/plan?from=S&to=C", &plan))
+	assert.Len(t, plan.Journeys, 1)
+	assert.Equal(t, "A", plan.Journeys[0].FromStop.UniqueStopID, "should route from the platforms of the station")
+	assert.Equal(t, "Coney Island", plan.Journeys[0].Legs[0].Trip.Headsign)
+	assert.Equal(t, "Broadway", plan.Journeys[0].Legs[0].Trip.IntermediateStops[0].Stop.Name)
+	assert.Equal(t, time.Date(2025, 7, 3, 8, 10, 0, 0, location).Unix(), plan.Journeys[0].ArrivalTimeInSeconds)
+
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "GET", "/plan?from=A&to=C&arrive_by=true&time=2025-07-03T08:30:00-04:00", &plan))
+	assert.Len(t, plan.Journeys, 1)
+	assert.Equal(t, time.Date(2025, 7, 3, 8, 0, 0, 0, location).Unix(), plan.Journeys[0].DepartureTimeInSeconds)
+
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "GET", "/plan?from=A&to=C&time=2025-07-05T07:30:00-04:00", &plan))
+	assert.Empty(t, plan.Journeys, "should not use the trips which do not run on the weekend")
+
+	var error_response errorResponse
+	assert.Equal(t, http.StatusBadRequest, doRequest(t, handler, "GET", "/plan?from=A&to=X", &error_response))
+	assert.Contains(t, error_response.Error, "unknown stop")
+	assert.Equal(t, http.StatusBadRequest, doRequest(t, handler, "GET", "/plan?from=A&to=C&max_transfers=100", &error_response))
+
+	var isochrone struct {
+		Stops []isochroneStopResponse
+	}
+	get_stop_ids := func() []string {
+		stop_ids := []string{}
+		for _, stop := range isochrone.Stops {
+			stop_ids = append(stop_ids, stop.StopID)
+		}
+		return stop_ids
+	}
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "GET", "/isochrone?from=A&max_duration=2450", &isochrone))
+	assert.Equal(t, []string{"A", "B", "C"}, get_stop_ids(), "should only contain the stops within the duration")
+	assert.Equal(t, raptor.TimestampInSeconds(35*60), isochrone.Stops[1].DurationInSeconds)
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "GET", "/isochrone?from=A&max_duration=3600", &isochrone))
+	assert.Equal(t, []string{"A", "B", "C", "D"}, get_stop_ids(), "should include the stops reached by transferring")
+
+	var stops struct {
+		Stops []stopResponse
+	}
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "GET", "/stops?q=astoria", &stops))
+	assert.Equal(t, []string{"Astoria", "Astoria Station"}, []string{stops.Stops[0].Name, stops.Stops[1].Name})
+
+	s.otp_feed_id = "MTA"
+	var otp_plan otp.PlanResponse
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "GET", "/otp/routers/default/plan?fromPlace=Astoria::MTA:S&toPlace=MTA:C&date=2025-07-03&time=7:30am", &otp_plan))
+	assert.Len(t, otp_plan.Plan.Itineraries, 1)
+	assert.Equal(t, "MTA:A", otp_plan.Plan.From.StopID)
+	assert.Equal(t, time.Date(2025, 7, 3, 8, 10, 0, 0, location).UnixMilli(), otp_plan.Plan.Itineraries[0].EndTime)
+	assert.Equal(t, "SUBWAY", otp_plan.Plan.Itineraries[0].Legs[0].Mode)
+	otp_plan = otp.PlanResponse{}
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "GET", "/otp/routers/default/plan?fromPlace=MTA:A&toPlace=MTA:C&date=07-05-2025&time=09:00", &otp_plan))
+	assert.Equal(t, "PATH_NOT_FOUND", otp_plan.Error.Message)
+
+	rpc_response, err := s.rpcServer().Plan(context.Background(), &raptorpb.PlanRequest{FromStopIds: []string{"S"}, ToStopIds: []string{"C"}, Time: s.now().Unix()})
+	assert.NoError(t, err)
+	assert.Len(t, rpc_response.Journeys, 1)
+	assert.Equal(t, "A", rpc_response.Journeys[0].FromStopId, "should route from the platforms of the station over gRPC")
+	assert.Equal(t, time.Date(2025, 7, 3, 8, 10, 0, 0, location).Unix(), rpc_response.Journeys[0].ArrivalTime)
+
+	headsign = "Ditmars Blvd"
+	var reload map[string]any
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "POST", "/reload", &reload))
+	assert.Equal(t, http.StatusOK, doRequest(t, handler, "GET", "/plan?from=A&to=C", &plan))
+	assert.Equal(t, "Ditmars Blvd", plan.Journeys[0].Legs[0].Trip.Headsign, "should use the reloaded feed")
+}
+
+func TestDataset_EnginePreprocessing(t *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+	at := time.Date(2025, 7, 3, 7, 30, 0, 0, location)
+
+	data, err := newDataset(getTestFeed(t, "Coney Island"), raptor.RaptorEngineTripBased)
+	assert.NoError(t, err)
+	input, err := data.getInput(at)
+	assert.NoError(t, err)
+	assert.NotNil(t, input.TripBasedTransfers, "should prepare the trip based transfers with the input")
+	assert.Nil(t, input.CsaConnections)
+	cached_input, err := data.getInput(at.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Same(t, input.TripBasedTransfers, cached_input.TripBasedTransfers, "should re-use the transfers of the service day")
+
+	/* the concurrent requests for a service day which is not cached yet wait for the first one to build it */
+	concurrent_inputs := make([]raptorInput, 4)
+	wait_group := sync.WaitGroup{}
+	for index := range concurrent_inputs {
+		wait_group.Add(1)
+		go func() {
+			defer wait_group.Done()
+			concurrent_inputs[index], _ = data.getInput(at.AddDate(0, 0, 1))
+		}()
+	}
+	wait_group.Wait()
+	for _, concurrent_input := range concurrent_inputs {
+		assert.Same(t, concurrent_inputs[0].TripBasedTransfers, concurrent_input.TripBasedTransfers, "should build the service day once")
+	}
+
+	data, err = newDataset(getTestFeed(t, "Coney Island"), raptor.RaptorEngineCsa)
+	assert.NoError(t, err)
+	input, err = data.getInput(at)
+	assert.NoError(t, err)
+	assert.NotNil(t, input.CsaConnections)
+	assert.Nil(t, input.TripBasedTransfers)
+
+	s, err := newServer(func() (*gtfs.Feed, error) { return getTestFeed(t, "Coney Island"), nil }, raptor.RaptorEngineTripBased)
+	assert.NoError(t, err)
+	s.now = func() time.Time { return at }
+	assert.NoError(t, s.reload())
+	_, is_prepared := s.dataset.Load().inputs_by_service_date[at.Format(gtfs.DateLayout)]
+	assert.True(t, is_prepared, "should prepare the input of the current service day when reloading")
+	var plan struct {
+		Journeys []raptor.EnrichedJourney[string]
+	}
+	assert.Equal(t, http.StatusOK, doRequest(t, s.handler(), "GET", "/plan?from=S&to=C", &plan))
+	assert.Len(t, plan.Journeys, 1)
+
+	_, err = newServer(func() (*gtfs.Feed, error) { return getTestFeed(t, "Coney Island"), nil }, raptor.RaptorEngine("dijkstra"))
+	assert.ErrorContains(t, err, "unknown engine")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/gtfs"
+)
+
+type raptorInput = raptor.SimpleRaptorInput[string, gtfs.Stop, gtfs.Transfer, raptor.GtfsStopTimeStruct[string]]
+
+/** builds the input with the trips running on the dates and the lookups of the feed - the stops, time and mode are left to the caller */
+func newInput(feed *gtfs.Feed, dates []time.Time) (raptorInput, error) {
+	timetable, err := feed.BuildTimetable(dates)
+	if err != nil {
+		return raptorInput{}, err
+	}
+	input := raptorInput{
+		Transfers:                     feed.GetFootpathTransfers(),
+		StopTimes:                     timetable.StopTimes,
+		EstimatedUniqueTripServiceIDs: timetable.EstimatedUniqueTripServiceIDs,
+		TripsByUniqueTripId:           make(map[string]raptor.GtfsTrip[string], len(feed.Trips)),
+		RoutesByUniqueRouteId:         make(map[string]raptor.GtfsRoute[string], len(feed.Routes)),
+		StopsByUniqueStopId:           make(map[string]gtfs.Stop, len(feed.Stops)),
+	}
+	for _, trip := range feed.Trips {
+		input.TripsByUniqueTripId[trip.TripID] = trip
+	}
+	for _, route := range feed.Routes {
+		input.RoutesByUniqueRouteId[route.RouteID] = route
+	}
+	/* the stations and entrances are not served by the trips themselves */
+	for _, stop := range feed.Stops {
+		if stop.LocationType == gtfs.LocationTypeStop {
+			input.StopsByUniqueStopId[stop.StopID] = stop
+		}
+	}
+	return input, nil
+}
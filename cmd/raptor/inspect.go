@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/gtfs"
+)
+
+type inspectResult struct {
+	StopCount          int                              `json:"stop_count"`
+	RouteCount         int                              `json:"route_count"`
+	TripCount          int                              `json:"trip_count"`
+	StopTimeCount      int                              `json:"stop_time_count"`
+	FirstServiceDate   string                           `json:"first_service_date"`
+	LastServiceDate    string                           `json:"last_service_date"`
+	TripCountsByDate   map[string]int                   `json:"trip_counts_by_date"`
+	InspectedDates     []string                         `json:"inspected_dates"`
+	InspectedTimetable raptor.RaptorInputReport[string] `json:"inspected_timetable"`
+}
+
+func runInspect(args []string, output io.Writer) error {
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	feed_path := flags.String("feed", "", "path to a GTFS zip, directory or snapshot")
+	date := flags.String("date", "", "first service date of the inspected timetable (ie 2025-08-23) - defaults to today")
+	days := flags.Int("days", 1, "number of service dates of the inspected timetable")
+	limit := flags.Int("limit", 20, "maximum number of stops and warnings to list")
+	as_json := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *feed_path == "" {
+		return fmt.Errorf("--feed is required")
+	}
+	if *days < 1 {
+		return fmt.Errorf("--days should be at least 1")
+	}
+
+	feed, err := gtfs.LoadFeedPath(*feed_path)
+	if err != nil {
+		return err
+	}
+	location, err := feed.GetLocation()
+	if err != nil {
+		return err
+	}
+	first_date := time.Now().In(location)
+	if *date != "" {
+		if first_date, err = time.ParseInLocation("2006-01-02", *date, location); err != nil {
+			return fmt.Errorf("invalid date %q - expected a date like 2025-08-23", *date)
+		}
+	}
+	first_date = time.Date(first_date.Year(), first_date.Month(), first_date.Day(), 0, 0, 0, 0, location)
+
+	result := inspectResult{
+		StopCount:        len(feed.Stops),
+		RouteCount:       len(feed.Routes),
+		TripCount:        len(feed.Trips),
+		StopTimeCount:    len(feed.StopTimes),
+		TripCountsByDate: map[string]int{},
+	}
+	trip_counts, err := feed.GetTripCountsByServiceDate()
+	if err != nil {
+		return err
+	}
+	result.FirstServiceDate = trip_counts[0].Date.Format("2006-01-02")
+	result.LastServiceDate = trip_counts[len(trip_counts)-1].Date.Format("2006-01-02")
+	for _, trip_count := range trip_counts {
+		result.TripCountsByDate[trip_count.Date.Format("2006-01-02")] = trip_count.TripCount
+	}
+
+	dates := []time.Time{}
+	for day := 0; day < *days; day++ {
+		dates = append(dates, first_date.AddDate(0, 0, day))
+		result.InspectedDates = append(result.InspectedDates, dates[day].Format("2006-01-02"))
+	}
+	input, err := newInput(feed, dates)
+	if err != nil {
+		return err
+	}
+	prepared_input := raptor.PrepareRaptorInput(input)
+	result.InspectedTimetable = prepared_input.Inspect()
+
+	if *as_json {
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	printInspectResult(output, result, trip_counts, feed, location, *limit)
+	return nil
+}
+
+func printInspectResult(output io.Writer, result inspectResult, trip_counts []gtfs.ServiceDateTripCount, feed *gtfs.Feed, location *time.Location, limit int) {
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "Feed")
+	fmt.Fprintf(writer, "  stops\t%d\n", result.StopCount)
+	fmt.Fprintf(writer, "  routes\t%d\n", result.RouteCount)
+	fmt.Fprintf(writer, "  trips\t%d\n", result.TripCount)
+	fmt.Fprintf(writer, "  stop times\t%d\n", result.StopTimeCount)
+	fmt.Fprintf(writer, "  service dates\t%s to %s\n", result.FirstServiceDate, result.LastServiceDate)
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, "Trips per service date")
+	for _, trip_count := range trip_counts {
+		fmt.Fprintf(writer, "  %s\t%d\n", trip_count.Date.Format("2006-01-02 Mon"), trip_count.TripCount)
+	}
+	writer.Flush()
+
+	report := result.InspectedTimetable
+	fmt.Fprintf(writer, "\nTimetable of %s\n", strings.Join(result.InspectedDates, ", "))
+	fmt.Fprintf(writer, "  stops\t%d\n", report.StopCount)
+	fmt.Fprintf(writer, "  trips\t%d\n", report.TripCount)
+	fmt.Fprintf(writer, "  trip services\t%d\n", report.TripServiceCount)
+	fmt.Fprintf(writer, "  stop times\t%d\n", report.StopTimeCount)
+	fmt.Fprintf(writer, "  transfers\t%d\n", report.TransferCount)
+	if report.StopTimeCount > 0 {
+		fmt.Fprintf(writer, "  arrivals\t%s to %s\n", formatTimestamp(report.FirstArrivalTimeInSeconds, location), formatTimestamp(report.LastArrivalTimeInSeconds, location))
+	}
+	writer.Flush()
+	fmt.Fprintf(writer, "\nPartitions of %s\n", time.Duration(report.TimePartitionInterval)*time.Second)
+	for _, partition := range report.Partitions {
+		fmt.Fprintf(writer, "  %s\t%d stop times\t%d trip services\n", formatTimestamp(partition.StartTimeInSeconds, location), partition.StopTimeCount, partition.TripServiceCount)
+	}
+	writer.Flush()
+
+	stop_names_by_id := make(map[string]string, len(feed.Stops))
+	for _, stop := range feed.Stops {
+		stop_names_by_id[stop.StopID] = stop.StopName
+	}
+	format_stops := func(stop_ids []string) []string {
+		stops := make([]string, len(stop_ids))
+		for index, stop_id := range stop_ids {
+			stops[index] = formatStop(raptor.EnrichedStop[string]{UniqueStopID: stop_id, StopMetadata: raptor.StopMetadata{Name: stop_names_by_id[stop_id]}})
+		}
+		return stops
+	}
+	printList(output, "Stops without departures", format_stops(report.StopsWithoutDepartures), limit)
+	printList(output, "Isolated stops", format_stops(report.IsolatedStops), limit)
+	printList(output, "Warnings", report.Warnings, limit)
+}
+
+/** prints the first items of the list - followed by the number of items which were left out */
+func printList(output io.Writer, title string, items []string, limit int) {
+	fmt.Fprintf(output, "\n%s (%d)\n", title, len(items))
+	for index, item := range items {
+		if index == limit {
+			fmt.Fprintf(output, "  ... and %d more\n", len(items)-limit)
+			break
+		}
+		fmt.Fprintf(output, "  %s\n", item)
+	}
+}
+
+func formatTimestamp(seconds raptor.TimestampInSeconds, location *time.Location) string {
+	return time.Unix(seconds, 0).In(location).Format("2006-01-02 15:04 MST")
+}
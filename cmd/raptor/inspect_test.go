@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInspect(t *testing.T) {
+	var output bytes.Buffer
+	assert.NoError(t, runInspect([]string{"--feed", "../../gtfslirr.zip", "--date", "2025-08-23", "--days", "2", "--limit", "1"}, &output))
+	assert.Contains(t, output.String(), "service dates  2025-08-14 to 2025-11-09")
+	assert.Contains(t, output.String(), "2025-08-23 Sat  761")
+	assert.Contains(t, output.String(), "Timetable of 2025-08-23, 2025-08-24")
+	assert.Contains(t, output.String(), "Stops without departures (3)\n  Long Island City (118)\n  ... and 2 more")
+
+	output.Reset()
+	assert.NoError(t, runInspect([]string{"--feed", "../../gtfslirr.zip", "--date", "2025-08-23", "--json"}, &output))
+	var result inspectResult
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &result))
+	assert.Equal(t, 761, result.TripCountsByDate["2025-08-23"])
+	assert.Equal(t, []string{"2025-08-23"}, result.InspectedDates)
+	assert.Equal(t, result.InspectedTimetable.TripServiceCount, result.InspectedTimetable.TripCount, "should have a trip service per trip for a single date")
+	assert.NotEmpty(t, result.InspectedTimetable.Partitions)
+	assert.Empty(t, result.InspectedTimetable.Warnings)
+
+	assert.ErrorContains(t, runInspect([]string{"--feed", "../../gtfslirr.zip", "--date", "23-08-2025"}, &output), "invalid date")
+}
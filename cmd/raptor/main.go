@@ -0,0 +1,40 @@
+/**
+ * raptor is a command line tool for debugging journey planning on a GTFS zip, directory or snapshot
+ *
+ *	raptor plan --feed gtfslirr.zip --from 237 --to 27 --depart 2025-08-23T08:00 --max-transfers 3
+ *	raptor plan --feed gtfslirr.zip --from "Penn Station" --to Babylon --arrive 2025-08-23T10:00 --json
+ *	raptor inspect --feed gtfslirr.zip --date 2025-08-23 --days 2
+ */
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+var commands = map[string]func(args []string, output io.Writer) error{
+	"plan":    runPlan,
+	"inspect": runInspect,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+	if err := commands[os.Args[1]](os.Args[2:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "raptor %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func printUsage(output io.Writer) {
+	fmt.Fprintln(output, "usage: raptor <command> [flags]")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "commands:")
+	fmt.Fprintln(output, "  plan     plans journeys between two stops")
+	fmt.Fprintln(output, "  inspect  reports the contents of a feed and validates its timetable")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "run raptor <command> --help for the flags of a command")
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/gtfs"
+)
+
+var engines = []raptor.RaptorEngine{raptor.RaptorEngineRaptor, raptor.RaptorEngineCsa, raptor.RaptorEngineTripBased}
+
+var sortOrders = []raptor.RaptorJourneySortOrder{
+	raptor.RaptorJourneySortByArrival,
+	raptor.RaptorJourneySortByDeparture,
+	raptor.RaptorJourneySortByDuration,
+	raptor.RaptorJourneySortByTransfers,
+	raptor.RaptorJourneySortByWalking,
+}
+
+var timeLayouts = []string{"2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02 15:04:05"}
+
+func runPlan(args []string, output io.Writer) error {
+	flags := flag.NewFlagSet("plan", flag.ContinueOnError)
+	feed_path := flags.String("feed", "", "path to a GTFS zip, directory or snapshot")
+	from := flags.String("from", "", "stop ID or name to depart from - stations are expanded into their platforms")
+	to := flags.String("to", "", "stop ID or name to arrive at")
+	depart := flags.String("depart", "", "local departure time (ie 2025-08-23T08:00) - defaults to now")
+	arrive := flags.String("arrive", "", "local arrival time - plans the journeys arriving by this time instead")
+	maximum_transfers := flags.Int("max-transfers", 4, "maximum number of transfers")
+	prune := flags.Bool("prune", false, "only plan the journeys arriving no later than the best one (departing for --arrive)")
+	slack := flags.Int("slack", 0, "with --prune also plan the journeys arriving within this many minutes of the best one")
+	compress := flags.Bool("compress", false, "depart as late as possible without arriving later")
+	sort_by := flags.String("sort", "", "sort the journeys by arrival, departure, duration, transfers or walking")
+	max_results := flags.Int("max-results", 0, "maximum number of journeys to print after sorting them - 0 prints all")
+	engine := flags.String("engine", string(raptor.RaptorEngineRaptor), "routing engine (raptor, csa or trip_based)")
+	as_json := flags.Bool("json", false, "print the enriched journeys as JSON")
+	print_stats := flags.Bool("stats", false, "print the work done in every round of the search")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *feed_path == "" || *from == "" || *to == "" {
+		return fmt.Errorf("--feed, --from and --to are required")
+	}
+	if *depart != "" && *arrive != "" {
+		return fmt.Errorf("only one of --depart and --arrive can be passed")
+	}
+	if !slices.Contains(engines, raptor.RaptorEngine(*engine)) {
+		return fmt.Errorf("unknown engine %q - expected raptor, csa or trip_based", *engine)
+	}
+	if *sort_by != "" && !slices.Contains(sortOrders, raptor.RaptorJourneySortOrder(*sort_by)) {
+		return fmt.Errorf("unknown sort order %q - expected arrival, departure, duration, transfers or walking", *sort_by)
+	}
+
+	feed, err := gtfs.LoadFeedPath(*feed_path)
+	if err != nil {
+		return err
+	}
+	location, err := feed.GetLocation()
+	if err != nil {
+		return err
+	}
+	from_stops, err := findStops(feed, *from)
+	if err != nil {
+		return err
+	}
+	to_stops, err := findStops(feed, *to)
+	if err != nil {
+		return err
+	}
+	mode := raptor.RaptorModeDepartAt
+	at := time.Now().In(location)
+	if *depart != "" {
+		at, err = parseLocalTime(*depart, location)
+	} else if *arrive != "" {
+		mode = raptor.RaptorModeArriveBy
+		at, err = parseLocalTime(*arrive, location)
+	}
+	if err != nil {
+		return err
+	}
+
+	/* the surrounding days are included for the trips running past midnight and the searches continuing into the next day */
+	service_date := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, location)
+	input, err := newInput(feed, []time.Time{service_date.AddDate(0, 0, -1), service_date, service_date.AddDate(0, 0, 1)})
+	if err != nil {
+		return err
+	}
+	input.FromStops = from_stops
+	input.ToStops = to_stops
+	input.Mode = mode
+	input.TimeInSeconds = at.Unix()
+	input.MaximumTransfers = *maximum_transfers
+	input.PruneByBestArrival = *prune
+	input.BestArrivalSlackInSeconds = raptor.TimestampInSeconds(*slack * 60)
+	input.CompressDepartureTimes = *compress
+	input.Ranking = raptor.RaptorJourneyRanking{
+		SortBy:     raptor.RaptorJourneySortOrder(*sort_by),
+		MaxResults: *max_results,
+	}
+	input.Engine = raptor.RaptorEngine(*engine)
+	collector := &raptor.RaptorStatsCollector{}
+	input.Observer = collector
+	journeys := raptor.EnrichJourneys(raptor.SimpleRaptor(input), gtfs.NewFeedMetadataStore(feed))
+
+	if *as_json {
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(journeys)
+	}
+	printJourneys(output, journeys, mode, at)
+	if *print_stats {
+		printStats(output, collector.GetLastQuery())
+	}
+	return nil
+}
+
+/** finds the stops by their ID or (case insensitive) name - stations are expanded into their platforms */
+func findStops(feed *gtfs.Feed, query string) ([]gtfs.Stop, error) {
+	matched_stops := []gtfs.Stop{}
+	for _, stop := range feed.Stops {
+		if stop.StopID == query {
+			matched_stops = []gtfs.Stop{stop}
+			break
+		}
+		if strings.EqualFold(stop.StopName, query) {
+			matched_stops = append(matched_stops, stop)
+		}
+	}
+	if len(matched_stops) == 0 {
+		return nil, fmt.Errorf("unknown stop %q", query)
+	}
+
+	stops := []gtfs.Stop{}
+	for _, matched_stop := range matched_stops {
+		if matched_stop.LocationType != gtfs.LocationTypeStation {
+			stops = append(stops, matched_stop)
+			continue
+		}
+		for _, stop := range feed.Stops {
+			if stop.ParentStation == matched_stop.StopID && stop.LocationType == gtfs.LocationTypeStop {
+				stops = append(stops, stop)
+			}
+		}
+	}
+	return stops, nil
+}
+
+func parseLocalTime(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if at, err := time.ParseInLocation(layout, value, location); err == nil {
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q - expected a local time like 2025-08-23T08:00", value)
+}
+
+func printJourneys(output io.Writer, journeys []raptor.EnrichedJourney[string], mode raptor.RaptorMode, at time.Time) {
+	verb := "departing at"
+	if mode == raptor.RaptorModeArriveBy {
+		verb = "arriving by"
+	}
+	noun := "journeys"
+	if len(journeys) == 1 {
+		noun = "journey"
+	}
+	fmt.Fprintf(output, "%d %s %s %s\n", len(journeys), noun, verb, at.Format("2006-01-02 15:04 MST"))
+	for index, journey := range journeys {
+		transit_legs := 0
+		for _, leg := range journey.Legs {
+			if leg.Trip != nil {
+				transit_legs++
+			}
+		}
+		transfers := "no transfers"
+		if transit_legs == 2 {
+			transfers = "1 transfer"
+		} else if transit_legs > 2 {
+			transfers = fmt.Sprintf("%d transfers", transit_legs-1)
+		}
+		fmt.Fprintf(output, "\nJourney %d: %s -> %s (%s, %s)\n",
+			index+1,
+			formatLocalTime(journey.DepartureTimeInSeconds, at),
+			formatLocalTime(journey.ArrivalTimeInSeconds, at),
+			formatDuration(journey.ArrivalTimeInSeconds-journey.DepartureTimeInSeconds),
+			transfers,
+		)
+		writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+		for _, leg := range journey.Legs {
+			description := ""
+			if leg.Trip != nil {
+				description = getTripDescription(*leg.Trip)
+			}
+			fmt.Fprintf(writer, "  %s\t%s\t%s\t%s -> %s\t%s\n",
+				formatLocalTime(leg.DepartureTimeInSeconds, at),
+				formatLocalTime(leg.ArrivalTimeInSeconds, at),
+				leg.Mode,
+				formatStop(leg.FromStop),
+				formatStop(leg.ToStop),
+				description,
+			)
+		}
+		writer.Flush()
+	}
+}
+
+func printStats(output io.Writer, stats raptor.RaptorQueryStats) {
+	fmt.Fprintf(output, "\nSearched %d round(s) with %s in %s\n", len(stats.Rounds), stats.Engine, stats.Duration)
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "  round\tmarked stops\tstop times\ttrips\tlabels\ttransfers\tduration\t")
+	for _, round := range stats.Rounds {
+		fmt.Fprintf(writer, "  %d\t%d\t%d\t%d\t%d\t%d\t%s\t\n", round.Round, round.MarkedStops, round.StopTimesScanned, round.TripsScanned, round.LabelsImproved, round.TransfersRelaxed, round.Duration)
+	}
+	writer.Flush()
+}
+
+func getTripDescription(trip raptor.EnrichedTrip[string]) string {
+	parts := []string{}
+	if trip.Route != nil {
+		name := trip.Route.ShortName
+		if name == "" {
+			name = trip.Route.LongName
+		}
+		parts = append(parts, name)
+	}
+	if trip.Headsign != "" {
+		parts = append(parts, "to "+trip.Headsign)
+	}
+	parts = append(parts, fmt.Sprintf("(trip %s)", trip.UniqueTripID))
+	if trip.IsEstimated {
+		parts = append(parts, "estimated")
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatStop(stop raptor.EnrichedStop[string]) string {
+	if stop.Name == "" {
+		return stop.UniqueStopID
+	}
+	return fmt.Sprintf("%s (%s)", stop.Name, stop.UniqueStopID)
+}
+
+/** formats the time in the timezone of the search - with the number of days when it is not on the day of the search */
+func formatLocalTime(seconds raptor.TimestampInSeconds, at time.Time) string {
+	local_time := time.Unix(seconds, 0).In(at.Location())
+	formatted := local_time.Format("15:04:05")
+	search_date := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	local_date := time.Date(local_time.Year(), local_time.Month(), local_time.Day(), 0, 0, 0, 0, time.UTC)
+	if days := int(local_date.Sub(search_date).Hours() / 24); days != 0 {
+		formatted += fmt.Sprintf("%+dd", days)
+	}
+	return formatted
+}
+
+func formatDuration(seconds raptor.TimestampInSeconds) string {
+	return fmt.Sprintf("%dh%02dm", seconds/3600, (seconds%3600)/60)
+}
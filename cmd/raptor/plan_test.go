@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPlan(t *testing.T) {
+	var output bytes.Buffer
+	assert.NoError(t, runPlan([]string{"--feed", "../../gtfslirr.zip", "--from", "237", "--to", "27", "--depart", "2025-08-23T08:00", "--max-transfers", "3"}, &output))
+	assert.Contains(t, output.String(), "1 journey departing at 2025-08-23 08:00 EDT")
+	assert.Contains(t, output.String(), "Journey 1: 08:21:00 -> 09:30:00 (1h09m, no transfers)")
+	assert.Contains(t, output.String(), "Penn Station (237) -> Babylon (27)  Babylon Branch to Babylon (trip GO101_25_6118)")
+	assert.NotContains(t, output.String(), "Searched")
+
+	output.Reset()
+	assert.NoError(t, runPlan([]string{"--feed", "../../gtfslirr.zip", "--from", "237", "--to", "27", "--depart", "2025-08-23T08:00", "--max-transfers", "3", "--stats"}, &output))
+	assert.Regexp(t, `Searched \d round\(s\) with raptor in`, output.String())
+	assert.Regexp(t, `round +marked stops +stop times +trips +labels +transfers +duration`, output.String())
+
+	output.Reset()
+	assert.NoError(t, runPlan([]string{"--feed", "../../gtfslirr.zip", "--from", "237", "--to", "27", "--depart", "2025-08-23T08:00", "--prune", "--slack", "120", "--sort", "departure", "--max-results", "1"}, &output))
+	assert.Contains(t, output.String(), "1 journey departing at 2025-08-23 08:00 EDT")
+
+	output.Reset()
+	assert.NoError(t, runPlan([]string{"--feed", "../../gtfslirr.zip", "--from", "237", "--to", "1", "--depart", "2025-08-23T07:00", "--compress"}, &output))
+	assert.Contains(t, output.String(), "Journey 1: 08:21:00 -> 09:09:00", "should depart as late as possible for the same arrival")
+
+	output.Reset()
+	assert.NoError(t, runPlan([]string{"--feed", "../../gtfslirr.zip", "--from", "penn station", "--to", "Babylon", "--arrive", "2025-08-23T09:30", "--json"}, &output))
+	var journeys []raptor.EnrichedJourney[string]
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &journeys))
+	assert.Len(t, journeys, 1)
+	assert.Equal(t, "237", journeys[0].FromStop.UniqueStopID, "should find the stops by their name")
+	assert.LessOrEqual(t, journeys[0].ArrivalTimeInSeconds, raptor.TimestampInSeconds(1755955800))
+
+	assert.ErrorContains(t, runPlan([]string{"--feed", "../../gtfslirr.zip", "--from", "237", "--to", "Atlantis"}, &output), "unknown stop")
+	assert.ErrorContains(t, runPlan([]string{"--feed", "../../gtfslirr.zip", "--from", "237", "--to", "27", "--depart", "08:00"}, &output), "invalid time")
+	assert.Error(t, runPlan([]string{"--from", "237"}, &output), "should require the feed")
+	assert.ErrorContains(t, runPlan([]string{"--feed", "../../gtfslirr.zip", "--from", "237", "--to", "27", "--engine", "dijkstra"}, &output), "unknown engine")
+	assert.ErrorContains(t, runPlan([]string{"--feed", "../../gtfslirr.zip", "--from", "237", "--to", "27", "--sort", "price"}, &output), "unknown sort order")
+}
+
+func TestFormatLocalTime(t *testing.T) {
+	at := time.Date(2025, 8, 23, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, "23:26:00", formatLocalTime(at.Add(26*time.Minute).Unix(), at))
+	assert.Equal(t, "00:14:00+1d", formatLocalTime(at.Add(74*time.Minute).Unix(), at), "should show the days after the search date")
+	assert.Equal(t, "1h09m", formatDuration(69*60))
+}
@@ -0,0 +1,61 @@
+package go_raptor
+
+/**
+ * shifts the departure of the journeys as late as possible without arriving later - by running an arrive by search from the arrival of every journey
+ * to its destination. the arrive by search may not use more transfers than the journey and the journeys which end up the same are only returned once
+ */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) compressDepartureTimes(journeys []Journey[ID]) []Journey[ID] {
+	compressed_journeys := make([]Journey[ID], 0, len(journeys))
+	compressed_journey_spans := newJourneySpansSet[ID]()
+	for _, journey := range journeys {
+		compressed_journey := prepared_input.getCompressedJourney(journey)
+		if compressed_journey_spans.Add(compressed_journey.Legs) {
+			compressed_journeys = append(compressed_journeys, compressed_journey)
+		}
+	}
+	return compressed_journeys
+}
+
+/** gets the latest departing journey between the stops of the journey arriving by its arrival - or the journey itself when there is none departing later */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) getCompressedJourney(journey Journey[ID]) Journey[ID] {
+	from_stop, has_from_stop := findStopByUniqueId(prepared_input.Input.FromStops, journey.FromUniqueStopID)
+	to_stop, has_to_stop := findStopByUniqueId(prepared_input.Input.ToStops, journey.ToUniqueStopID)
+	if !has_from_stop || !has_to_stop {
+		return journey
+	}
+
+	arrive_by_input := prepared_input.Input.WithPreparedInput(*prepared_input)
+	arrive_by_input.FromStops = []StopType{from_stop}
+	arrive_by_input.ToStops = []StopType{to_stop}
+	arrive_by_input.Mode = RaptorModeArriveBy
+	/* the arrival already includes the egress duration - which is what the arrive by search expects */
+	arrive_by_input.TimeInSeconds = journey.ArrivalTimeInSeconds
+	/* every round rides one more trip */
+	arrive_by_input.MaximumTransfers = journey.GetTransferCount() + 1
+	arrive_by_input.BestArrivalSlackInSeconds = 0
+	arrive_by_input.CompressDepartureTimes = false
+	arrive_by_input.Ranking = RaptorJourneyRanking{}
+	arrive_by_input.ViaStops = nil
+	arrive_by_input.Observer = nil
+
+	compressed_journey := journey
+	for _, arrive_by_journey := range SimpleRaptorArriveBy(arrive_by_input) {
+		if arrive_by_journey.DepartureTimeInSeconds > compressed_journey.DepartureTimeInSeconds &&
+			arrive_by_journey.ArrivalTimeInSeconds <= journey.ArrivalTimeInSeconds &&
+			arrive_by_journey.GetTransferCount() <= journey.GetTransferCount() &&
+			arrive_by_journey.PenaltyInSeconds <= journey.PenaltyInSeconds {
+			compressed_journey = arrive_by_journey
+		}
+	}
+	return compressed_journey
+}
+
+func findStopByUniqueId[ID UniqueGtfsIdLike, StopType GtfsStop[ID]](stops []StopType, unique_stop_id ID) (StopType, bool) {
+	for _, stop := range stops {
+		if stop.GetUniqueID() == unique_stop_id {
+			return stop, true
+		}
+	}
+	var empty_stop StopType
+	return empty_stop, false
+}
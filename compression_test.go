@@ -0,0 +1,71 @@
+package go_raptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getCompressionTestInput() SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]] {
+	/* T1 and T2 both go from A to B where T3 continues to C - T1 arrives at B long before T3 departs */
+	return SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "B", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "A", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 1, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+			{UniqueStopID: "B", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 2, ArrivalTimeInSeconds: 1400, DepartureTimeInSeconds: 1400},
+			{UniqueStopID: "B", UniqueTripID: "T3", UniqueTripServiceID: "T3", StopSequence: 1, ArrivalTimeInSeconds: 1500, DepartureTimeInSeconds: 1500},
+			{UniqueStopID: "C", UniqueTripID: "T3", UniqueTripServiceID: "T3", StopSequence: 2, ArrivalTimeInSeconds: 1600, DepartureTimeInSeconds: 1600},
+		},
+		FromStops:        []GtfsStopStruct[string]{{UniqueID: "A"}},
+		ToStops:          []GtfsStopStruct[string]{{UniqueID: "C"}},
+		Mode:             RaptorModeDepartAt,
+		TimeInSeconds:    900,
+		MaximumTransfers: 4,
+	}
+}
+
+func TestSimpleRaptor_CompressDepartureTimes(t *testing.T) {
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			input := getCompressionTestInput()
+			input.Engine = engine
+			journeys := SimpleRaptor(input)
+			assert.Len(t, journeys, 1)
+			assert.Equal(t, TimestampInSeconds(1000), journeys[0].DepartureTimeInSeconds, "should board the first trip without compressing")
+
+			input.CompressDepartureTimes = true
+			journeys = SimpleRaptor(input)
+			assert.Len(t, journeys, 1)
+			assert.Equal(t, TimestampInSeconds(1300), journeys[0].DepartureTimeInSeconds, "should depart on the later trip")
+			assert.Equal(t, TimestampInSeconds(1600), journeys[0].ArrivalTimeInSeconds)
+			assert.Equal(t, "T2", journeys[0].Legs[0].ViaTrip.UniqueTripID)
+		})
+	}
+}
+
+func TestSimpleRaptor_CompressDepartureTimesAccessEgress(t *testing.T) {
+	input := getCompressionTestInput()
+	input.AccessDurationsInSecondsByUniqueStopId = map[string]int{"A": 60}
+	input.EgressDurationsInSecondsByUniqueStopId = map[string]int{"C": 120}
+	input.CompressDepartureTimes = true
+	journeys := SimpleRaptor(input)
+	assert.Len(t, journeys, 1)
+	assert.Equal(t, TimestampInSeconds(1240), journeys[0].DepartureTimeInSeconds, "should include the access duration")
+	assert.Equal(t, TimestampInSeconds(1720), journeys[0].ArrivalTimeInSeconds, "should include the egress duration")
+	assert.Equal(t, TimestampInSeconds(60), journeys[0].AccessDurationInSeconds)
+}
+
+func TestSimpleRaptor_CompressDepartureTimesKeepsTransfers(t *testing.T) {
+	/* the direct trip T4 arrives at the same time as T2 and T3 - compressing should not add a transfer to depart later */
+	input := getCompressionTestInput()
+	input.StopTimes = append(input.StopTimes,
+		GtfsStopTimeStruct[string]{UniqueStopID: "A", UniqueTripID: "T4", UniqueTripServiceID: "T4", StopSequence: 1, ArrivalTimeInSeconds: 950, DepartureTimeInSeconds: 950},
+		GtfsStopTimeStruct[string]{UniqueStopID: "C", UniqueTripID: "T4", UniqueTripServiceID: "T4", StopSequence: 2, ArrivalTimeInSeconds: 1600, DepartureTimeInSeconds: 1600},
+	)
+	input.CompressDepartureTimes = true
+	journeys := SimpleRaptor(input)
+	assert.Len(t, journeys, 1)
+	assert.Equal(t, TimestampInSeconds(950), journeys[0].DepartureTimeInSeconds)
+	assert.Len(t, journeys[0].Legs, 1)
+}
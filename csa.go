@@ -0,0 +1,444 @@
+package go_raptor
+
+import (
+	"math"
+	"sort"
+)
+
+/**
+ * below is a Connection Scan Algorithm (CSA) implementation which works on the same inputs as the raptor implementations
+ * a connection is a pair of consecutive stop times in a trip - these are scanned once per round in order of their departure (or arrival for arrive by)
+ * the rounds bound the number of trips taken which means the results match the raptor journeys (one per improved arrival per number of trips)
+ */
+
+type CsaConnection struct {
+	/* indexes of the stop times in the input StopTimes */
+	DepartureStopTimeIndex int
+	ArrivalStopTimeIndex   int
+	DepartureTimeInSeconds TimestampInSeconds
+	ArrivalTimeInSeconds   TimestampInSeconds
+}
+
+type CsaConnections struct {
+	/* all connections ordered by departure time ascending - used for depart at */
+	ByDepartureTime []CsaConnection
+	/* all connections ordered by arrival time descending - used for arrive by */
+	ByArrivalTime []CsaConnection
+}
+
+func PrepareCsaConnections[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	prepared_input PreparedRaptorInput[ID, StopType, TransferType, StopTimeType],
+) CsaConnections {
+	/* the stop times per trip are expected to be ordered by their stop sequence so every consecutive pair is a connection */
+	by_departure_time := make([]CsaConnection, 0, len(prepared_input.Input.StopTimes))
+	for _, stop_time_indexes := range prepared_input.StopTimesByUniqueTripServiceId {
+		for index := 1; index < len(stop_time_indexes); index++ {
+			departure_stop_time := prepared_input.Input.StopTimes[stop_time_indexes[index-1]]
+			arrival_stop_time := prepared_input.Input.StopTimes[stop_time_indexes[index]]
+			by_departure_time = append(by_departure_time, CsaConnection{
+				DepartureStopTimeIndex: stop_time_indexes[index-1],
+				ArrivalStopTimeIndex:   stop_time_indexes[index],
+				DepartureTimeInSeconds: departure_stop_time.GetDepartureTimeInSeconds(),
+				ArrivalTimeInSeconds:   arrival_stop_time.GetArrivalTimeInSeconds(),
+			})
+		}
+	}
+
+	by_arrival_time := make([]CsaConnection, len(by_departure_time))
+	copy(by_arrival_time, by_departure_time)
+
+	/* ties are broken by the stop time indexes so connections of the same trip are always scanned in trip order */
+	sort.Slice(by_departure_time, func(i, j int) bool {
+		if by_departure_time[i].DepartureTimeInSeconds != by_departure_time[j].DepartureTimeInSeconds {
+			return by_departure_time[i].DepartureTimeInSeconds < by_departure_time[j].DepartureTimeInSeconds
+		}
+		return by_departure_time[i].DepartureStopTimeIndex < by_departure_time[j].DepartureStopTimeIndex
+	})
+	sort.Slice(by_arrival_time, func(i, j int) bool {
+		if by_arrival_time[i].ArrivalTimeInSeconds != by_arrival_time[j].ArrivalTimeInSeconds {
+			return by_arrival_time[i].ArrivalTimeInSeconds > by_arrival_time[j].ArrivalTimeInSeconds
+		}
+		return by_arrival_time[i].ArrivalStopTimeIndex > by_arrival_time[j].ArrivalStopTimeIndex
+	})
+
+	return CsaConnections{
+		ByDepartureTime: by_departure_time,
+		ByArrivalTime:   by_arrival_time,
+	}
+}
+
+func SimpleCsaDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) []Journey[ID] {
+	prepared_input := PrepareRaptorInput(input)
+	connections := input.CsaConnections
+	if connections == nil {
+		prepared_connections := PrepareCsaConnections(prepared_input)
+		connections = &prepared_connections
+	}
+
+	/* the earliest arrival segments with up to the previous round's number of trips - used for boarding */
+	previous_round_segments_by_unique_stop_id := map[ID]RoundSegment[ID]{}
+	for _, from_stop := range input.FromStops {
+		previous_round_segments_by_unique_stop_id[from_stop.GetUniqueID()] = RoundSegment[ID]{
+			UniqueStopID:         from_stop.GetUniqueID(),
+			ArrivalTimeInSeconds: input.TimeInSeconds + prepared_input.GetAccessDurationInSeconds(from_stop.GetUniqueID()),
+			Spans:                []RoundSegmentSpan[ID]{},
+		}
+	}
+
+	potential_journeys_found := []Journey[ID]{}
+	potential_journey_spans := newJourneySpansSet[ID]()
+	stats_recorder := newRaptorStatsRecorder(input.Context, input.Observer, RaptorEngineCsa, RaptorModeDepartAt)
+	/* the connections arriving at or after this time can not lead to a journey within the slack of the best journey found so far */
+	pruning_bound := TimestampInSeconds(math.MaxInt64)
+	best_arrival_time_by_destination_stop_id := map[ID]TimestampInSeconds{}
+
+	/* skip all connections departing before the requested time */
+	first_connection_index := sort.Search(len(connections.ByDepartureTime), func(i int) bool {
+		return connections.ByDepartureTime[i].DepartureTimeInSeconds >= input.TimeInSeconds
+	})
+
+	for range input.MaximumTransfers {
+		stats_recorder.startRound(len(previous_round_segments_by_unique_stop_id))
+		had_improvements_this_round := false
+		/* every round can only improve on the previous one so we start from a copy */
+		current_round_segments_by_unique_stop_id := make(map[ID]RoundSegment[ID], len(previous_round_segments_by_unique_stop_id))
+		for unique_stop_id, segment := range previous_round_segments_by_unique_stop_id {
+			current_round_segments_by_unique_stop_id[unique_stop_id] = segment
+		}
+		/* the stop time index at which we boarded each trip this round */
+		boarded_stop_time_index_by_unique_trip_service_id := map[ID]int{}
+
+		for _, connection := range connections.ByDepartureTime[first_connection_index:] {
+			if input.StopTimeCutOffTimestamp != 0 && connection.DepartureTimeInSeconds > input.StopTimeCutOffTimestamp || connection.DepartureTimeInSeconds >= pruning_bound {
+				break
+			}
+
+			stats_recorder.round.StopTimesScanned++
+			departure_stop_time := prepared_input.Input.StopTimes[connection.DepartureStopTimeIndex]
+			arrival_stop_time := prepared_input.Input.StopTimes[connection.ArrivalStopTimeIndex]
+			boarded_stop_time_index, has_boarded_trip := boarded_stop_time_index_by_unique_trip_service_id[departure_stop_time.GetUniqueTripServiceID()]
+			if !has_boarded_trip {
+				/* we can only board if we arrived at the departure stop in the previous round before the departure */
+				previous_segment, has_previous_segment := previous_round_segments_by_unique_stop_id[departure_stop_time.GetUniqueStopID()]
+				if !has_previous_segment || previous_segment.ArrivalTimeInSeconds > connection.DepartureTimeInSeconds || !prepared_input.CanBoardStopTime(departure_stop_time) {
+					continue
+				}
+				boarded_stop_time_index = connection.DepartureStopTimeIndex
+				boarded_stop_time_index_by_unique_trip_service_id[departure_stop_time.GetUniqueTripServiceID()] = boarded_stop_time_index
+				stats_recorder.round.TripsScanned++
+			}
+
+			/* we can not get off at a banned stop - but the trip itself continues past it */
+			if !prepared_input.CanAlightStopTime(arrival_stop_time) {
+				continue
+			}
+
+			boarded_stop_time := prepared_input.Input.StopTimes[boarded_stop_time_index]
+			boarded_segment := previous_round_segments_by_unique_stop_id[boarded_stop_time.GetUniqueStopID()]
+			boarded_penalty := boarded_segment.PenaltyInSeconds + prepared_input.GetTripPenaltyInSeconds(boarded_stop_time)
+			existing_segment, has_existing_segment := current_round_segments_by_unique_stop_id[arrival_stop_time.GetUniqueStopID()]
+			if connection.ArrivalTimeInSeconds+boarded_penalty >= pruning_bound ||
+				has_existing_segment && existing_segment.ArrivalTimeInSeconds+existing_segment.PenaltyInSeconds <= connection.ArrivalTimeInSeconds+boarded_penalty {
+				continue
+			}
+			had_improvements_this_round = true
+			stats_recorder.round.LabelsImproved++
+
+			updated_spans := make([]RoundSegmentSpan[ID], len(boarded_segment.Spans)+1)
+			copy(updated_spans, boarded_segment.Spans)
+			updated_spans[len(updated_spans)-1] = RoundSegmentSpan[ID]{
+				FromUniqueStopID: boarded_stop_time.GetUniqueStopID(),
+				ToUniqueStopID:   arrival_stop_time.GetUniqueStopID(),
+				ViaTrip: &ViaTrip[ID]{
+					UniqueTripID:           arrival_stop_time.GetUniqueTripID(),
+					UniqueTripServiceID:    arrival_stop_time.GetUniqueTripServiceID(),
+					FromStopSequenceInTrip: boarded_stop_time.GetStopSequence(),
+					ToStopSequenceInTrip:   arrival_stop_time.GetStopSequence(),
+				},
+				DepartureTimeInSecondsFromUniqueStopID: boarded_stop_time.GetDepartureTimeInSeconds(),
+				ArrivalTimeInSecondsToUniqueStopID:     arrival_stop_time.GetArrivalTimeInSeconds(),
+				TransferIndex:                          -1,
+			}
+			arrival_segment := RoundSegment[ID]{
+				UniqueStopID:         arrival_stop_time.GetUniqueStopID(),
+				ArrivalTimeInSeconds: connection.ArrivalTimeInSeconds,
+				Spans:                updated_spans,
+				PenaltyInSeconds:     boarded_penalty,
+			}
+			current_round_segments_by_unique_stop_id[arrival_stop_time.GetUniqueStopID()] = arrival_segment
+			if _, is_destination_stop := prepared_input.ToStopsByUniqueStopId[arrival_stop_time.GetUniqueStopID()]; is_destination_stop && input.PruneByBestArrival {
+				pruning_bound = min(pruning_bound, connection.ArrivalTimeInSeconds+boarded_penalty+prepared_input.GetEgressDurationInSeconds(arrival_stop_time.GetUniqueStopID())+input.BestArrivalSlackInSeconds)
+			}
+
+			/* walking transfers from the arrival stop - these are always later than the connection so they can not affect already scanned connections */
+			csaRelaxTransfersDepartAt(&prepared_input, current_round_segments_by_unique_stop_id, arrival_segment, &stats_recorder.round)
+		}
+
+		/* any destination which was improved this round (and was arrived at by a trip) is a new journey */
+		for _, unique_stop_id := range getSortedKeys(prepared_input.ToStopsByUniqueStopId) {
+			segment, has_segment := current_round_segments_by_unique_stop_id[unique_stop_id]
+			if !has_segment || len(segment.Spans) == 0 || segment.Spans[0].ViaTrip == nil || segment.Spans[len(segment.Spans)-1].ViaTrip == nil {
+				continue
+			}
+			if best_arrival_time, has_best_arrival_time := best_arrival_time_by_destination_stop_id[unique_stop_id]; has_best_arrival_time && best_arrival_time <= segment.ArrivalTimeInSeconds+segment.PenaltyInSeconds {
+				continue
+			}
+			best_arrival_time_by_destination_stop_id[unique_stop_id] = segment.ArrivalTimeInSeconds + segment.PenaltyInSeconds
+			if journey := segment.ToJourney(); potential_journey_spans.Add(journey.Legs) {
+				potential_journeys_found = append(potential_journeys_found, journey)
+			}
+		}
+
+		stats_recorder.endRound()
+		previous_round_segments_by_unique_stop_id = current_round_segments_by_unique_stop_id
+		if !had_improvements_this_round {
+			break
+		}
+	}
+
+	journeys := prepared_input.finalizeJourneys(potential_journeys_found, RaptorModeDepartAt)
+	stats_recorder.endQuery(len(journeys))
+	return journeys
+}
+
+func SimpleCsaArriveBy[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) []Journey[ID] {
+	prepared_input := PrepareRaptorInput(input)
+	connections := input.CsaConnections
+	if connections == nil {
+		prepared_connections := PrepareCsaConnections(prepared_input)
+		connections = &prepared_connections
+	}
+
+	/* the latest departure segments with up to the previous round's number of trips - used for alighting */
+	previous_round_segments_by_unique_stop_id := map[ID]RoundSegment[ID]{}
+	for _, to_stop := range input.ToStops {
+		previous_round_segments_by_unique_stop_id[to_stop.GetUniqueID()] = RoundSegment[ID]{
+			UniqueStopID:         to_stop.GetUniqueID(),
+			ArrivalTimeInSeconds: input.TimeInSeconds - prepared_input.GetEgressDurationInSeconds(to_stop.GetUniqueID()),
+			Spans:                []RoundSegmentSpan[ID]{},
+		}
+	}
+
+	potential_journeys_found := []Journey[ID]{}
+	potential_journey_spans := newJourneySpansSet[ID]()
+	stats_recorder := newRaptorStatsRecorder(input.Context, input.Observer, RaptorEngineCsa, RaptorModeArriveBy)
+	/* the connections departing at or before this time can not lead to a journey within the slack of the best journey found so far */
+	pruning_bound := TimestampInSeconds(math.MinInt64)
+	best_departure_time_by_origin_stop_id := map[ID]TimestampInSeconds{}
+
+	/* skip all connections arriving after the requested time */
+	first_connection_index := sort.Search(len(connections.ByArrivalTime), func(i int) bool {
+		return connections.ByArrivalTime[i].ArrivalTimeInSeconds <= input.TimeInSeconds
+	})
+
+	for range input.MaximumTransfers {
+		stats_recorder.startRound(len(previous_round_segments_by_unique_stop_id))
+		had_improvements_this_round := false
+		current_round_segments_by_unique_stop_id := make(map[ID]RoundSegment[ID], len(previous_round_segments_by_unique_stop_id))
+		for unique_stop_id, segment := range previous_round_segments_by_unique_stop_id {
+			current_round_segments_by_unique_stop_id[unique_stop_id] = segment
+		}
+		/* the stop time index at which we alighted each trip this round */
+		alighted_stop_time_index_by_unique_trip_service_id := map[ID]int{}
+
+		for _, connection := range connections.ByArrivalTime[first_connection_index:] {
+			if input.StopTimeCutOffTimestamp != 0 && connection.ArrivalTimeInSeconds < input.StopTimeCutOffTimestamp || connection.ArrivalTimeInSeconds <= pruning_bound {
+				break
+			}
+
+			stats_recorder.round.StopTimesScanned++
+			departure_stop_time := prepared_input.Input.StopTimes[connection.DepartureStopTimeIndex]
+			arrival_stop_time := prepared_input.Input.StopTimes[connection.ArrivalStopTimeIndex]
+			alighted_stop_time_index, has_alighted_trip := alighted_stop_time_index_by_unique_trip_service_id[arrival_stop_time.GetUniqueTripServiceID()]
+			if !has_alighted_trip {
+				/* we can only alight if the arrival is before the time we need to be at the arrival stop in the previous round */
+				previous_segment, has_previous_segment := previous_round_segments_by_unique_stop_id[arrival_stop_time.GetUniqueStopID()]
+				if !has_previous_segment || previous_segment.ArrivalTimeInSeconds < connection.ArrivalTimeInSeconds || !prepared_input.IsTripAllowed(arrival_stop_time) || !prepared_input.CanAlightStopTime(arrival_stop_time) {
+					continue
+				}
+				alighted_stop_time_index = connection.ArrivalStopTimeIndex
+				alighted_stop_time_index_by_unique_trip_service_id[arrival_stop_time.GetUniqueTripServiceID()] = alighted_stop_time_index
+				stats_recorder.round.TripsScanned++
+			}
+
+			/* we can not board at a banned stop - but the trip itself passes through it */
+			if !prepared_input.IsStopAllowed(departure_stop_time.GetUniqueStopID()) {
+				continue
+			}
+
+			alighted_stop_time := prepared_input.Input.StopTimes[alighted_stop_time_index]
+			alighted_segment := previous_round_segments_by_unique_stop_id[alighted_stop_time.GetUniqueStopID()]
+			boarded_penalty := alighted_segment.PenaltyInSeconds + prepared_input.GetTripPenaltyInSeconds(alighted_stop_time)
+			existing_segment, has_existing_segment := current_round_segments_by_unique_stop_id[departure_stop_time.GetUniqueStopID()]
+			if connection.DepartureTimeInSeconds-boarded_penalty <= pruning_bound ||
+				has_existing_segment && existing_segment.ArrivalTimeInSeconds-existing_segment.PenaltyInSeconds >= connection.DepartureTimeInSeconds-boarded_penalty {
+				continue
+			}
+			had_improvements_this_round = true
+			stats_recorder.round.LabelsImproved++
+
+			updated_spans := append([]RoundSegmentSpan[ID]{
+				{
+					FromUniqueStopID: departure_stop_time.GetUniqueStopID(),
+					ToUniqueStopID:   alighted_stop_time.GetUniqueStopID(),
+					ViaTrip: &ViaTrip[ID]{
+						UniqueTripID:           departure_stop_time.GetUniqueTripID(),
+						UniqueTripServiceID:    departure_stop_time.GetUniqueTripServiceID(),
+						FromStopSequenceInTrip: departure_stop_time.GetStopSequence(),
+						ToStopSequenceInTrip:   alighted_stop_time.GetStopSequence(),
+					},
+					DepartureTimeInSecondsFromUniqueStopID: departure_stop_time.GetDepartureTimeInSeconds(),
+					ArrivalTimeInSecondsToUniqueStopID:     alighted_stop_time.GetArrivalTimeInSeconds(),
+					TransferIndex:                          -1,
+				},
+			}, alighted_segment.Spans...)
+			departure_segment := RoundSegment[ID]{
+				UniqueStopID:         departure_stop_time.GetUniqueStopID(),
+				ArrivalTimeInSeconds: connection.DepartureTimeInSeconds,
+				Spans:                updated_spans,
+				PenaltyInSeconds:     boarded_penalty,
+			}
+			current_round_segments_by_unique_stop_id[departure_stop_time.GetUniqueStopID()] = departure_segment
+			if _, is_origin_stop := prepared_input.FromStopsByUniqueStopId[departure_stop_time.GetUniqueStopID()]; is_origin_stop && input.PruneByBestArrival {
+				pruning_bound = max(pruning_bound, connection.DepartureTimeInSeconds-boarded_penalty-prepared_input.GetAccessDurationInSeconds(departure_stop_time.GetUniqueStopID())-input.BestArrivalSlackInSeconds)
+			}
+
+			/* walking transfers towards the departure stop - these are always earlier than the connection so they can not affect already scanned connections */
+			csaRelaxTransfersArriveBy(&prepared_input, current_round_segments_by_unique_stop_id, departure_segment, &stats_recorder.round)
+		}
+
+		/* any origin which was improved this round (and was departed from by a trip) is a new journey */
+		for _, unique_stop_id := range getSortedKeys(prepared_input.FromStopsByUniqueStopId) {
+			segment, has_segment := current_round_segments_by_unique_stop_id[unique_stop_id]
+			if !has_segment || len(segment.Spans) == 0 || segment.Spans[0].ViaTrip == nil || segment.Spans[len(segment.Spans)-1].ViaTrip == nil {
+				continue
+			}
+			if best_departure_time, has_best_departure_time := best_departure_time_by_origin_stop_id[unique_stop_id]; has_best_departure_time && best_departure_time >= segment.ArrivalTimeInSeconds-segment.PenaltyInSeconds {
+				continue
+			}
+			best_departure_time_by_origin_stop_id[unique_stop_id] = segment.ArrivalTimeInSeconds - segment.PenaltyInSeconds
+			if journey := segment.ToJourney(); potential_journey_spans.Add(journey.Legs) {
+				potential_journeys_found = append(potential_journeys_found, journey)
+			}
+		}
+
+		stats_recorder.endRound()
+		previous_round_segments_by_unique_stop_id = current_round_segments_by_unique_stop_id
+		if !had_improvements_this_round {
+			break
+		}
+	}
+
+	journeys := prepared_input.finalizeJourneys(potential_journeys_found, RaptorModeArriveBy)
+	stats_recorder.endQuery(len(journeys))
+	return journeys
+}
+
+/** relaxes the walking transfers from a stop which was arrived at by a trip - and transitively if transfer hopping is allowed */
+func csaRelaxTransfersDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType],
+	segments_by_unique_stop_id map[ID]RoundSegment[ID],
+	from_segment RoundSegment[ID],
+	stats *RaptorRoundStats,
+) {
+	segments_to_relax := []RoundSegment[ID]{from_segment}
+	for len(segments_to_relax) > 0 {
+		segment := segments_to_relax[0]
+		segments_to_relax = segments_to_relax[1:]
+		for _, transfer_index := range prepared_input.TransfersByUniqueStopId[segment.UniqueStopID] {
+			transfer := prepared_input.Input.Transfers[transfer_index]
+			if !prepared_input.IsTransferAllowed(transfer) {
+				continue
+			}
+			stats.TransfersRelaxed++
+			arrival_time_at_transfer_stop := segment.ArrivalTimeInSeconds + int64(transfer.GetMinimumTransferTimeInSeconds())
+			existing_segment, has_existing_segment := segments_by_unique_stop_id[transfer.GetToUniqueStopID()]
+			if has_existing_segment && existing_segment.ArrivalTimeInSeconds+existing_segment.PenaltyInSeconds <= arrival_time_at_transfer_stop+segment.PenaltyInSeconds {
+				continue
+			}
+			updated_spans := make([]RoundSegmentSpan[ID], len(segment.Spans)+1)
+			copy(updated_spans, segment.Spans)
+			updated_spans[len(updated_spans)-1] = RoundSegmentSpan[ID]{
+				FromUniqueStopID:                       segment.UniqueStopID,
+				ToUniqueStopID:                         transfer.GetToUniqueStopID(),
+				ViaTrip:                                nil,
+				DepartureTimeInSecondsFromUniqueStopID: segment.ArrivalTimeInSeconds,
+				ArrivalTimeInSecondsToUniqueStopID:     arrival_time_at_transfer_stop,
+				TransferIndex:                          transfer_index,
+			}
+			transfer_segment := RoundSegment[ID]{
+				UniqueStopID:         transfer.GetToUniqueStopID(),
+				ArrivalTimeInSeconds: arrival_time_at_transfer_stop,
+				Spans:                updated_spans,
+				PenaltyInSeconds:     segment.PenaltyInSeconds,
+			}
+			segments_by_unique_stop_id[transfer.GetToUniqueStopID()] = transfer_segment
+			stats.LabelsImproved++
+			if prepared_input.Input.AllowTransferHopping {
+				segments_to_relax = append(segments_to_relax, transfer_segment)
+			}
+		}
+	}
+}
+
+/** relaxes the walking transfers towards a stop which was departed from by a trip - and transitively if transfer hopping is allowed */
+func csaRelaxTransfersArriveBy[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType],
+	segments_by_unique_stop_id map[ID]RoundSegment[ID],
+	from_segment RoundSegment[ID],
+	stats *RaptorRoundStats,
+) {
+	segments_to_relax := []RoundSegment[ID]{from_segment}
+	for len(segments_to_relax) > 0 {
+		segment := segments_to_relax[0]
+		segments_to_relax = segments_to_relax[1:]
+		/* like the raptor arrive by implementation the transfers without a transfer in the other direction are assumed to be symmetric */
+		for _, transfer_index := range prepared_input.ArriveByTransfersByUniqueStopId[segment.UniqueStopID] {
+			transfer := prepared_input.Input.Transfers[transfer_index]
+			if !prepared_input.isArriveByTransferAllowed(transfer, segment.UniqueStopID) {
+				continue
+			}
+			stats.TransfersRelaxed++
+			transfer_from_unique_stop_id := getArriveByTransferFromStop(transfer, segment.UniqueStopID)
+			departure_time_from_transfer_stop := segment.ArrivalTimeInSeconds - int64(transfer.GetMinimumTransferTimeInSeconds())
+			existing_segment, has_existing_segment := segments_by_unique_stop_id[transfer_from_unique_stop_id]
+			if has_existing_segment && existing_segment.ArrivalTimeInSeconds-existing_segment.PenaltyInSeconds >= departure_time_from_transfer_stop-segment.PenaltyInSeconds {
+				continue
+			}
+			updated_spans := append([]RoundSegmentSpan[ID]{
+				{
+					FromUniqueStopID:                       transfer_from_unique_stop_id,
+					ToUniqueStopID:                         segment.UniqueStopID,
+					ViaTrip:                                nil,
+					DepartureTimeInSecondsFromUniqueStopID: departure_time_from_transfer_stop,
+					ArrivalTimeInSecondsToUniqueStopID:     segment.ArrivalTimeInSeconds,
+					TransferIndex:                          transfer_index,
+				},
+			}, segment.Spans...)
+			transfer_segment := RoundSegment[ID]{
+				UniqueStopID:         transfer_from_unique_stop_id,
+				ArrivalTimeInSeconds: departure_time_from_transfer_stop,
+				Spans:                updated_spans,
+				PenaltyInSeconds:     segment.PenaltyInSeconds,
+			}
+			segments_by_unique_stop_id[transfer_from_unique_stop_id] = transfer_segment
+			stats.LabelsImproved++
+			if prepared_input.Input.AllowTransferHopping {
+				segments_to_relax = append(segments_to_relax, transfer_segment)
+			}
+		}
+	}
+}
+
+func SimpleCsa[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) []Journey[ID] {
+	if input.Mode == RaptorModeDepartAt {
+		return SimpleCsaDepartAt(input)
+	}
+	return SimpleCsaArriveBy(input)
+}
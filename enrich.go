@@ -0,0 +1,164 @@
+package go_raptor
+
+type RouteMetadata struct {
+	ShortName string
+	LongName  string
+	Color     string
+	TextColor string
+	RouteType GtfsRouteType
+}
+
+type TripMetadata[ID UniqueGtfsIdLike] struct {
+	UniqueRouteID ID
+	Headsign      string
+	ShortName     string
+}
+
+type StopMetadata struct {
+	Name         string
+	Latitude     float64
+	Longitude    float64
+	PlatformCode string
+}
+
+/** provides the descriptive data of the routes, trips and stops which is not needed for the routing itself */
+type MetadataStore[ID UniqueGtfsIdLike] interface {
+	GetRouteMetadata(unique_route_id ID) (RouteMetadata, bool)
+	GetTripMetadata(unique_trip_id ID) (TripMetadata[ID], bool)
+	GetStopMetadata(unique_stop_id ID) (StopMetadata, bool)
+	/* the calls of the trip service ordered by stop sequence - using the same times as the routing input */
+	GetTripServiceStopCalls(unique_trip_service_id ID) []StopCall[ID]
+}
+
+/**
+ * below are the enriched versions of the journey - these can be serialized as JSON as-is
+ * any metadata which is not available in the store is left empty
+ */
+
+type EnrichedStop[ID UniqueGtfsIdLike] struct {
+	UniqueStopID ID
+	StopMetadata
+}
+
+type EnrichedRoute[ID UniqueGtfsIdLike] struct {
+	UniqueRouteID ID
+	RouteMetadata
+}
+
+type EnrichedStopCall[ID UniqueGtfsIdLike] struct {
+	Stop                   EnrichedStop[ID]
+	StopSequence           int
+	ArrivalTimeInSeconds   TimestampInSeconds
+	DepartureTimeInSeconds TimestampInSeconds
+}
+
+type EnrichedTrip[ID UniqueGtfsIdLike] struct {
+	UniqueTripID        ID
+	UniqueTripServiceID ID
+	Headsign            string
+	ShortName           string
+	IsEstimated         bool
+	Route               *EnrichedRoute[ID]
+	/* the calls between the boarding and alighting stop */
+	IntermediateStops []EnrichedStopCall[ID]
+}
+
+type EnrichedLeg[ID UniqueGtfsIdLike] struct {
+	FromStop               EnrichedStop[ID]
+	ToStop                 EnrichedStop[ID]
+	DepartureTimeInSeconds TimestampInSeconds
+	ArrivalTimeInSeconds   TimestampInSeconds
+	Mode                   TransitMode
+	WithBike               bool
+	/* nil for walking legs */
+	Trip *EnrichedTrip[ID]
+	Path []TransferPathStep[ID]
+	/* the geometry of the leg as an encoded polyline - empty unless it is added (see geo.AddEncodedPolylines) */
+	EncodedPolyline string
+}
+
+type EnrichedJourney[ID UniqueGtfsIdLike] struct {
+	FromStop                EnrichedStop[ID]
+	ToStop                  EnrichedStop[ID]
+	DepartureTimeInSeconds  TimestampInSeconds
+	ArrivalTimeInSeconds    TimestampInSeconds
+	AccessDurationInSeconds TimestampInSeconds
+	EgressDurationInSeconds TimestampInSeconds
+	PenaltyInSeconds        TimestampInSeconds
+	Legs                    []EnrichedLeg[ID]
+}
+
+func EnrichJourneys[ID UniqueGtfsIdLike](journeys []Journey[ID], store MetadataStore[ID]) []EnrichedJourney[ID] {
+	enriched_journeys := make([]EnrichedJourney[ID], len(journeys))
+	for index, journey := range journeys {
+		enriched_journeys[index] = EnrichJourney(journey, store)
+	}
+	return enriched_journeys
+}
+
+/** annotates the journey with the route, trip and stop metadata of the store */
+func EnrichJourney[ID UniqueGtfsIdLike](journey Journey[ID], store MetadataStore[ID]) EnrichedJourney[ID] {
+	enriched_journey := EnrichedJourney[ID]{
+		FromStop:                enrichStop(journey.FromUniqueStopID, store),
+		ToStop:                  enrichStop(journey.ToUniqueStopID, store),
+		DepartureTimeInSeconds:  journey.DepartureTimeInSeconds,
+		ArrivalTimeInSeconds:    journey.ArrivalTimeInSeconds,
+		AccessDurationInSeconds: journey.AccessDurationInSeconds,
+		EgressDurationInSeconds: journey.EgressDurationInSeconds,
+		PenaltyInSeconds:        journey.PenaltyInSeconds,
+		Legs:                    make([]EnrichedLeg[ID], len(journey.Legs)),
+	}
+	for index, leg := range journey.Legs {
+		enriched_journey.Legs[index] = EnrichedLeg[ID]{
+			FromStop:               enrichStop(leg.FromUniqueStopID, store),
+			ToStop:                 enrichStop(leg.ToUniqueStopID, store),
+			DepartureTimeInSeconds: leg.DepartureTimeInSecondsFromUniqueStopID,
+			ArrivalTimeInSeconds:   leg.ArrivalTimeInSecondsToUniqueStopID,
+			Mode:                   leg.Mode,
+			WithBike:               leg.WithBike,
+			Path:                   leg.Path,
+		}
+		if leg.ViaTrip != nil {
+			enriched_journey.Legs[index].Trip = enrichTrip(*leg.ViaTrip, store)
+		}
+	}
+	return enriched_journey
+}
+
+func enrichStop[ID UniqueGtfsIdLike](unique_stop_id ID, store MetadataStore[ID]) EnrichedStop[ID] {
+	stop_metadata, _ := store.GetStopMetadata(unique_stop_id)
+	return EnrichedStop[ID]{UniqueStopID: unique_stop_id, StopMetadata: stop_metadata}
+}
+
+func enrichTrip[ID UniqueGtfsIdLike](via_trip ViaTrip[ID], store MetadataStore[ID]) *EnrichedTrip[ID] {
+	enriched_trip := &EnrichedTrip[ID]{
+		UniqueTripID:        via_trip.UniqueTripID,
+		UniqueTripServiceID: via_trip.UniqueTripServiceID,
+		IsEstimated:         via_trip.IsEstimated,
+		IntermediateStops:   []EnrichedStopCall[ID]{},
+	}
+	if trip_metadata, has_trip_metadata := store.GetTripMetadata(via_trip.UniqueTripID); has_trip_metadata {
+		enriched_trip.Headsign = trip_metadata.Headsign
+		enriched_trip.ShortName = trip_metadata.ShortName
+		if route_metadata, has_route_metadata := store.GetRouteMetadata(trip_metadata.UniqueRouteID); has_route_metadata {
+			enriched_trip.Route = &EnrichedRoute[ID]{UniqueRouteID: trip_metadata.UniqueRouteID, RouteMetadata: route_metadata}
+		}
+	}
+	/* the intermediate stops of the leg are used when the journey was found with IncludeIntermediateStops */
+	stop_calls := via_trip.IntermediateStops
+	if stop_calls == nil {
+		stop_calls = store.GetTripServiceStopCalls(via_trip.UniqueTripServiceID)
+	}
+	for _, stop_call := range stop_calls {
+		if stop_call.StopSequence <= via_trip.FromStopSequenceInTrip || stop_call.StopSequence >= via_trip.ToStopSequenceInTrip {
+			continue
+		}
+		enriched_trip.IntermediateStops = append(enriched_trip.IntermediateStops, EnrichedStopCall[ID]{
+			Stop:                   enrichStop(stop_call.UniqueStopID, store),
+			StopSequence:           stop_call.StopSequence,
+			ArrivalTimeInSeconds:   stop_call.ArrivalTimeInSeconds,
+			DepartureTimeInSeconds: stop_call.DepartureTimeInSeconds,
+		})
+	}
+	return enriched_trip
+}
@@ -0,0 +1,130 @@
+package go_raptor
+
+import "sort"
+
+/** the frequencies.txt entries - the times have to use the same time basis as the stop times of the template trip */
+type GtfsFrequency[ID UniqueGtfsIdLike] interface {
+	/* the unique trip ID of the template trip */
+	GetUniqueTripID() ID
+	GetStartTimeInSeconds() TimestampInSeconds
+	GetEndTimeInSeconds() TimestampInSeconds
+	GetHeadwayInSeconds() int
+	/* exact_times=1 means the trips depart exactly at the headways - otherwise the departures are estimated */
+	GetExactTimes() bool
+}
+
+type GtfsFrequencyStruct[ID UniqueGtfsIdLike] struct {
+	GtfsFrequency[ID]
+	UniqueTripID       ID
+	StartTimeInSeconds TimestampInSeconds
+	EndTimeInSeconds   TimestampInSeconds
+	HeadwayInSeconds   int
+	ExactTimes         bool
+}
+
+func (b GtfsFrequencyStruct[T]) GetUniqueTripID() T {
+	return b.UniqueTripID
+}
+
+func (b GtfsFrequencyStruct[T]) GetStartTimeInSeconds() TimestampInSeconds {
+	return b.StartTimeInSeconds
+}
+
+func (b GtfsFrequencyStruct[T]) GetEndTimeInSeconds() TimestampInSeconds {
+	return b.EndTimeInSeconds
+}
+
+func (b GtfsFrequencyStruct[T]) GetHeadwayInSeconds() int {
+	return b.HeadwayInSeconds
+}
+
+func (b GtfsFrequencyStruct[T]) GetExactTimes() bool {
+	return b.ExactTimes
+}
+
+type ExpandedFrequencies[ID UniqueGtfsIdLike] struct {
+	/* all the stop times ordered by arrival time - with the template trips replaced by their instances */
+	StopTimes []GtfsStopTimeStruct[ID]
+	/* the trip service IDs of the instances of exact_times=0 frequencies - to be passed as the EstimatedUniqueTripServiceIDs of the input */
+	EstimatedUniqueTripServiceIDs []ID
+}
+
+/**
+ * expands the frequency based trips into concrete trip instances - one for every headway between the start (inclusive) and end (exclusive) time
+ * the stop times of the template trips are shifted so the first departure matches the instance start time and the other stop times are kept as-is
+ * the frequencies with exact_times=0 are expanded as well but since the actual departures can be anywhere within the headway their instances are
+ * reported as estimated. the unique trip service ID of every instance is created using the get_unique_trip_service_id function
+ * this should be done per service day - like filtering the stop times by calendar
+ */
+func ExpandFrequencies[ID UniqueGtfsIdLike, StopTimeType GtfsStopTime[ID], FrequencyType GtfsFrequency[ID]](
+	stop_times []StopTimeType,
+	frequencies []FrequencyType,
+	get_unique_trip_service_id func(template_unique_trip_service_id ID, start_time TimestampInSeconds) ID,
+) ExpandedFrequencies[ID] {
+	frequencies_by_unique_trip_id := map[ID][]FrequencyType{}
+	for _, frequency := range frequencies {
+		if frequency.GetHeadwayInSeconds() <= 0 {
+			continue
+		}
+		frequencies_by_unique_trip_id[frequency.GetUniqueTripID()] = append(frequencies_by_unique_trip_id[frequency.GetUniqueTripID()], frequency)
+	}
+
+	expanded := ExpandedFrequencies[ID]{
+		StopTimes:                     []GtfsStopTimeStruct[ID]{},
+		EstimatedUniqueTripServiceIDs: []ID{},
+	}
+	/* the template trips are grouped by their trip service ID since the same trip can be repeated across days */
+	template_stop_times_by_unique_trip_service_id := map[ID][]StopTimeType{}
+	template_unique_trip_service_ids := []ID{}
+	for _, stop_time := range stop_times {
+		if _, has_frequencies := frequencies_by_unique_trip_id[stop_time.GetUniqueTripID()]; !has_frequencies {
+			expanded.StopTimes = append(expanded.StopTimes, toGtfsStopTimeStruct[ID](stop_time))
+			continue
+		}
+		if _, has_template := template_stop_times_by_unique_trip_service_id[stop_time.GetUniqueTripServiceID()]; !has_template {
+			template_unique_trip_service_ids = append(template_unique_trip_service_ids, stop_time.GetUniqueTripServiceID())
+		}
+		template_stop_times_by_unique_trip_service_id[stop_time.GetUniqueTripServiceID()] = append(template_stop_times_by_unique_trip_service_id[stop_time.GetUniqueTripServiceID()], stop_time)
+	}
+
+	for _, template_unique_trip_service_id := range template_unique_trip_service_ids {
+		template_stop_times := template_stop_times_by_unique_trip_service_id[template_unique_trip_service_id]
+		sort.SliceStable(template_stop_times, func(i, j int) bool {
+			return template_stop_times[i].GetStopSequence() < template_stop_times[j].GetStopSequence()
+		})
+		first_departure_time := template_stop_times[0].GetDepartureTimeInSeconds()
+		for _, frequency := range frequencies_by_unique_trip_id[template_stop_times[0].GetUniqueTripID()] {
+			for start_time := frequency.GetStartTimeInSeconds(); start_time < frequency.GetEndTimeInSeconds(); start_time += TimestampInSeconds(frequency.GetHeadwayInSeconds()) {
+				unique_trip_service_id := get_unique_trip_service_id(template_unique_trip_service_id, start_time)
+				offset := start_time - first_departure_time
+				for _, template_stop_time := range template_stop_times {
+					stop_time := toGtfsStopTimeStruct[ID](template_stop_time)
+					stop_time.UniqueTripServiceID = unique_trip_service_id
+					stop_time.ArrivalTimeInSeconds += offset
+					stop_time.DepartureTimeInSeconds += offset
+					expanded.StopTimes = append(expanded.StopTimes, stop_time)
+				}
+				if !frequency.GetExactTimes() {
+					expanded.EstimatedUniqueTripServiceIDs = append(expanded.EstimatedUniqueTripServiceIDs, unique_trip_service_id)
+				}
+			}
+		}
+	}
+
+	/* the stop times have to be ordered by time for the partitions */
+	sort.SliceStable(expanded.StopTimes, func(i, j int) bool {
+		return expanded.StopTimes[i].ArrivalTimeInSeconds < expanded.StopTimes[j].ArrivalTimeInSeconds
+	})
+	return expanded
+}
+
+func toGtfsStopTimeStruct[ID UniqueGtfsIdLike, StopTimeType GtfsStopTime[ID]](stop_time StopTimeType) GtfsStopTimeStruct[ID] {
+	return GtfsStopTimeStruct[ID]{
+		UniqueStopID:           stop_time.GetUniqueStopID(),
+		UniqueTripID:           stop_time.GetUniqueTripID(),
+		UniqueTripServiceID:    stop_time.GetUniqueTripServiceID(),
+		StopSequence:           stop_time.GetStopSequence(),
+		ArrivalTimeInSeconds:   stop_time.GetArrivalTimeInSeconds(),
+		DepartureTimeInSeconds: stop_time.GetDepartureTimeInSeconds(),
+	}
+}
@@ -0,0 +1,58 @@
+package go_raptor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandFrequencies(t *testing.T) {
+	stop_times := []GtfsStopTimeStruct[string]{
+		{UniqueStopID: "Times Sq", UniqueTripID: "S", UniqueTripServiceID: "S", StopSequence: 1, ArrivalTimeInSeconds: 0, DepartureTimeInSeconds: 0},
+		{UniqueStopID: "Grand Central", UniqueTripID: "S", UniqueTripServiceID: "S", StopSequence: 2, ArrivalTimeInSeconds: 90, DepartureTimeInSeconds: 90},
+		{UniqueStopID: "Times Sq", UniqueTripID: "7", UniqueTripServiceID: "7", StopSequence: 1, ArrivalTimeInSeconds: 500, DepartureTimeInSeconds: 510},
+		{UniqueStopID: "Grand Central", UniqueTripID: "7", UniqueTripServiceID: "7", StopSequence: 2, ArrivalTimeInSeconds: 600, DepartureTimeInSeconds: 610},
+	}
+	frequencies := []GtfsFrequencyStruct[string]{
+		{UniqueTripID: "S", StartTimeInSeconds: 300, EndTimeInSeconds: 900, HeadwayInSeconds: 300, ExactTimes: true},
+		{UniqueTripID: "S", StartTimeInSeconds: 900, EndTimeInSeconds: 1200, HeadwayInSeconds: 600, ExactTimes: false},
+	}
+	expanded := ExpandFrequencies[string](stop_times, frequencies, func(template_unique_trip_service_id string, start_time TimestampInSeconds) string {
+		return fmt.Sprintf("%s_%d", template_unique_trip_service_id, start_time)
+	})
+
+	/* 2 exact instances (300 and 600) + 1 estimated instance (900) of the S and the untouched 7 */
+	assert.Len(t, expanded.StopTimes, 8)
+	assert.Equal(t, []string{"S_900"}, expanded.EstimatedUniqueTripServiceIDs)
+	for index := 1; index < len(expanded.StopTimes); index++ {
+		assert.LessOrEqual(t, expanded.StopTimes[index-1].ArrivalTimeInSeconds, expanded.StopTimes[index].ArrivalTimeInSeconds, "should be ordered by arrival time")
+	}
+	assert.Equal(t, GtfsStopTimeStruct[string]{UniqueStopID: "Grand Central", UniqueTripID: "S", UniqueTripServiceID: "S_600", StopSequence: 2, ArrivalTimeInSeconds: 690, DepartureTimeInSeconds: 690}, expanded.StopTimes[5])
+
+	input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops:                     []GtfsStopStruct[string]{{UniqueID: "Times Sq"}},
+		ToStops:                       []GtfsStopStruct[string]{{UniqueID: "Grand Central"}},
+		Transfers:                     []GtfsTransferStruct[string]{},
+		StopTimes:                     expanded.StopTimes,
+		EstimatedUniqueTripServiceIDs: expanded.EstimatedUniqueTripServiceIDs,
+		Mode:                          RaptorModeDepartAt,
+		MaximumTransfers:              4,
+	}
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			input.Engine = engine
+			input.TimeInSeconds = 550
+			journeys := SimpleRaptor(input)
+			assert.Len(t, journeys, 1)
+			assert.Equal(t, "S_600", journeys[0].Legs[0].ViaTrip.UniqueTripServiceID)
+			assert.False(t, journeys[0].Legs[0].ViaTrip.IsEstimated)
+
+			input.TimeInSeconds = 700
+			journeys = SimpleRaptor(input)
+			assert.Len(t, journeys, 1)
+			assert.Equal(t, "S_900", journeys[0].Legs[0].ViaTrip.UniqueTripServiceID)
+			assert.True(t, journeys[0].Legs[0].ViaTrip.IsEstimated, "should report the departure without exact times as estimated")
+		})
+	}
+}
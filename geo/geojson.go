@@ -0,0 +1,64 @@
+package geo
+
+import (
+	raptor "github.com/liammartens/go-raptor"
+)
+
+/** a GeoJSON LineString - the positions are [longitude, latitude] as required by the spec */
+type LineString struct {
+	Type        string       `json:"type"`
+	Coordinates [][2]float64 `json:"coordinates"`
+}
+
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   LineString     `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func NewLineString(coordinates []Coordinate) LineString {
+	positions := make([][2]float64, len(coordinates))
+	for index, coordinate := range coordinates {
+		positions[index] = [2]float64{coordinate.Longitude, coordinate.Latitude}
+	}
+	return LineString{Type: "LineString", Coordinates: positions}
+}
+
+func JourneysToFeatureCollections[ID raptor.UniqueGtfsIdLike](journeys []raptor.Journey[ID], store GeometryStore[ID]) []FeatureCollection {
+	feature_collections := make([]FeatureCollection, len(journeys))
+	for index, journey := range journeys {
+		feature_collections[index] = JourneyToFeatureCollection(journey, store)
+	}
+	return feature_collections
+}
+
+/** converts the journey to a feature collection with a LineString feature for every leg */
+func JourneyToFeatureCollection[ID raptor.UniqueGtfsIdLike](journey raptor.Journey[ID], store GeometryStore[ID]) FeatureCollection {
+	feature_collection := FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, len(journey.Legs))}
+	for index, leg := range journey.Legs {
+		properties := map[string]any{
+			"leg_index":      index,
+			"mode":           leg.Mode,
+			"from_stop_id":   leg.FromUniqueStopID,
+			"to_stop_id":     leg.ToUniqueStopID,
+			"departure_time": leg.DepartureTimeInSecondsFromUniqueStopID,
+			"arrival_time":   leg.ArrivalTimeInSecondsToUniqueStopID,
+		}
+		if leg.ViaTrip != nil {
+			properties["trip_id"] = leg.ViaTrip.UniqueTripID
+			properties["trip_service_id"] = leg.ViaTrip.UniqueTripServiceID
+			properties["is_estimated"] = leg.ViaTrip.IsEstimated
+		}
+		feature_collection.Features[index] = Feature{
+			Type:       "Feature",
+			Geometry:   NewLineString(GetLegCoordinates(leg, store)),
+			Properties: properties,
+		}
+	}
+	return feature_collection
+}
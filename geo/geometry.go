@@ -0,0 +1,149 @@
+package geo
+
+import (
+	"math"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+type Coordinate struct {
+	Latitude  float64
+	Longitude float64
+}
+
+/** a point of a trip shape - the distance traveled is optional and uses the same unit as the stop distances */
+type ShapePoint struct {
+	Coordinate
+	DistanceTraveled    float64
+	HasDistanceTraveled bool
+}
+
+/** provides the coordinates and shapes which are used to draw the legs of a journey */
+type GeometryStore[ID raptor.UniqueGtfsIdLike] interface {
+	GetStopCoordinate(unique_stop_id ID) (Coordinate, bool)
+	/* the shape points of the trip ordered by sequence - empty when the trip has no shape */
+	GetTripShape(unique_trip_id ID) []ShapePoint
+	/* the shape_dist_traveled of the trip service at the stop - false when it is unknown */
+	GetShapeDistanceTraveled(unique_trip_service_id ID, stop_sequence int) (float64, bool)
+}
+
+/**
+ * gets the line of the leg - walking legs are a straight line between the stops while transit legs follow the shape of the trip
+ * between the boarding and alighting stop. transit legs without a shape connect the (intermediate) stops with straight lines
+ */
+func GetLegCoordinates[ID raptor.UniqueGtfsIdLike](leg raptor.RoundSegmentSpan[ID], store GeometryStore[ID]) []Coordinate {
+	from_coordinate, has_from_coordinate := store.GetStopCoordinate(leg.FromUniqueStopID)
+	to_coordinate, has_to_coordinate := store.GetStopCoordinate(leg.ToUniqueStopID)
+	if !has_from_coordinate || !has_to_coordinate {
+		return []Coordinate{}
+	}
+	if leg.ViaTrip == nil {
+		return []Coordinate{from_coordinate, to_coordinate}
+	}
+
+	shape := store.GetTripShape(leg.ViaTrip.UniqueTripID)
+	if len(shape) < 2 {
+		coordinates := []Coordinate{from_coordinate}
+		for _, stop_call := range leg.ViaTrip.IntermediateStops {
+			if coordinate, has_coordinate := store.GetStopCoordinate(stop_call.UniqueStopID); has_coordinate {
+				coordinates = append(coordinates, coordinate)
+			}
+		}
+		return append(coordinates, to_coordinate)
+	}
+
+	from_distance, has_from_distance := store.GetShapeDistanceTraveled(leg.ViaTrip.UniqueTripServiceID, leg.ViaTrip.FromStopSequenceInTrip)
+	to_distance, has_to_distance := store.GetShapeDistanceTraveled(leg.ViaTrip.UniqueTripServiceID, leg.ViaTrip.ToStopSequenceInTrip)
+	if has_from_distance && has_to_distance && from_distance < to_distance && hasShapeDistances(shape) {
+		return SliceShapeByDistance(shape, from_distance, to_distance)
+	}
+	return SliceShapeByProjection(shape, from_coordinate, to_coordinate)
+}
+
+func hasShapeDistances(shape []ShapePoint) bool {
+	for _, shape_point := range shape {
+		if !shape_point.HasDistanceTraveled {
+			return false
+		}
+	}
+	return true
+}
+
+/** clips the shape between the distances traveled - the start and end are interpolated between the surrounding shape points */
+func SliceShapeByDistance(shape []ShapePoint, from_distance float64, to_distance float64) []Coordinate {
+	coordinates := []Coordinate{getCoordinateAtDistance(shape, from_distance)}
+	for _, shape_point := range shape {
+		if shape_point.DistanceTraveled > from_distance && shape_point.DistanceTraveled < to_distance {
+			coordinates = append(coordinates, shape_point.Coordinate)
+		}
+	}
+	return append(coordinates, getCoordinateAtDistance(shape, to_distance))
+}
+
+func getCoordinateAtDistance(shape []ShapePoint, distance float64) Coordinate {
+	if distance <= shape[0].DistanceTraveled {
+		return shape[0].Coordinate
+	}
+	for index := 1; index < len(shape); index++ {
+		if shape[index].DistanceTraveled < distance {
+			continue
+		}
+		segment_distance := shape[index].DistanceTraveled - shape[index-1].DistanceTraveled
+		if segment_distance <= 0 {
+			return shape[index].Coordinate
+		}
+		return interpolateCoordinate(shape[index-1].Coordinate, shape[index].Coordinate, (distance-shape[index-1].DistanceTraveled)/segment_distance)
+	}
+	return shape[len(shape)-1].Coordinate
+}
+
+/**
+ * clips the shape between the points nearest to the stops - the to stop is only matched after the from stop
+ * so shapes which pass the same place twice (ie loops) are clipped correctly
+ */
+func SliceShapeByProjection(shape []ShapePoint, from_coordinate Coordinate, to_coordinate Coordinate) []Coordinate {
+	from_segment_index, from_fraction := projectOntoShape(shape, from_coordinate, 0, 0)
+	to_segment_index, to_fraction := projectOntoShape(shape, to_coordinate, from_segment_index, from_fraction)
+	coordinates := []Coordinate{interpolateCoordinate(shape[from_segment_index].Coordinate, shape[from_segment_index+1].Coordinate, from_fraction)}
+	for index := from_segment_index + 1; index <= to_segment_index; index++ {
+		coordinates = append(coordinates, shape[index].Coordinate)
+	}
+	return append(coordinates, interpolateCoordinate(shape[to_segment_index].Coordinate, shape[to_segment_index+1].Coordinate, to_fraction))
+}
+
+/** finds the nearest point on the shape from the start segment and fraction onwards - returning its segment index and the fraction along that segment */
+func projectOntoShape(shape []ShapePoint, coordinate Coordinate, start_segment_index int, start_fraction float64) (int, float64) {
+	nearest_segment_index := start_segment_index
+	nearest_fraction := start_fraction
+	nearest_distance := math.Inf(1)
+	/* the coordinates are projected onto a flat plane around the coordinate - which is accurate enough for comparing nearby distances */
+	longitude_scale := math.Cos(coordinate.Latitude * math.Pi / 180)
+	for segment_index := start_segment_index; segment_index < len(shape)-1; segment_index++ {
+		from_x := (shape[segment_index].Longitude - coordinate.Longitude) * longitude_scale
+		from_y := shape[segment_index].Latitude - coordinate.Latitude
+		delta_x := (shape[segment_index+1].Longitude-coordinate.Longitude)*longitude_scale - from_x
+		delta_y := shape[segment_index+1].Latitude - coordinate.Latitude - from_y
+		fraction := 0.0
+		if segment_length := delta_x*delta_x + delta_y*delta_y; segment_length > 0 {
+			fraction = math.Max(0, math.Min(1, -(from_x*delta_x+from_y*delta_y)/segment_length))
+		}
+		if segment_index == start_segment_index {
+			fraction = math.Max(fraction, start_fraction)
+		}
+		x := from_x + delta_x*fraction
+		y := from_y + delta_y*fraction
+		if distance := x*x + y*y; distance < nearest_distance {
+			nearest_distance = distance
+			nearest_segment_index = segment_index
+			nearest_fraction = fraction
+		}
+	}
+	return nearest_segment_index, nearest_fraction
+}
+
+func interpolateCoordinate(from Coordinate, to Coordinate, fraction float64) Coordinate {
+	return Coordinate{
+		Latitude:  from.Latitude + (to.Latitude-from.Latitude)*fraction,
+		Longitude: from.Longitude + (to.Longitude-from.Longitude)*fraction,
+	}
+}
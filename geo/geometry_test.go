@@ -0,0 +1,114 @@
+package geo
+
+import (
+	"encoding/json"
+	"testing"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/stretchr/testify/assert"
+)
+
+type testGeometryStore struct {
+	coordinates_by_stop_id map[string]Coordinate
+	shapes_by_trip_id      map[string][]ShapePoint
+	distances_by_sequence  map[int]float64
+}
+
+func (store testGeometryStore) GetStopCoordinate(stop_id string) (Coordinate, bool) {
+	coordinate, has_coordinate := store.coordinates_by_stop_id[stop_id]
+	return coordinate, has_coordinate
+}
+
+func (store testGeometryStore) GetTripShape(trip_id string) []ShapePoint {
+	return store.shapes_by_trip_id[trip_id]
+}
+
+func (store testGeometryStore) GetShapeDistanceTraveled(trip_service_id string, stop_sequence int) (float64, bool) {
+	distance, has_distance := store.distances_by_sequence[stop_sequence]
+	return distance, has_distance
+}
+
+func TestSliceShapeByDistance(t *testing.T) {
+	shape := []ShapePoint{
+		{Coordinate: Coordinate{Latitude: 0, Longitude: 0}, DistanceTraveled: 0, HasDistanceTraveled: true},
+		{Coordinate: Coordinate{Latitude: 0, Longitude: 1}, DistanceTraveled: 10, HasDistanceTraveled: true},
+		{Coordinate: Coordinate{Latitude: 1, Longitude: 1}, DistanceTraveled: 20, HasDistanceTraveled: true},
+		{Coordinate: Coordinate{Latitude: 1, Longitude: 2}, DistanceTraveled: 30, HasDistanceTraveled: true},
+	}
+	assert.Equal(t, []Coordinate{
+		{Latitude: 0, Longitude: 0.5},
+		{Latitude: 0, Longitude: 1},
+		{Latitude: 1, Longitude: 1},
+		{Latitude: 1, Longitude: 1.5},
+	}, SliceShapeByDistance(shape, 5, 25))
+}
+
+func TestSliceShapeByProjection(t *testing.T) {
+	/* a loop which passes the start again at the end */
+	shape := []ShapePoint{
+		{Coordinate: Coordinate{Latitude: 0, Longitude: 0}},
+		{Coordinate: Coordinate{Latitude: 0, Longitude: 0.02}},
+		{Coordinate: Coordinate{Latitude: 0.01, Longitude: 0.02}},
+		{Coordinate: Coordinate{Latitude: 0.01, Longitude: 0}},
+		{Coordinate: Coordinate{Latitude: 0.0001, Longitude: 0}},
+	}
+	coordinates := SliceShapeByProjection(shape, Coordinate{Latitude: 0.0001, Longitude: 0.01}, Coordinate{Latitude: 0.0001, Longitude: 0.0001})
+	assert.Len(t, coordinates, 5)
+	assert.InDelta(t, 0.01, coordinates[0].Longitude, 1e-9, "should start at the projection of the from stop")
+	assert.InDelta(t, 0, coordinates[0].Latitude, 1e-9)
+	assert.Equal(t, Coordinate{Latitude: 0, Longitude: 0.02}, coordinates[1])
+	assert.Equal(t, Coordinate{Latitude: 0.01, Longitude: 0}, coordinates[3])
+	assert.InDelta(t, 0.0001, coordinates[4].Latitude, 1e-9, "should match the to stop on the end of the loop rather than the start")
+}
+
+func TestJourneyToFeatureCollection(t *testing.T) {
+	store := testGeometryStore{
+		coordinates_by_stop_id: map[string]Coordinate{
+			"A": {Latitude: 0, Longitude: 0},
+			"B": {Latitude: 0, Longitude: 2},
+			"C": {Latitude: 0.001, Longitude: 2},
+		},
+		shapes_by_trip_id: map[string][]ShapePoint{
+			"T1": {
+				{Coordinate: Coordinate{Latitude: 0, Longitude: 0}},
+				{Coordinate: Coordinate{Latitude: 0, Longitude: 1}},
+				{Coordinate: Coordinate{Latitude: 0, Longitude: 2}},
+			},
+		},
+		distances_by_sequence: map[int]float64{},
+	}
+	journey := raptor.Journey[string]{
+		FromUniqueStopID: "A",
+		ToUniqueStopID:   "C",
+		Legs: []raptor.RoundSegmentSpan[string]{
+			{
+				FromUniqueStopID:                       "A",
+				ToUniqueStopID:                         "B",
+				DepartureTimeInSecondsFromUniqueStopID: 100,
+				ArrivalTimeInSecondsToUniqueStopID:     200,
+				Mode:                                   raptor.TransitModeRail,
+				ViaTrip:                                &raptor.ViaTrip[string]{UniqueTripID: "T1", UniqueTripServiceID: "T1_1", FromStopSequenceInTrip: 1, ToStopSequenceInTrip: 2},
+			},
+			{
+				FromUniqueStopID:                       "B",
+				ToUniqueStopID:                         "C",
+				DepartureTimeInSecondsFromUniqueStopID: 200,
+				ArrivalTimeInSecondsToUniqueStopID:     260,
+				Mode:                                   raptor.TransitModeWalk,
+			},
+		},
+	}
+	feature_collection := JourneyToFeatureCollection(journey, store)
+	assert.Len(t, feature_collection.Features, 2)
+	assert.Equal(t, [][2]float64{{0, 0}, {1, 0}, {2, 0}}, feature_collection.Features[0].Geometry.Coordinates)
+	assert.Equal(t, "T1", feature_collection.Features[0].Properties["trip_id"])
+	assert.Equal(t, [][2]float64{{2, 0}, {2, 0.001}}, feature_collection.Features[1].Geometry.Coordinates, "should draw walking legs as a straight line")
+	assert.NotContains(t, feature_collection.Features[1].Properties, "trip_id")
+
+	encoded, err := json.Marshal(feature_collection)
+	assert.NoError(t, err)
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, "FeatureCollection", decoded["type"])
+	assert.Equal(t, "LineString", decoded["features"].([]any)[0].(map[string]any)["geometry"].(map[string]any)["type"])
+}
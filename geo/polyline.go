@@ -0,0 +1,96 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+const (
+	/* the precision used by the Google encoded polyline format */
+	PolylinePrecision5 = 5
+	/* the precision used by OSRM and Valhalla */
+	PolylinePrecision6 = 6
+)
+
+/** encodes the coordinates using the Google encoded polyline algorithm with the number of decimals of the precision */
+func EncodePolyline(coordinates []Coordinate, precision int) string {
+	factor := math.Pow10(precision)
+	var builder strings.Builder
+	previous_latitude, previous_longitude := int64(0), int64(0)
+	for _, coordinate := range coordinates {
+		latitude := int64(math.Round(coordinate.Latitude * factor))
+		longitude := int64(math.Round(coordinate.Longitude * factor))
+		encodePolylineValue(&builder, latitude-previous_latitude)
+		encodePolylineValue(&builder, longitude-previous_longitude)
+		previous_latitude, previous_longitude = latitude, longitude
+	}
+	return builder.String()
+}
+
+func encodePolylineValue(builder *strings.Builder, value int64) {
+	shifted_value := value << 1
+	if value < 0 {
+		shifted_value = ^shifted_value
+	}
+	for shifted_value >= 0x20 {
+		builder.WriteByte(byte((0x20 | (shifted_value & 0x1f)) + 63))
+		shifted_value >>= 5
+	}
+	builder.WriteByte(byte(shifted_value + 63))
+}
+
+/** decodes a Google encoded polyline - the precision has to match the one it was encoded with */
+func DecodePolyline(encoded string, precision int) ([]Coordinate, error) {
+	factor := math.Pow10(precision)
+	coordinates := []Coordinate{}
+	latitude, longitude := int64(0), int64(0)
+	for index := 0; index < len(encoded); {
+		latitude_delta, next_index, err := decodePolylineValue(encoded, index)
+		if err != nil {
+			return nil, err
+		}
+		longitude_delta, next_index, err := decodePolylineValue(encoded, next_index)
+		if err != nil {
+			return nil, err
+		}
+		index = next_index
+		latitude += latitude_delta
+		longitude += longitude_delta
+		coordinates = append(coordinates, Coordinate{Latitude: float64(latitude) / factor, Longitude: float64(longitude) / factor})
+	}
+	return coordinates, nil
+}
+
+func decodePolylineValue(encoded string, index int) (int64, int, error) {
+	result := int64(0)
+	shift := uint(0)
+	for {
+		if index >= len(encoded) {
+			return 0, index, fmt.Errorf("unexpected end of polyline at %d", index)
+		}
+		chunk := int64(encoded[index]) - 63
+		if chunk < 0 || chunk > 0x3f || shift > 60 {
+			return 0, index, fmt.Errorf("invalid polyline character %q at %d", encoded[index], index)
+		}
+		index++
+		result |= (chunk & 0x1f) << shift
+		shift += 5
+		if chunk < 0x20 {
+			break
+		}
+	}
+	if result&1 == 1 {
+		return ^(result >> 1), index, nil
+	}
+	return result >> 1, index, nil
+}
+
+/** sets the encoded polyline of every leg of the enriched journey - which has to be the enrichment of the passed journey */
+func AddEncodedPolylines[ID raptor.UniqueGtfsIdLike](enriched_journey *raptor.EnrichedJourney[ID], journey raptor.Journey[ID], store GeometryStore[ID], precision int) {
+	for index, leg := range journey.Legs {
+		enriched_journey.Legs[index].EncodedPolyline = EncodePolyline(GetLegCoordinates(leg, store), precision)
+	}
+}
@@ -0,0 +1,78 @@
+package geo
+
+import (
+	"testing"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodePolyline(t *testing.T) {
+	coordinates := []Coordinate{
+		{Latitude: 38.5, Longitude: -120.2},
+		{Latitude: 40.7, Longitude: -120.95},
+		{Latitude: 43.252, Longitude: -126.453},
+	}
+	/* the example of the encoded polyline algorithm format documentation */
+	assert.Equal(t, "_p~iF~ps|U_ulLnnqC_mqNvxq`@", EncodePolyline(coordinates, PolylinePrecision5))
+	decoded, err := DecodePolyline("_p~iF~ps|U_ulLnnqC_mqNvxq`@", PolylinePrecision5)
+	assert.NoError(t, err)
+	for index := range coordinates {
+		assert.InDelta(t, coordinates[index].Latitude, decoded[index].Latitude, 1e-9)
+		assert.InDelta(t, coordinates[index].Longitude, decoded[index].Longitude, 1e-9)
+	}
+
+	precise_coordinates := []Coordinate{
+		{Latitude: 40.750568, Longitude: -73.993519},
+		{Latitude: 40.699379, Longitude: -73.807998},
+	}
+	decoded, err = DecodePolyline(EncodePolyline(precise_coordinates, PolylinePrecision6), PolylinePrecision6)
+	assert.NoError(t, err)
+	for index := range precise_coordinates {
+		assert.InDelta(t, precise_coordinates[index].Latitude, decoded[index].Latitude, 1e-9)
+		assert.InDelta(t, precise_coordinates[index].Longitude, decoded[index].Longitude, 1e-9)
+	}
+
+	empty, err := DecodePolyline("", PolylinePrecision5)
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+	_, err = DecodePolyline("_p~iF~ps|U_", PolylinePrecision5)
+	assert.Error(t, err, "should fail on a truncated polyline")
+	_, err = DecodePolyline("_p~iF ps|U", PolylinePrecision5)
+	assert.Error(t, err, "should fail on invalid characters")
+}
+
+func TestAddEncodedPolylines(t *testing.T) {
+	store := testGeometryStore{
+		coordinates_by_stop_id: map[string]Coordinate{
+			"A": {Latitude: 38.5, Longitude: -120.2},
+			"B": {Latitude: 40.7, Longitude: -120.95},
+		},
+	}
+	journey := raptor.Journey[string]{
+		FromUniqueStopID: "A",
+		ToUniqueStopID:   "B",
+		Legs:             []raptor.RoundSegmentSpan[string]{{FromUniqueStopID: "A", ToUniqueStopID: "B"}},
+	}
+	enriched_journey := raptor.EnrichJourney[string](journey, emptyMetadataStore{})
+	AddEncodedPolylines(&enriched_journey, journey, store, PolylinePrecision5)
+	assert.Equal(t, "_p~iF~ps|U_ulLnnqC", enriched_journey.Legs[0].EncodedPolyline)
+}
+
+type emptyMetadataStore struct{}
+
+func (emptyMetadataStore) GetRouteMetadata(route_id string) (raptor.RouteMetadata, bool) {
+	return raptor.RouteMetadata{}, false
+}
+
+func (emptyMetadataStore) GetTripMetadata(trip_id string) (raptor.TripMetadata[string], bool) {
+	return raptor.TripMetadata[string]{}, false
+}
+
+func (emptyMetadataStore) GetStopMetadata(stop_id string) (raptor.StopMetadata, bool) {
+	return raptor.StopMetadata{}, false
+}
+
+func (emptyMetadataStore) GetTripServiceStopCalls(trip_service_id string) []raptor.StopCall[string] {
+	return nil
+}
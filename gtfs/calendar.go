@@ -0,0 +1,62 @@
+package gtfs
+
+import (
+	"io"
+)
+
+/** a calendar.txt entry - the dates are formatted as YYYYMMDD */
+type Calendar struct {
+	ServiceID string
+	/* the days of the week the service runs starting at sunday - matching time.Weekday */
+	Weekdays  [7]bool
+	StartDate string
+	EndDate   string
+}
+
+type ExceptionType = int
+
+const (
+	ExceptionTypeAdded   ExceptionType = 1
+	ExceptionTypeRemoved ExceptionType = 2
+)
+
+/** a calendar_dates.txt entry */
+type CalendarDate struct {
+	ServiceID     string
+	Date          string
+	ExceptionType ExceptionType
+}
+
+func ReadCalendars(reader io.Reader) ([]Calendar, error) {
+	calendars := []Calendar{}
+	err := readCsv(reader, func(row csvRow) error {
+		calendar := Calendar{
+			ServiceID: row.Get("service_id"),
+			StartDate: row.Get("start_date"),
+			EndDate:   row.Get("end_date"),
+		}
+		for weekday, column := range []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"} {
+			calendar.Weekdays[weekday] = row.Get(column) == "1"
+		}
+		calendars = append(calendars, calendar)
+		return nil
+	})
+	return calendars, err
+}
+
+func ReadCalendarDates(reader io.Reader) ([]CalendarDate, error) {
+	calendar_dates := []CalendarDate{}
+	err := readCsv(reader, func(row csvRow) error {
+		calendar_date := CalendarDate{
+			ServiceID: row.Get("service_id"),
+			Date:      row.Get("date"),
+		}
+		var err error
+		if calendar_date.ExceptionType, err = row.GetInt("exception_type", ExceptionTypeAdded); err != nil {
+			return err
+		}
+		calendar_dates = append(calendar_dates, calendar_date)
+		return nil
+	})
+	return calendar_dates, err
+}
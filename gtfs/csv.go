@@ -0,0 +1,84 @@
+package gtfs
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/** a single row of a GTFS csv file - the values are looked up by their column name */
+type csvRow struct {
+	column_indexes map[string]int
+	record         []string
+}
+
+/** reads a GTFS csv file calling on_row for every row after the header */
+func readCsv(reader io.Reader, on_row func(row csvRow) error) error {
+	csv_reader := csv.NewReader(reader)
+	csv_reader.FieldsPerRecord = -1
+	csv_reader.LazyQuotes = true
+	csv_reader.ReuseRecord = true
+
+	header, err := csv_reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the csv header: %w", err)
+	}
+	column_indexes := make(map[string]int, len(header))
+	for index, column := range header {
+		/* some feeds start with a byte order mark or pad the column names */
+		column_indexes[strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))] = index
+	}
+
+	for {
+		record, err := csv_reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the csv row: %w", err)
+		}
+		if err := on_row(csvRow{column_indexes: column_indexes, record: record}); err != nil {
+			line, _ := csv_reader.FieldPos(0)
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+func (row csvRow) Get(column string) string {
+	index, has_column := row.column_indexes[column]
+	if !has_column || index >= len(row.record) {
+		return ""
+	}
+	return strings.TrimSpace(row.record[index])
+}
+
+/** gets an integer value - returning the default value when the column is missing or empty */
+func (row csvRow) GetInt(column string, default_value int) (int, error) {
+	value := row.Get(column)
+	if value == "" {
+		return default_value, nil
+	}
+	parsed_value, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", column, value, err)
+	}
+	return parsed_value, nil
+}
+
+/** gets a float value - returning the default value when the column is missing or empty */
+func (row csvRow) GetFloat(column string, default_value float64) (float64, error) {
+	value := row.Get(column)
+	if value == "" {
+		return default_value, nil
+	}
+	parsed_value, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", column, value, err)
+	}
+	return parsed_value, nil
+}
@@ -0,0 +1,134 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+/** a parsed GTFS feed - the optional files which are missing are left empty */
+type Feed struct {
+	Agencies      []Agency
+	Stops         []Stop
+	Routes        []Route
+	Trips         []Trip
+	StopTimes     []StopTime
+	Calendars     []Calendar
+	CalendarDates []CalendarDate
+	Transfers     []Transfer
+	Frequencies   []Frequency
+	Pathways      []Pathway
+	Levels        []Level
+	Shapes        []ShapePoint
+}
+
+/** loads a feed from a directory (os.DirFS) or any other file system - like an opened zip file */
+func LoadFeed(fsys fs.FS) (*Feed, error) {
+	feed := &Feed{}
+	var err error
+	if feed.Agencies, err = readFeedFile(fsys, "agency.txt", true, ReadAgencies); err != nil {
+		return nil, err
+	}
+	if feed.Stops, err = readFeedFile(fsys, "stops.txt", true, ReadStops); err != nil {
+		return nil, err
+	}
+	if feed.Routes, err = readFeedFile(fsys, "routes.txt", true, ReadRoutes); err != nil {
+		return nil, err
+	}
+	if feed.Trips, err = readFeedFile(fsys, "trips.txt", true, ReadTrips); err != nil {
+		return nil, err
+	}
+	if feed.StopTimes, err = readFeedFile(fsys, "stop_times.txt", true, ReadStopTimes); err != nil {
+		return nil, err
+	}
+	if feed.Calendars, err = readFeedFile(fsys, "calendar.txt", false, ReadCalendars); err != nil {
+		return nil, err
+	}
+	if feed.CalendarDates, err = readFeedFile(fsys, "calendar_dates.txt", false, ReadCalendarDates); err != nil {
+		return nil, err
+	}
+	if feed.Transfers, err = readFeedFile(fsys, "transfers.txt", false, ReadTransfers); err != nil {
+		return nil, err
+	}
+	if feed.Frequencies, err = readFeedFile(fsys, "frequencies.txt", false, ReadFrequencies); err != nil {
+		return nil, err
+	}
+	if feed.Pathways, err = readFeedFile(fsys, "pathways.txt", false, ReadPathways); err != nil {
+		return nil, err
+	}
+	if feed.Levels, err = readFeedFile(fsys, "levels.txt", false, ReadLevels); err != nil {
+		return nil, err
+	}
+	if feed.Shapes, err = readFeedFile(fsys, "shapes.txt", false, ReadShapes); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+/** loads a feed from a zip file */
+func LoadFeedZip(path string) (*Feed, error) {
+	zip_reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer zip_reader.Close()
+	return LoadFeed(zip_reader)
+}
+
+/** stores the parsed feed as a snapshot - which loads a lot faster than parsing the csv files again */
+func (feed *Feed) Encode(writer io.Writer) error {
+	return gob.NewEncoder(writer).Encode(feed)
+}
+
+func DecodeFeed(reader io.Reader) (*Feed, error) {
+	feed := &Feed{}
+	if err := gob.NewDecoder(reader).Decode(feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+/** loads a feed from a directory, a zip file or a snapshot (see Feed.Encode) */
+func LoadFeedPath(path string) (*Feed, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return LoadFeed(os.DirFS(path))
+	}
+	if zip_reader, err := zip.OpenReader(path); err == nil {
+		defer zip_reader.Close()
+		return LoadFeed(zip_reader)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	feed, err := DecodeFeed(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a GTFS zip nor a snapshot: %w", path, err)
+	}
+	return feed, nil
+}
+
+func readFeedFile[T any](fsys fs.FS, name string, is_required bool, read func(reader io.Reader) ([]T, error)) ([]T, error) {
+	file, err := fsys.Open(name)
+	if errors.Is(err, fs.ErrNotExist) && !is_required {
+		return []T{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer file.Close()
+	entries, err := read(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return entries, nil
+}
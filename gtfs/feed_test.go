@@ -0,0 +1,66 @@
+package gtfs
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFeedZip(t *testing.T) {
+	feed, err := LoadFeedZip("../gtfslirr.zip")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, feed.Stops)
+	assert.NotEmpty(t, feed.Routes)
+	assert.NotEmpty(t, feed.Trips)
+	assert.NotEmpty(t, feed.StopTimes)
+	assert.NotEmpty(t, feed.CalendarDates)
+	assert.Empty(t, feed.Calendars, "the feed only uses calendar dates")
+	assert.Equal(t, "America/New_York", feed.Agencies[0].AgencyTimezone)
+}
+
+func TestLoadFeed_InterpolatesStopTimes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"agency.txt": {Data: []byte("agency_id,agency_name,agency_url,agency_timezone\nMTA,MTA,https://mta.info,America/New_York\n")},
+		"stops.txt":  {Data: []byte("\ufeffstop_id,stop_name,stop_lat,stop_lon\nA,A,40.1,-73.1\nB,B,40.2,-73.2\nC,C,40.3,-73.3\nD,D,40.4,-73.4\n")},
+		"routes.txt": {Data: []byte("route_id,agency_id,route_short_name,route_type\nR,MTA,R,1\n")},
+		"trips.txt":  {Data: []byte("route_id,service_id,trip_id\nR,WKD,T1\n")},
+		"stop_times.txt": {Data: []byte("trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"T1,24:59:00,25:00:00,A,1\n" +
+			"T1,,,C,3\n" +
+			"T1,,,B,2\n" +
+			"T1,25:06:00,25:06:00,D,4\n")},
+	}
+	feed, err := LoadFeed(fsys)
+	assert.NoError(t, err)
+	assert.Equal(t, "A", feed.Stops[0].StopID, "should strip the byte order mark")
+	assert.Equal(t, []string{"A", "B", "C", "D"}, []string{feed.StopTimes[0].StopID, feed.StopTimes[1].StopID, feed.StopTimes[2].StopID, feed.StopTimes[3].StopID})
+	assert.Equal(t, "25:02:00", FormatTime(feed.StopTimes[1].ArrivalTimeInSeconds))
+	assert.Equal(t, "25:04:00", FormatTime(feed.StopTimes[2].DepartureTimeInSeconds))
+	assert.True(t, feed.StopTimes[1].IsInterpolated)
+	assert.False(t, feed.StopTimes[3].IsInterpolated)
+
+	_, err = LoadFeed(fstest.MapFS{})
+	assert.Error(t, err, "should require the agencies")
+}
+
+func TestGetFootpathTransfers(t *testing.T) {
+	feed := &Feed{Transfers: []Transfer{
+		{FromStopID: "A", ToStopID: "B", TransferType: TransferTypeMinimumTime, MinimumTransferTimeInSeconds: 120},
+		{FromStopID: "A", ToStopID: "C", TransferType: TransferTypeNotPossible},
+		{FromStopID: "A", ToStopID: "D", FromTripID: "T1", ToTripID: "T2", TransferType: TransferTypeTimed},
+		{FromStopID: "A", ToStopID: "A", TransferType: TransferTypeMinimumTime, MinimumTransferTimeInSeconds: 300},
+	}}
+	assert.Equal(t, []Transfer{feed.Transfers[0]}, feed.GetFootpathTransfers())
+}
+
+func TestReadStops_Coordinates(t *testing.T) {
+	stops, err := ReadStops(strings.NewReader("stop_id,stop_name,stop_lat,stop_lon,location_type\nA,Null Island,0,0,0\nB,Node,,,3\n"))
+	assert.NoError(t, err)
+	latitude, longitude, has_coordinates := stops[0].GetCoordinates()
+	assert.True(t, has_coordinates, "should keep the (0, 0) coordinates when they are set")
+	assert.Equal(t, []float64{0, 0}, []float64{latitude, longitude})
+	_, _, has_coordinates = stops[1].GetCoordinates()
+	assert.False(t, has_coordinates)
+}
@@ -0,0 +1,250 @@
+package gtfs
+
+import (
+	"strings"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+/** a reference to an entity of one of the merged feeds */
+type FeedReference struct {
+	FeedID     string
+	OriginalID string
+}
+
+type NamedFeed struct {
+	/* used to namespace the IDs of the feed - should be unique across the merged feeds */
+	FeedID string
+	Feed   *Feed
+}
+
+type MergeOptions struct {
+	/* stops with the same name and location type within this distance of a stop of a previous feed are merged into it - 0 disables deduplication */
+	DeduplicationDistanceInMeters float64
+	/* transfers are generated between the stops of different feeds within this distance - 0 disables the transfers */
+	InterFeedTransferDistanceInMeters float64
+	/* defaults to raptor.DefaultWalkingSpeedInMetersPerSecond */
+	WalkingSpeedInMetersPerSecond float64
+}
+
+/**
+ * a feed in which all the IDs are namespaced by their feed ID - which keeps track of where every stop, trip and route came from
+ * the stop references can contain multiple feeds when identical stops were deduplicated
+ */
+type MergedFeed struct {
+	Feed
+	StopReferences  map[string][]FeedReference
+	TripReferences  map[string]FeedReference
+	RouteReferences map[string]FeedReference
+	/* the unique stop ID every stop of every feed ended up as */
+	UniqueStopIDsByReference map[FeedReference]string
+}
+
+/** namespaces an ID by its feed - empty IDs (unset references) are kept empty */
+func GetNamespacedID(feed_id string, id string) string {
+	if id == "" {
+		return ""
+	}
+	return feed_id + ":" + id
+}
+
+/**
+ * merges the feeds into a single feed with globally unique IDs - the feeds are merged in order so any deduplicated stop keeps
+ * the ID of the first feed it appeared in. agencies without an ID (single agency feeds) get the feed ID as their ID
+ */
+func MergeFeeds(feeds []NamedFeed, options MergeOptions) *MergedFeed {
+	if options.WalkingSpeedInMetersPerSecond <= 0 {
+		options.WalkingSpeedInMetersPerSecond = raptor.DefaultWalkingSpeedInMetersPerSecond
+	}
+	merged := &MergedFeed{
+		StopReferences:           map[string][]FeedReference{},
+		TripReferences:           map[string]FeedReference{},
+		RouteReferences:          map[string]FeedReference{},
+		UniqueStopIDsByReference: map[FeedReference]string{},
+	}
+	/* the merged stop indexes by their normalized name - used to find the duplicates */
+	stop_indexes_by_name := map[string][]int{}
+
+	for _, named_feed := range feeds {
+		feed_id := named_feed.FeedID
+		feed := named_feed.Feed
+		get_agency_id := func(agency_id string) string {
+			if agency_id == "" {
+				return feed_id
+			}
+			return GetNamespacedID(feed_id, agency_id)
+		}
+		get_stop_id := func(stop_id string) string {
+			if stop_id == "" {
+				return ""
+			}
+			return merged.UniqueStopIDsByReference[FeedReference{FeedID: feed_id, OriginalID: stop_id}]
+		}
+
+		for _, agency := range feed.Agencies {
+			agency.AgencyID = get_agency_id(agency.AgencyID)
+			merged.Agencies = append(merged.Agencies, agency)
+		}
+
+		/* the unique IDs are allocated first since the parent stations can be defined after their children */
+		new_stops := []Stop{}
+		for _, stop := range feed.Stops {
+			reference := FeedReference{FeedID: feed_id, OriginalID: stop.StopID}
+			name := strings.ToLower(strings.TrimSpace(stop.StopName))
+			duplicate_index := -1
+			if options.DeduplicationDistanceInMeters > 0 && name != "" {
+				for _, stop_index := range stop_indexes_by_name[name] {
+					existing_stop := merged.Stops[stop_index]
+					if existing_stop.LocationType == stop.LocationType &&
+						raptor.GetHaversineDistanceInMeters(existing_stop.Latitude, existing_stop.Longitude, stop.Latitude, stop.Longitude) <= options.DeduplicationDistanceInMeters {
+						duplicate_index = stop_index
+						break
+					}
+				}
+			}
+			if duplicate_index != -1 {
+				unique_stop_id := merged.Stops[duplicate_index].StopID
+				merged.UniqueStopIDsByReference[reference] = unique_stop_id
+				merged.StopReferences[unique_stop_id] = append(merged.StopReferences[unique_stop_id], reference)
+				continue
+			}
+			unique_stop_id := GetNamespacedID(feed_id, stop.StopID)
+			merged.UniqueStopIDsByReference[reference] = unique_stop_id
+			merged.StopReferences[unique_stop_id] = []FeedReference{reference}
+			new_stops = append(new_stops, stop)
+		}
+		/* the stops of the same feed are never merged with each other so they are only added to the name lookup afterwards */
+		for _, stop := range new_stops {
+			stop.StopID = get_stop_id(stop.StopID)
+			stop.ParentStation = get_stop_id(stop.ParentStation)
+			stop.LevelID = GetNamespacedID(feed_id, stop.LevelID)
+			name := strings.ToLower(strings.TrimSpace(stop.StopName))
+			stop_indexes_by_name[name] = append(stop_indexes_by_name[name], len(merged.Stops))
+			merged.Stops = append(merged.Stops, stop)
+		}
+
+		for _, route := range feed.Routes {
+			merged.RouteReferences[GetNamespacedID(feed_id, route.RouteID)] = FeedReference{FeedID: feed_id, OriginalID: route.RouteID}
+			route.RouteID = GetNamespacedID(feed_id, route.RouteID)
+			route.AgencyID = get_agency_id(route.AgencyID)
+			merged.Routes = append(merged.Routes, route)
+		}
+		for _, trip := range feed.Trips {
+			merged.TripReferences[GetNamespacedID(feed_id, trip.TripID)] = FeedReference{FeedID: feed_id, OriginalID: trip.TripID}
+			trip.TripID = GetNamespacedID(feed_id, trip.TripID)
+			trip.RouteID = GetNamespacedID(feed_id, trip.RouteID)
+			trip.ServiceID = GetNamespacedID(feed_id, trip.ServiceID)
+			trip.ShapeID = GetNamespacedID(feed_id, trip.ShapeID)
+			merged.Trips = append(merged.Trips, trip)
+		}
+		for _, stop_time := range feed.StopTimes {
+			stop_time.TripID = GetNamespacedID(feed_id, stop_time.TripID)
+			stop_time.StopID = get_stop_id(stop_time.StopID)
+			merged.StopTimes = append(merged.StopTimes, stop_time)
+		}
+		for _, calendar := range feed.Calendars {
+			calendar.ServiceID = GetNamespacedID(feed_id, calendar.ServiceID)
+			merged.Calendars = append(merged.Calendars, calendar)
+		}
+		for _, calendar_date := range feed.CalendarDates {
+			calendar_date.ServiceID = GetNamespacedID(feed_id, calendar_date.ServiceID)
+			merged.CalendarDates = append(merged.CalendarDates, calendar_date)
+		}
+		for _, transfer := range feed.Transfers {
+			transfer.FromStopID = get_stop_id(transfer.FromStopID)
+			transfer.ToStopID = get_stop_id(transfer.ToStopID)
+			transfer.FromTripID = GetNamespacedID(feed_id, transfer.FromTripID)
+			transfer.ToTripID = GetNamespacedID(feed_id, transfer.ToTripID)
+			merged.Transfers = append(merged.Transfers, transfer)
+		}
+		for _, frequency := range feed.Frequencies {
+			frequency.TripID = GetNamespacedID(feed_id, frequency.TripID)
+			merged.Frequencies = append(merged.Frequencies, frequency)
+		}
+		for _, pathway := range feed.Pathways {
+			pathway.PathwayID = GetNamespacedID(feed_id, pathway.PathwayID)
+			pathway.FromStopID = get_stop_id(pathway.FromStopID)
+			pathway.ToStopID = get_stop_id(pathway.ToStopID)
+			merged.Pathways = append(merged.Pathways, pathway)
+		}
+		for _, level := range feed.Levels {
+			level.LevelID = GetNamespacedID(feed_id, level.LevelID)
+			merged.Levels = append(merged.Levels, level)
+		}
+		for _, shape_point := range feed.Shapes {
+			shape_point.ShapeID = GetNamespacedID(feed_id, shape_point.ShapeID)
+			merged.Shapes = append(merged.Shapes, shape_point)
+		}
+	}
+
+	if options.InterFeedTransferDistanceInMeters > 0 {
+		merged.Transfers = append(merged.Transfers, merged.getInterFeedTransfers(options)...)
+	}
+	return merged
+}
+
+/** generates the walking transfers between the nearby stops which do not share any feed */
+func (merged *MergedFeed) getInterFeedTransfers(options MergeOptions) []Transfer {
+	stops := []Stop{}
+	for _, stop := range merged.Stops {
+		if stop.LocationType == LocationTypeStop {
+			stops = append(stops, stop)
+		}
+	}
+	transfers := []Transfer{}
+	for _, transfer := range raptor.GenerateTransfersFromCoordinates[string](stops, options.InterFeedTransferDistanceInMeters, options.WalkingSpeedInMetersPerSecond) {
+		if merged.sharesFeed(transfer.FromUniqueStopID, transfer.ToUniqueStopID) {
+			continue
+		}
+		transfers = append(transfers, Transfer{
+			FromStopID:                   transfer.FromUniqueStopID,
+			ToStopID:                     transfer.ToUniqueStopID,
+			TransferType:                 TransferTypeMinimumTime,
+			MinimumTransferTimeInSeconds: transfer.MinimumTransferTimeInSeconds,
+		})
+	}
+	return transfers
+}
+
+func (merged *MergedFeed) sharesFeed(from_unique_stop_id string, to_unique_stop_id string) bool {
+	for _, from_reference := range merged.StopReferences[from_unique_stop_id] {
+		for _, to_reference := range merged.StopReferences[to_unique_stop_id] {
+			if from_reference.FeedID == to_reference.FeedID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/** allocates dense uint64 IDs for unique string IDs - to use the merged feed with a numeric raptor ID type */
+type Uint64IdMapping struct {
+	Uint64IDsByUniqueID map[string]uint64
+	/* the unique ID of every allocated uint64 ID by its value */
+	UniqueIDs []string
+}
+
+func NewUint64IdMapping() *Uint64IdMapping {
+	return &Uint64IdMapping{
+		Uint64IDsByUniqueID: map[string]uint64{},
+		UniqueIDs:           []string{},
+	}
+}
+
+/** gets the uint64 ID of the unique ID - allocating a new one the first time */
+func (mapping *Uint64IdMapping) GetUint64ID(unique_id string) uint64 {
+	if id, has_id := mapping.Uint64IDsByUniqueID[unique_id]; has_id {
+		return id
+	}
+	id := uint64(len(mapping.UniqueIDs))
+	mapping.Uint64IDsByUniqueID[unique_id] = id
+	mapping.UniqueIDs = append(mapping.UniqueIDs, unique_id)
+	return id
+}
+
+func (mapping *Uint64IdMapping) GetUniqueID(id uint64) (string, bool) {
+	if id >= uint64(len(mapping.UniqueIDs)) {
+		return "", false
+	}
+	return mapping.UniqueIDs[id], true
+}
@@ -0,0 +1,66 @@
+package gtfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeFeeds(t *testing.T) {
+	lirr := &Feed{
+		Agencies: []Agency{{AgencyName: "LIRR"}},
+		Stops: []Stop{
+			{StopID: "1", StopName: "Penn Station", Latitude: 40.750580, Longitude: -73.993584, HasCoordinates: true},
+			{StopID: "2", StopName: "Jamaica", Latitude: 40.699768, Longitude: -73.808090, HasCoordinates: true},
+			/* roughly 125 meters from the NJT 34 St stop */
+			{StopID: "3", StopName: "Herald Sq", Latitude: 40.749600, Longitude: -73.991000, HasCoordinates: true},
+		},
+		Routes:    []Route{{RouteID: "1", RouteType: 2}},
+		Trips:     []Trip{{TripID: "T1", RouteID: "1", ServiceID: "WKD"}},
+		StopTimes: []StopTime{{TripID: "T1", StopID: "1", StopSequence: 1}, {TripID: "T1", StopID: "2", StopSequence: 2}},
+	}
+	njt := &Feed{
+		Agencies: []Agency{{AgencyID: "NJT", AgencyName: "NJ Transit"}},
+		Stops: []Stop{
+			/* the same station as the LIRR one - but a few meters off */
+			{StopID: "1", StopName: "Penn Station ", Latitude: 40.750600, Longitude: -73.993600, HasCoordinates: true},
+			/* roughly 150 meters from penn station */
+			{StopID: "2", StopName: "34 St", Latitude: 40.749500, Longitude: -73.992500, HasCoordinates: true},
+		},
+		Routes:    []Route{{RouteID: "1", AgencyID: "NJT", RouteType: 2}},
+		Trips:     []Trip{{TripID: "T1", RouteID: "1", ServiceID: "WKD"}},
+		StopTimes: []StopTime{{TripID: "T1", StopID: "2", StopSequence: 1}, {TripID: "T1", StopID: "1", StopSequence: 2}},
+		Transfers: []Transfer{{FromStopID: "1", ToStopID: "2", TransferType: TransferTypeMinimumTime, MinimumTransferTimeInSeconds: 60}},
+	}
+
+	merged := MergeFeeds([]NamedFeed{{FeedID: "lirr", Feed: lirr}, {FeedID: "njt", Feed: njt}}, MergeOptions{
+		DeduplicationDistanceInMeters:     25,
+		InterFeedTransferDistanceInMeters: 200,
+	})
+
+	assert.Equal(t, []string{"lirr", "njt:NJT"}, []string{merged.Agencies[0].AgencyID, merged.Agencies[1].AgencyID})
+	assert.Equal(t, "lirr", merged.Routes[0].AgencyID, "should use the feed ID for agencies without an ID")
+	assert.Len(t, merged.Stops, 4, "should deduplicate penn station")
+	assert.Equal(t, []FeedReference{{FeedID: "lirr", OriginalID: "1"}, {FeedID: "njt", OriginalID: "1"}}, merged.StopReferences["lirr:1"])
+	assert.Equal(t, "lirr:1", merged.UniqueStopIDsByReference[FeedReference{FeedID: "njt", OriginalID: "1"}])
+	assert.Equal(t, FeedReference{FeedID: "njt", OriginalID: "T1"}, merged.TripReferences["njt:T1"])
+	assert.Equal(t, FeedReference{FeedID: "lirr", OriginalID: "1"}, merged.RouteReferences["lirr:1"])
+	assert.Equal(t, "lirr:1", merged.StopTimes[3].StopID, "should point the stop times to the deduplicated stop")
+	assert.Equal(t, "njt:WKD", merged.Trips[1].ServiceID)
+
+	/* the original transfer and the generated ones between herald sq and 34 st - penn station already shares the NJT feed with 34 st */
+	assert.Len(t, merged.Transfers, 3)
+	assert.Equal(t, Transfer{FromStopID: "lirr:1", ToStopID: "njt:2", TransferType: TransferTypeMinimumTime, MinimumTransferTimeInSeconds: 60}, merged.Transfers[0])
+	assert.ElementsMatch(t, []string{"lirr:3->njt:2", "njt:2->lirr:3"}, []string{
+		merged.Transfers[1].FromStopID + "->" + merged.Transfers[1].ToStopID,
+		merged.Transfers[2].FromStopID + "->" + merged.Transfers[2].ToStopID,
+	})
+
+	mapping := NewUint64IdMapping()
+	assert.Equal(t, uint64(0), mapping.GetUint64ID("lirr:1"))
+	assert.Equal(t, uint64(1), mapping.GetUint64ID("njt:2"))
+	assert.Equal(t, uint64(0), mapping.GetUint64ID("lirr:1"))
+	unique_id, has_unique_id := mapping.GetUniqueID(1)
+	assert.True(t, has_unique_id)
+	assert.Equal(t, "njt:2", unique_id)
+}
@@ -0,0 +1,162 @@
+package gtfs
+
+import (
+	"strings"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/geo"
+)
+
+/**
+ * implements the raptor MetadataStore and the geo GeometryStore using the feed - the stop calls can be looked up by trip ID (with the times of
+ * the stop times as-is) or by the dated trip service IDs of BuildTimetable (with the unix times of the timetable)
+ */
+type FeedMetadataStore struct {
+	/* the timezone of the feed - nil when the feed has no valid agency timezone */
+	Location          *time.Location
+	RoutesByID        map[string]Route
+	TripsByID         map[string]Trip
+	StopsByID         map[string]Stop
+	StopTimesByTripID map[string][]StopTime
+	ShapesByID        map[string][]geo.ShapePoint
+}
+
+func NewFeedMetadataStore(feed *Feed) *FeedMetadataStore {
+	store := &FeedMetadataStore{
+		RoutesByID:        make(map[string]Route, len(feed.Routes)),
+		TripsByID:         make(map[string]Trip, len(feed.Trips)),
+		StopsByID:         make(map[string]Stop, len(feed.Stops)),
+		StopTimesByTripID: make(map[string][]StopTime, len(feed.Trips)),
+		ShapesByID:        map[string][]geo.ShapePoint{},
+	}
+	if location, err := feed.GetLocation(); err == nil {
+		store.Location = location
+	}
+	for _, route := range feed.Routes {
+		store.RoutesByID[route.RouteID] = route
+	}
+	for _, trip := range feed.Trips {
+		store.TripsByID[trip.TripID] = trip
+	}
+	for _, stop := range feed.Stops {
+		store.StopsByID[stop.StopID] = stop
+	}
+	/* the stop times are already ordered by trip and stop sequence */
+	for _, stop_time := range feed.StopTimes {
+		store.StopTimesByTripID[stop_time.TripID] = append(store.StopTimesByTripID[stop_time.TripID], stop_time)
+	}
+	/* as are the shape points by shape and sequence */
+	for _, shape_point := range feed.Shapes {
+		store.ShapesByID[shape_point.ShapeID] = append(store.ShapesByID[shape_point.ShapeID], geo.ShapePoint{
+			Coordinate:          geo.Coordinate{Latitude: shape_point.Latitude, Longitude: shape_point.Longitude},
+			DistanceTraveled:    shape_point.DistTraveled,
+			HasDistanceTraveled: shape_point.HasDistTraveled,
+		})
+	}
+	return store
+}
+
+func (store *FeedMetadataStore) GetRouteMetadata(route_id string) (raptor.RouteMetadata, bool) {
+	route, has_route := store.RoutesByID[route_id]
+	if !has_route {
+		return raptor.RouteMetadata{}, false
+	}
+	return raptor.RouteMetadata{
+		ShortName: route.RouteShortName,
+		LongName:  route.RouteLongName,
+		Color:     route.RouteColor,
+		TextColor: route.RouteTextColor,
+		RouteType: route.RouteType,
+	}, true
+}
+
+func (store *FeedMetadataStore) GetTripMetadata(trip_id string) (raptor.TripMetadata[string], bool) {
+	trip, has_trip := store.TripsByID[trip_id]
+	if !has_trip {
+		return raptor.TripMetadata[string]{}, false
+	}
+	return raptor.TripMetadata[string]{
+		UniqueRouteID: trip.RouteID,
+		Headsign:      trip.TripHeadsign,
+		ShortName:     trip.TripShortName,
+	}, true
+}
+
+func (store *FeedMetadataStore) GetStopMetadata(stop_id string) (raptor.StopMetadata, bool) {
+	stop, has_stop := store.StopsByID[stop_id]
+	if !has_stop {
+		return raptor.StopMetadata{}, false
+	}
+	return raptor.StopMetadata{
+		Name:         stop.StopName,
+		Latitude:     stop.Latitude,
+		Longitude:    stop.Longitude,
+		PlatformCode: stop.PlatformCode,
+	}, true
+}
+
+/** accepts both trip IDs and dated trip service IDs - the times of the latter are shifted to match the timetable (see getTripServiceTimeOffset) */
+func (store *FeedMetadataStore) GetTripServiceStopCalls(trip_service_id string) []raptor.StopCall[string] {
+	stop_times := store.StopTimesByTripID[GetTripIDFromTripServiceID(trip_service_id)]
+	time_offset := store.getTripServiceTimeOffset(trip_service_id, stop_times)
+	stop_calls := make([]raptor.StopCall[string], len(stop_times))
+	for index, stop_time := range stop_times {
+		stop_calls[index] = raptor.StopCall[string]{
+			UniqueStopID:           stop_time.StopID,
+			StopSequence:           stop_time.StopSequence,
+			ArrivalTimeInSeconds:   stop_time.ArrivalTimeInSeconds + time_offset,
+			DepartureTimeInSeconds: stop_time.DepartureTimeInSeconds + time_offset,
+		}
+	}
+	return stop_calls
+}
+
+/**
+ * the seconds to add to the stop times of the trip for the dated trip service ID - the start of the service day and for the trip instances
+ * of expanded frequencies (trip@date/HH:MM:SS) the offset of the start time from the first departure. 0 for trip IDs which are not dated
+ */
+func (store *FeedMetadataStore) getTripServiceTimeOffset(trip_service_id string, stop_times []StopTime) raptor.TimestampInSeconds {
+	separator_index := strings.LastIndex(trip_service_id, "@")
+	if separator_index == -1 || store.Location == nil || len(stop_times) == 0 {
+		return 0
+	}
+	service_date, start_time, has_start_time := strings.Cut(trip_service_id[separator_index+1:], "/")
+	date, err := time.ParseInLocation(DateLayout, service_date, store.Location)
+	if err != nil {
+		return 0
+	}
+	time_offset := GetServiceDayStart(date).Unix()
+	if has_start_time {
+		if start_time_in_seconds, err := ParseTime(start_time); err == nil {
+			time_offset += start_time_in_seconds - stop_times[0].DepartureTimeInSeconds
+		}
+	}
+	return time_offset
+}
+
+func (store *FeedMetadataStore) GetStopCoordinate(stop_id string) (geo.Coordinate, bool) {
+	stop, has_stop := store.StopsByID[stop_id]
+	if !has_stop || !stop.HasCoordinates {
+		return geo.Coordinate{}, false
+	}
+	return geo.Coordinate{Latitude: stop.Latitude, Longitude: stop.Longitude}, true
+}
+
+func (store *FeedMetadataStore) GetTripShape(trip_id string) []geo.ShapePoint {
+	trip, has_trip := store.TripsByID[trip_id]
+	if !has_trip || trip.ShapeID == "" {
+		return []geo.ShapePoint{}
+	}
+	return store.ShapesByID[trip.ShapeID]
+}
+
+/** accepts both trip IDs and dated trip service IDs (see GetDatedTripServiceID) */
+func (store *FeedMetadataStore) GetShapeDistanceTraveled(trip_service_id string, stop_sequence int) (float64, bool) {
+	for _, stop_time := range store.StopTimesByTripID[GetTripIDFromTripServiceID(trip_service_id)] {
+		if stop_time.StopSequence == stop_sequence {
+			return stop_time.ShapeDistTraveled, stop_time.HasShapeDistTraveled
+		}
+	}
+	return 0, false
+}
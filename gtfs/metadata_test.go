@@ -0,0 +1,152 @@
+package gtfs
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnrichJourneys_FeedMetadataStore(t *testing.T) {
+	fsys := fstest.MapFS{
+		"agency.txt": {Data: []byte("agency_id,agency_name,agency_url,agency_timezone\nMTA,MTA,https://mta.info,America/New_York\n")},
+		"stops.txt":  {Data: []byte("stop_id,stop_name,stop_lat,stop_lon,platform_code\nA,Astoria,40.1,-73.1,1\nB,Broadway,40.2,-73.2,\nC,Canal St,40.3,-73.3,2\n")},
+		"routes.txt": {Data: []byte("route_id,agency_id,route_short_name,route_long_name,route_type,route_color,route_text_color\nN,MTA,N,Broadway Express,1,FCCC0A,000000\n")},
+		"trips.txt":  {Data: []byte("route_id,service_id,trip_id,trip_headsign\nN,WKD,N1,Coney Island\n")},
+		"stop_times.txt": {Data: []byte("trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"N1,08:00:00,08:00:00,A,1\n" +
+			"N1,08:05:00,08:06:00,B,2\n" +
+			"N1,08:10:00,08:10:00,C,3\n")},
+	}
+	feed, err := LoadFeed(fsys)
+	assert.NoError(t, err)
+	store := NewFeedMetadataStore(feed)
+
+	journeys := raptor.SimpleRaptor(raptor.SimpleRaptorInput[string, Stop, Transfer, StopTime]{
+		FromStops:        []Stop{{StopID: "A"}},
+		ToStops:          []Stop{{StopID: "C"}},
+		Transfers:        []Transfer{},
+		StopTimes:        feed.StopTimes,
+		Mode:             raptor.RaptorModeDepartAt,
+		TimeInSeconds:    7 * 3600,
+		MaximumTransfers: 2,
+	})
+	assert.Len(t, journeys, 1)
+
+	enriched_journeys := raptor.EnrichJourneys(journeys, store)
+	assert.Len(t, enriched_journeys, 1)
+	enriched_journey := enriched_journeys[0]
+	assert.Equal(t, "Astoria", enriched_journey.FromStop.Name)
+	assert.Equal(t, "Canal St", enriched_journey.ToStop.Name)
+	assert.Len(t, enriched_journey.Legs, 1)
+
+	leg := enriched_journey.Legs[0]
+	assert.Equal(t, "1", leg.FromStop.PlatformCode)
+	assert.Equal(t, 40.3, leg.ToStop.Latitude)
+	assert.Equal(t, "Coney Island", leg.Trip.Headsign)
+	assert.Equal(t, raptor.EnrichedRoute[string]{
+		UniqueRouteID: "N",
+		RouteMetadata: raptor.RouteMetadata{ShortName: "N", LongName: "Broadway Express", Color: "FCCC0A", TextColor: "000000", RouteType: raptor.GtfsRouteTypeSubway},
+	}, *leg.Trip.Route)
+	assert.Equal(t, []raptor.EnrichedStopCall[string]{{
+		Stop:                   raptor.EnrichedStop[string]{UniqueStopID: "B", StopMetadata: raptor.StopMetadata{Name: "Broadway", Latitude: 40.2, Longitude: -73.2}},
+		StopSequence:           2,
+		ArrivalTimeInSeconds:   8*3600 + 5*60,
+		DepartureTimeInSeconds: 8*3600 + 6*60,
+	}}, leg.Trip.IntermediateStops, "should only contain the stops between boarding and alighting")
+
+	encoded, err := json.Marshal(enriched_journeys)
+	assert.NoError(t, err)
+	var decoded []raptor.EnrichedJourney[string]
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, enriched_journeys, decoded)
+}
+
+func TestEnrichJourneys_FeedMetadataStoreTimetable(t *testing.T) {
+	fsys := fstest.MapFS{
+		"agency.txt":      {Data: []byte("agency_id,agency_name,agency_url,agency_timezone\nMTA,MTA,https://mta.info,America/New_York\n")},
+		"stops.txt":       {Data: []byte("stop_id,stop_name,stop_lat,stop_lon\nA,Astoria,40.1,-73.1\nB,Broadway,40.2,-73.2\nC,Canal St,40.3,-73.3\n")},
+		"routes.txt":      {Data: []byte("route_id,agency_id,route_short_name,route_type\nN,MTA,N,1\n")},
+		"calendar.txt":    {Data: []byte("service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\nWKD,1,1,1,1,1,0,0,20250101,20251231\n")},
+		"trips.txt":       {Data: []byte("route_id,service_id,trip_id\nN,WKD,N1\nN,WKD,F1\n")},
+		"frequencies.txt": {Data: []byte("trip_id,start_time,end_time,headway_secs,exact_times\nF1,10:00:00,11:00:00,1800,0\n")},
+		"stop_times.txt": {Data: []byte("trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"N1,08:00:00,08:00:00,A,1\n" +
+			"N1,08:05:00,08:06:00,B,2\n" +
+			"N1,08:10:00,08:10:00,C,3\n" +
+			"F1,00:00:00,00:00:00,A,1\n" +
+			"F1,00:05:00,00:06:00,B,2\n" +
+			"F1,00:10:00,00:10:00,C,3\n")},
+	}
+	feed, err := LoadFeed(fsys)
+	assert.NoError(t, err)
+	location, err := feed.GetLocation()
+	assert.NoError(t, err)
+	thursday := time.Date(2025, 7, 3, 0, 0, 0, 0, location)
+	timetable, err := feed.BuildTimetable([]time.Time{thursday})
+	assert.NoError(t, err)
+	store := NewFeedMetadataStore(feed)
+
+	input := raptor.SimpleRaptorInput[string, Stop, Transfer, raptor.GtfsStopTimeStruct[string]]{
+		FromStops:                     []Stop{{StopID: "A"}},
+		ToStops:                       []Stop{{StopID: "C"}},
+		Transfers:                     []Transfer{},
+		StopTimes:                     timetable.StopTimes,
+		EstimatedUniqueTripServiceIDs: timetable.EstimatedUniqueTripServiceIDs,
+		Mode:                          raptor.RaptorModeDepartAt,
+		TimeInSeconds:                 thursday.Add(7 * time.Hour).Unix(),
+		MaximumTransfers:              2,
+	}
+	enriched_journeys := raptor.EnrichJourneys(raptor.SimpleRaptor(input), store)
+	assert.Len(t, enriched_journeys, 1)
+	leg := enriched_journeys[0].Legs[0]
+	assert.Equal(t, "N1@20250703", leg.Trip.UniqueTripServiceID)
+	assert.Len(t, leg.Trip.IntermediateStops, 1, "should find the calls of the dated trip service")
+	assert.Equal(t, "B", leg.Trip.IntermediateStops[0].Stop.UniqueStopID)
+	assert.Equal(t, thursday.Add(8*time.Hour+5*time.Minute).Unix(), leg.Trip.IntermediateStops[0].ArrivalTimeInSeconds, "should use the times of the timetable")
+
+	/* the trip instances of the frequencies are shifted to their start time */
+	input.TimeInSeconds = thursday.Add(10*time.Hour + 15*time.Minute).Unix()
+	enriched_journeys = raptor.EnrichJourneys(raptor.SimpleRaptor(input), store)
+	assert.Len(t, enriched_journeys, 1)
+	leg = enriched_journeys[0].Legs[0]
+	assert.Equal(t, "F1@20250703/10:30:00", leg.Trip.UniqueTripServiceID)
+	assert.Len(t, leg.Trip.IntermediateStops, 1)
+	assert.Equal(t, thursday.Add(10*time.Hour+35*time.Minute).Unix(), leg.Trip.IntermediateStops[0].ArrivalTimeInSeconds)
+	assert.Equal(t, thursday.Add(10*time.Hour+36*time.Minute).Unix(), leg.Trip.IntermediateStops[0].DepartureTimeInSeconds)
+}
+
+func TestFeedMetadataStore_ShapeGeometry(t *testing.T) {
+	feed, err := LoadFeedZip("../gtfslirr.zip")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, feed.Shapes)
+	store := NewFeedMetadataStore(feed)
+
+	stop_times := store.StopTimesByTripID[feed.Trips[0].TripID]
+	assert.GreaterOrEqual(t, len(stop_times), 3)
+	from_stop_time := stop_times[0]
+	to_stop_time := stop_times[2]
+	leg := raptor.RoundSegmentSpan[string]{
+		FromUniqueStopID:                       from_stop_time.StopID,
+		ToUniqueStopID:                         to_stop_time.StopID,
+		DepartureTimeInSecondsFromUniqueStopID: from_stop_time.DepartureTimeInSeconds,
+		ArrivalTimeInSecondsToUniqueStopID:     to_stop_time.ArrivalTimeInSeconds,
+		ViaTrip: &raptor.ViaTrip[string]{
+			UniqueTripID:           from_stop_time.TripID,
+			UniqueTripServiceID:    from_stop_time.TripID,
+			FromStopSequenceInTrip: from_stop_time.StopSequence,
+			ToStopSequenceInTrip:   to_stop_time.StopSequence,
+		},
+	}
+	coordinates := geo.GetLegCoordinates(leg, store)
+	assert.Greater(t, len(coordinates), 3, "should follow the shape rather than draw a straight line")
+	from_stop := store.StopsByID[from_stop_time.StopID]
+	to_stop := store.StopsByID[to_stop_time.StopID]
+	/* the shape passes the platforms rather than the stop coordinates */
+	assert.Less(t, raptor.GetHaversineDistanceInMeters(from_stop.Latitude, from_stop.Longitude, coordinates[0].Latitude, coordinates[0].Longitude), 200.0)
+	assert.Less(t, raptor.GetHaversineDistanceInMeters(to_stop.Latitude, to_stop.Longitude, coordinates[len(coordinates)-1].Latitude, coordinates[len(coordinates)-1].Longitude), 200.0)
+}
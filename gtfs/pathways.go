@@ -0,0 +1,371 @@
+package gtfs
+
+import (
+	"container/heap"
+	"io"
+	"math"
+	"sort"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+type PathwayMode = int
+
+const (
+	PathwayModeWalkway        PathwayMode = 1
+	PathwayModeStairs         PathwayMode = 2
+	PathwayModeMovingSidewalk PathwayMode = 3
+	PathwayModeEscalator      PathwayMode = 4
+	PathwayModeElevator       PathwayMode = 5
+	PathwayModeFareGate       PathwayMode = 6
+	PathwayModeExitGate       PathwayMode = 7
+)
+
+/** a pathways.txt entry */
+type Pathway struct {
+	PathwayID       string
+	FromStopID      string
+	ToStopID        string
+	PathwayMode     PathwayMode
+	IsBidirectional bool
+	/* in meters */
+	Length                 float64
+	TraversalTimeInSeconds int
+	/* positive when going up - negative when going down */
+	StairCount           int
+	SignpostedAs         string
+	ReversedSignpostedAs string
+}
+
+/** a levels.txt entry */
+type Level struct {
+	LevelID    string
+	LevelIndex float64
+	LevelName  string
+}
+
+func ReadPathways(reader io.Reader) ([]Pathway, error) {
+	pathways := []Pathway{}
+	err := readCsv(reader, func(row csvRow) error {
+		pathway := Pathway{
+			PathwayID:            row.Get("pathway_id"),
+			FromStopID:           row.Get("from_stop_id"),
+			ToStopID:             row.Get("to_stop_id"),
+			IsBidirectional:      row.Get("is_bidirectional") == "1",
+			SignpostedAs:         row.Get("signposted_as"),
+			ReversedSignpostedAs: row.Get("reversed_signposted_as"),
+		}
+		var err error
+		if pathway.PathwayMode, err = row.GetInt("pathway_mode", PathwayModeWalkway); err != nil {
+			return err
+		}
+		if pathway.Length, err = row.GetFloat("length", 0); err != nil {
+			return err
+		}
+		if pathway.TraversalTimeInSeconds, err = row.GetInt("traversal_time", 0); err != nil {
+			return err
+		}
+		if pathway.StairCount, err = row.GetInt("stair_count", 0); err != nil {
+			return err
+		}
+		pathways = append(pathways, pathway)
+		return nil
+	})
+	return pathways, err
+}
+
+func ReadLevels(reader io.Reader) ([]Level, error) {
+	levels := []Level{}
+	err := readCsv(reader, func(row csvRow) error {
+		level := Level{
+			LevelID:   row.Get("level_id"),
+			LevelName: row.Get("level_name"),
+		}
+		var err error
+		if level.LevelIndex, err = row.GetFloat("level_index", 0); err != nil {
+			return err
+		}
+		levels = append(levels, level)
+		return nil
+	})
+	return levels, err
+}
+
+/** the assumptions used for pathways without a traversal_time - any zero value falls back to the default */
+type PathwayOptions struct {
+	WalkingSpeedInMetersPerSecond float64
+	StairTimeInSeconds            float64
+	ElevatorWaitTimeInSeconds     int
+	ElevatorTimePerLevelInSeconds int
+	/* used when there is neither a length nor a stair count */
+	DefaultTraversalTimeInSeconds int
+}
+
+const (
+	DefaultStairTimeInSeconds            = 0.6
+	DefaultElevatorWaitTimeInSeconds     = 60
+	DefaultElevatorTimePerLevelInSeconds = 10
+	DefaultTraversalTimeInSeconds        = 30
+)
+
+func (options PathwayOptions) withDefaults() PathwayOptions {
+	if options.WalkingSpeedInMetersPerSecond <= 0 {
+		options.WalkingSpeedInMetersPerSecond = raptor.DefaultWalkingSpeedInMetersPerSecond
+	}
+	if options.StairTimeInSeconds <= 0 {
+		options.StairTimeInSeconds = DefaultStairTimeInSeconds
+	}
+	if options.ElevatorWaitTimeInSeconds <= 0 {
+		options.ElevatorWaitTimeInSeconds = DefaultElevatorWaitTimeInSeconds
+	}
+	if options.ElevatorTimePerLevelInSeconds <= 0 {
+		options.ElevatorTimePerLevelInSeconds = DefaultElevatorTimePerLevelInSeconds
+	}
+	if options.DefaultTraversalTimeInSeconds <= 0 {
+		options.DefaultTraversalTimeInSeconds = DefaultTraversalTimeInSeconds
+	}
+	return options
+}
+
+/** a platform to platform transfer derived from the pathways - implements the raptor GtfsTransferWithPath interface */
+type PathwayTransfer struct {
+	raptor.GtfsTransferStruct[string]
+	Path []raptor.TransferPathStep[string]
+}
+
+func (t PathwayTransfer) GetPath() []raptor.TransferPathStep[string] {
+	return t.Path
+}
+
+type pathwayEdge struct {
+	ToStopID               string
+	PathwayID              string
+	PathwayMode            PathwayMode
+	TraversalTimeInSeconds int
+	SignpostedAs           string
+	IsWheelchairAccessible bool
+}
+
+/**
+ * computes the walking times between all the platforms (and stops) which are connected by pathways
+ * for every pair the fastest path is emitted - if that path is not wheelchair accessible (stairs, escalators or inaccessible nodes)
+ * the fastest wheelchair accessible path is emitted as an additional transfer so the accessibility profile can use it instead
+ */
+func ComputePathwayTransfers(stops []Stop, pathways []Pathway, levels []Level, options PathwayOptions) []PathwayTransfer {
+	options = options.withDefaults()
+	stops_by_id := make(map[string]Stop, len(stops))
+	for _, stop := range stops {
+		stops_by_id[stop.StopID] = stop
+	}
+	levels_by_id := make(map[string]Level, len(levels))
+	for _, level := range levels {
+		levels_by_id[level.LevelID] = level
+	}
+
+	edges_by_stop_id := map[string][]pathwayEdge{}
+	has_incoming_edges := map[string]bool{}
+	add_edge := func(from_stop_id string, edge pathwayEdge) {
+		from_stop, has_from_stop := stops_by_id[from_stop_id]
+		to_stop, has_to_stop := stops_by_id[edge.ToStopID]
+		edge.IsWheelchairAccessible = edge.IsWheelchairAccessible &&
+			!(has_from_stop && from_stop.WheelchairBoarding == raptor.GtfsWheelchairAccessibilityNotAccessible) &&
+			!(has_to_stop && to_stop.WheelchairBoarding == raptor.GtfsWheelchairAccessibilityNotAccessible)
+		edges_by_stop_id[from_stop_id] = append(edges_by_stop_id[from_stop_id], edge)
+		has_incoming_edges[edge.ToStopID] = true
+	}
+	for _, pathway := range pathways {
+		is_wheelchair_accessible := pathway.PathwayMode != PathwayModeStairs && pathway.PathwayMode != PathwayModeEscalator
+		/* the traversal time is assumed to be the same in both directions */
+		traversal_time := options.getTraversalTimeInSeconds(pathway, stops_by_id, levels_by_id)
+		add_edge(pathway.FromStopID, pathwayEdge{
+			ToStopID:               pathway.ToStopID,
+			PathwayID:              pathway.PathwayID,
+			PathwayMode:            pathway.PathwayMode,
+			TraversalTimeInSeconds: traversal_time,
+			SignpostedAs:           pathway.SignpostedAs,
+			IsWheelchairAccessible: is_wheelchair_accessible,
+		})
+		if pathway.IsBidirectional {
+			add_edge(pathway.ToStopID, pathwayEdge{
+				ToStopID:               pathway.FromStopID,
+				PathwayID:              pathway.PathwayID,
+				PathwayMode:            pathway.PathwayMode,
+				TraversalTimeInSeconds: traversal_time,
+				SignpostedAs:           pathway.ReversedSignpostedAs,
+				IsWheelchairAccessible: is_wheelchair_accessible,
+			})
+		}
+	}
+	/* boarding areas are part of their platform so they are connected without any walking time */
+	for _, stop := range stops {
+		if stop.LocationType == LocationTypeBoardingArea && stop.ParentStation != "" {
+			add_edge(stop.ParentStation, pathwayEdge{ToStopID: stop.StopID, IsWheelchairAccessible: true})
+			add_edge(stop.StopID, pathwayEdge{ToStopID: stop.ParentStation, IsWheelchairAccessible: true})
+		}
+	}
+
+	/* the stops which can have stop times - sorted to keep the output stable */
+	platform_stop_ids := []string{}
+	for _, stop := range stops {
+		if stop.LocationType != LocationTypeStop {
+			continue
+		}
+		if _, has_edges := edges_by_stop_id[stop.StopID]; has_edges || has_incoming_edges[stop.StopID] {
+			platform_stop_ids = append(platform_stop_ids, stop.StopID)
+		}
+	}
+	sort.Strings(platform_stop_ids)
+
+	transfers := []PathwayTransfer{}
+	for _, from_stop_id := range platform_stop_ids {
+		fastest_paths := getFastestPathways(edges_by_stop_id, from_stop_id, false)
+		var accessible_paths map[string]pathwayNode
+		for _, to_stop_id := range platform_stop_ids {
+			fastest_path, has_path := fastest_paths[to_stop_id]
+			if to_stop_id == from_stop_id || !has_path {
+				continue
+			}
+			transfers = append(transfers, toPathwayTransfer(from_stop_id, to_stop_id, fastest_paths, stops_by_id, levels_by_id))
+			if fastest_path.IsWheelchairAccessible {
+				continue
+			}
+			if accessible_paths == nil {
+				accessible_paths = getFastestPathways(edges_by_stop_id, from_stop_id, true)
+			}
+			if _, has_accessible_path := accessible_paths[to_stop_id]; has_accessible_path {
+				transfers = append(transfers, toPathwayTransfer(from_stop_id, to_stop_id, accessible_paths, stops_by_id, levels_by_id))
+			}
+		}
+	}
+	return transfers
+}
+
+func (options PathwayOptions) getTraversalTimeInSeconds(pathway Pathway, stops_by_id map[string]Stop, levels_by_id map[string]Level) int {
+	if pathway.TraversalTimeInSeconds > 0 {
+		return pathway.TraversalTimeInSeconds
+	}
+	switch pathway.PathwayMode {
+	case PathwayModeStairs:
+		if pathway.StairCount != 0 {
+			return int(math.Ceil(math.Abs(float64(pathway.StairCount)) * options.StairTimeInSeconds))
+		}
+		if pathway.Length > 0 {
+			/* taking the stairs is roughly half as fast as walking */
+			return int(math.Ceil(pathway.Length / (options.WalkingSpeedInMetersPerSecond / 2)))
+		}
+	case PathwayModeElevator:
+		from_level, has_from_level := levels_by_id[stops_by_id[pathway.FromStopID].LevelID]
+		to_level, has_to_level := levels_by_id[stops_by_id[pathway.ToStopID].LevelID]
+		if has_from_level && has_to_level {
+			return options.ElevatorWaitTimeInSeconds + int(math.Ceil(math.Abs(to_level.LevelIndex-from_level.LevelIndex)))*options.ElevatorTimePerLevelInSeconds
+		}
+		return options.ElevatorWaitTimeInSeconds + options.ElevatorTimePerLevelInSeconds
+	default:
+		if pathway.Length > 0 {
+			return int(math.Ceil(pathway.Length / options.WalkingSpeedInMetersPerSecond))
+		}
+	}
+	return options.DefaultTraversalTimeInSeconds
+}
+
+/* the dijkstra state of a stop - keeping the edge used to get there to rebuild the path */
+type pathwayNode struct {
+	StopID                 string
+	TimeInSeconds          int
+	IsWheelchairAccessible bool
+	PreviousStopID         string
+	PreviousEdge           *pathwayEdge
+}
+
+type pathwayQueue []pathwayNode
+
+func (q pathwayQueue) Len() int {
+	return len(q)
+}
+
+func (q pathwayQueue) Less(i, j int) bool {
+	return q[i].TimeInSeconds < q[j].TimeInSeconds
+}
+
+func (q pathwayQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *pathwayQueue) Push(node any) {
+	*q = append(*q, node.(pathwayNode))
+}
+
+func (q *pathwayQueue) Pop() any {
+	old_queue := *q
+	node := old_queue[len(old_queue)-1]
+	*q = old_queue[:len(old_queue)-1]
+	return node
+}
+
+/** dijkstra from the stop over the pathway graph - optionally only using the wheelchair accessible edges */
+func getFastestPathways(edges_by_stop_id map[string][]pathwayEdge, from_stop_id string, wheelchair_accessible_only bool) map[string]pathwayNode {
+	settled_nodes := map[string]pathwayNode{}
+	queue := &pathwayQueue{{StopID: from_stop_id, TimeInSeconds: 0, IsWheelchairAccessible: true}}
+	for queue.Len() > 0 {
+		node := heap.Pop(queue).(pathwayNode)
+		if _, is_settled := settled_nodes[node.StopID]; is_settled {
+			continue
+		}
+		settled_nodes[node.StopID] = node
+		for index := range edges_by_stop_id[node.StopID] {
+			edge := &edges_by_stop_id[node.StopID][index]
+			if wheelchair_accessible_only && !edge.IsWheelchairAccessible {
+				continue
+			}
+			if _, is_settled := settled_nodes[edge.ToStopID]; is_settled {
+				continue
+			}
+			heap.Push(queue, pathwayNode{
+				StopID:                 edge.ToStopID,
+				TimeInSeconds:          node.TimeInSeconds + edge.TraversalTimeInSeconds,
+				IsWheelchairAccessible: node.IsWheelchairAccessible && edge.IsWheelchairAccessible,
+				PreviousStopID:         node.StopID,
+				PreviousEdge:           edge,
+			})
+		}
+	}
+	return settled_nodes
+}
+
+func toPathwayTransfer(from_stop_id string, to_stop_id string, nodes map[string]pathwayNode, stops_by_id map[string]Stop, levels_by_id map[string]Level) PathwayTransfer {
+	to_node := nodes[to_stop_id]
+	path := []raptor.TransferPathStep[string]{}
+	for node := to_node; node.PreviousEdge != nil; node = nodes[node.PreviousStopID] {
+		/* the edges between platforms and their boarding areas are not actual pathways */
+		if node.PreviousEdge.PathwayID == "" {
+			continue
+		}
+		path = append(path, raptor.TransferPathStep[string]{
+			FromUniqueStopID:       node.PreviousStopID,
+			ToUniqueStopID:         node.StopID,
+			PathwayID:              node.PreviousEdge.PathwayID,
+			PathwayMode:            node.PreviousEdge.PathwayMode,
+			TraversalTimeInSeconds: node.PreviousEdge.TraversalTimeInSeconds,
+			SignpostedAs:           node.PreviousEdge.SignpostedAs,
+			ToLevelName:            levels_by_id[stops_by_id[node.StopID].LevelID].LevelName,
+		})
+	}
+	/* the path was collected from the destination backwards */
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	wheelchair_accessible := raptor.GtfsWheelchairAccessibilityNotAccessible
+	if to_node.IsWheelchairAccessible {
+		wheelchair_accessible = raptor.GtfsWheelchairAccessibilityAccessible
+	}
+	return PathwayTransfer{
+		GtfsTransferStruct: raptor.GtfsTransferStruct[string]{
+			FromUniqueStopID:             from_stop_id,
+			ToUniqueStopID:               to_stop_id,
+			MinimumTransferTimeInSeconds: to_node.TimeInSeconds,
+			WheelchairAccessible:         wheelchair_accessible,
+		},
+		Path: path,
+	}
+}
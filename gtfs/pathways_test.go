@@ -0,0 +1,126 @@
+package gtfs
+
+import (
+	"strings"
+	"testing"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPathwayStops = `stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station,wheelchair_boarding,level_id
+PENN,Penn Station,40.7506,-73.9935,1,,1,
+PENN_MEZZ,Mezzanine,40.7506,-73.9935,3,PENN,1,L0
+PENN_1,Track 1,40.7506,-73.9935,0,PENN,1,L1
+PENN_2,Track 2,40.7506,-73.9935,0,PENN,1,L2
+`
+
+const testPathways = `pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional,length,traversal_time,stair_count,signposted_as,reversed_signposted_as
+STAIRS_1_2,PENN_1,PENN_2,2,1,,,-40,To Track 2,To Track 1
+WALK_1_MEZZ,PENN_1,PENN_MEZZ,1,1,70,,,To Mezzanine,To Track 1
+ELEVATOR_MEZZ_2,PENN_MEZZ,PENN_2,5,1,,,,Elevator to Track 2,Elevator to Mezzanine
+`
+
+const testLevels = `level_id,level_index,level_name
+L0,0,Mezzanine
+L1,-1,Upper Level
+L2,-2,Lower Level
+`
+
+func TestComputePathwayTransfers(t *testing.T) {
+	stops, err := ReadStops(strings.NewReader(testPathwayStops))
+	assert.NoError(t, err)
+	pathways, err := ReadPathways(strings.NewReader(testPathways))
+	assert.NoError(t, err)
+	levels, err := ReadLevels(strings.NewReader(testLevels))
+	assert.NoError(t, err)
+
+	transfers := ComputePathwayTransfers(stops, pathways, levels, PathwayOptions{})
+	assert.Len(t, transfers, 4, "should emit the fastest and the accessible transfer in both directions")
+
+	/* the stairs take 40 * 0.6 seconds */
+	assert.Equal(t, "PENN_1", transfers[0].FromUniqueStopID)
+	assert.Equal(t, "PENN_2", transfers[0].ToUniqueStopID)
+	assert.Equal(t, 24, transfers[0].MinimumTransferTimeInSeconds)
+	assert.Equal(t, raptor.GtfsWheelchairAccessibilityNotAccessible, transfers[0].WheelchairAccessible)
+
+	/* walking the 70 meters takes 50 seconds and the elevator waits 60 seconds and travels 2 levels */
+	assert.Equal(t, 130, transfers[1].MinimumTransferTimeInSeconds)
+	assert.Equal(t, raptor.GtfsWheelchairAccessibilityAccessible, transfers[1].WheelchairAccessible)
+	assert.Equal(t, []raptor.TransferPathStep[string]{
+		{FromUniqueStopID: "PENN_1", ToUniqueStopID: "PENN_MEZZ", PathwayID: "WALK_1_MEZZ", PathwayMode: PathwayModeWalkway, TraversalTimeInSeconds: 50, SignpostedAs: "To Mezzanine", ToLevelName: "Mezzanine"},
+		{FromUniqueStopID: "PENN_MEZZ", ToUniqueStopID: "PENN_2", PathwayID: "ELEVATOR_MEZZ_2", PathwayMode: PathwayModeElevator, TraversalTimeInSeconds: 80, SignpostedAs: "Elevator to Track 2", ToLevelName: "Lower Level"},
+	}, transfers[1].Path)
+
+	assert.Equal(t, "To Track 1", transfers[2].Path[0].SignpostedAs, "should use the reversed signposting")
+}
+
+func TestComputePathwayTransfers_AttachedToJourney(t *testing.T) {
+	stops, _ := ReadStops(strings.NewReader(testPathwayStops))
+	pathways, _ := ReadPathways(strings.NewReader(testPathways))
+	levels, _ := ReadLevels(strings.NewReader(testLevels))
+
+	input := raptor.SimpleRaptorInput[string, Stop, PathwayTransfer, raptor.GtfsStopTimeStruct[string]]{
+		FromStops: []Stop{{StopID: "NEWARK"}},
+		ToStops:   []Stop{{StopID: "JAMAICA"}},
+		Transfers: ComputePathwayTransfers(stops, pathways, levels, PathwayOptions{}),
+		StopTimes: []raptor.GtfsStopTimeStruct[string]{
+			{UniqueStopID: "NEWARK", UniqueTripID: "NJT", UniqueTripServiceID: "NJT", StopSequence: 1, ArrivalTimeInSeconds: 0, DepartureTimeInSeconds: 0},
+			{UniqueStopID: "PENN_1", UniqueTripID: "NJT", UniqueTripServiceID: "NJT", StopSequence: 2, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+			{UniqueStopID: "PENN_2", UniqueTripID: "LIRR", UniqueTripServiceID: "LIRR", StopSequence: 1, ArrivalTimeInSeconds: 1500, DepartureTimeInSeconds: 1500},
+			{UniqueStopID: "JAMAICA", UniqueTripID: "LIRR", UniqueTripServiceID: "LIRR", StopSequence: 2, ArrivalTimeInSeconds: 2700, DepartureTimeInSeconds: 2700},
+		},
+		Mode:             raptor.RaptorModeDepartAt,
+		MaximumTransfers: 4,
+	}
+	journeys := raptor.SimpleRaptor(input)
+	assert.Len(t, journeys, 1)
+	assert.Len(t, journeys[0].Legs, 3)
+	assert.Equal(t, "STAIRS_1_2", journeys[0].Legs[1].Path[0].PathwayID, "should attach the path of the fastest transfer")
+
+	input.AccessibilityProfile = raptor.RaptorAccessibilityProfile{Wheelchair: true, AllowUnknownAccessibility: true}
+	journeys = raptor.SimpleRaptor(input)
+	assert.Len(t, journeys, 1)
+	assert.Len(t, journeys[0].Legs[1].Path, 2, "should attach the path of the accessible transfer")
+	assert.Equal(t, "ELEVATOR_MEZZ_2", journeys[0].Legs[1].Path[1].PathwayID)
+}
+
+func TestPathwayTransfers_AttachedByIndex(t *testing.T) {
+	/* two transfers between the same platforms taking the same time - only the second one is accessible */
+	getTransfer := func(from_stop_id string, to_stop_id string, pathway_id string, wheelchair_accessible raptor.GtfsWheelchairAccessibility) PathwayTransfer {
+		return PathwayTransfer{
+			GtfsTransferStruct: raptor.GtfsTransferStruct[string]{FromUniqueStopID: from_stop_id, ToUniqueStopID: to_stop_id, MinimumTransferTimeInSeconds: 120, WheelchairAccessible: wheelchair_accessible},
+			Path:               []raptor.TransferPathStep[string]{{FromUniqueStopID: from_stop_id, ToUniqueStopID: to_stop_id, PathwayID: pathway_id}},
+		}
+	}
+	input := raptor.SimpleRaptorInput[string, Stop, PathwayTransfer, raptor.GtfsStopTimeStruct[string]]{
+		FromStops: []Stop{{StopID: "NEWARK"}},
+		ToStops:   []Stop{{StopID: "JAMAICA"}},
+		Transfers: []PathwayTransfer{
+			getTransfer("PENN_1", "PENN_2", "STAIRS_1_2", raptor.GtfsWheelchairAccessibilityNotAccessible),
+			getTransfer("PENN_1", "PENN_2", "RAMP_1_2", raptor.GtfsWheelchairAccessibilityAccessible),
+			getTransfer("PENN_2", "PENN_1", "STAIRS_2_1", raptor.GtfsWheelchairAccessibilityNotAccessible),
+			getTransfer("PENN_2", "PENN_1", "RAMP_2_1", raptor.GtfsWheelchairAccessibilityAccessible),
+		},
+		StopTimes: []raptor.GtfsStopTimeStruct[string]{
+			{UniqueStopID: "NEWARK", UniqueTripID: "NJT", UniqueTripServiceID: "NJT", StopSequence: 1, ArrivalTimeInSeconds: 0, DepartureTimeInSeconds: 0},
+			{UniqueStopID: "PENN_1", UniqueTripID: "NJT", UniqueTripServiceID: "NJT", StopSequence: 2, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+			{UniqueStopID: "PENN_2", UniqueTripID: "LIRR", UniqueTripServiceID: "LIRR", StopSequence: 1, ArrivalTimeInSeconds: 1500, DepartureTimeInSeconds: 1500},
+			{UniqueStopID: "JAMAICA", UniqueTripID: "LIRR", UniqueTripServiceID: "LIRR", StopSequence: 2, ArrivalTimeInSeconds: 2700, DepartureTimeInSeconds: 2700},
+		},
+		MaximumTransfers:     4,
+		AccessibilityProfile: raptor.RaptorAccessibilityProfile{Wheelchair: true, AllowUnknownAccessibility: true},
+	}
+	for _, mode := range []raptor.RaptorMode{raptor.RaptorModeDepartAt, raptor.RaptorModeArriveBy} {
+		input.Mode = mode
+		input.TimeInSeconds = 0
+		if mode == raptor.RaptorModeArriveBy {
+			input.TimeInSeconds = 3000
+		}
+		journeys := raptor.SimpleRaptor(input)
+		assert.Len(t, journeys, 1, mode)
+		if len(journeys) == 1 {
+			assert.Equal(t, "RAMP_1_2", journeys[0].Legs[1].Path[0].PathwayID, "%s should attach the path of the walked transfer", mode)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package gtfs
+
+import (
+	"io"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+/** an agency.txt entry */
+type Agency struct {
+	AgencyID       string
+	AgencyName     string
+	AgencyURL      string
+	AgencyTimezone string
+}
+
+/** a routes.txt entry - implements the raptor GtfsRoute interface using the route_id as the unique ID */
+type Route struct {
+	RouteID        string
+	AgencyID       string
+	RouteShortName string
+	RouteLongName  string
+	RouteType      raptor.GtfsRouteType
+	RouteColor     string
+	RouteTextColor string
+}
+
+func (r Route) GetUniqueID() string {
+	return r.RouteID
+}
+
+func (r Route) GetUniqueAgencyID() string {
+	return r.AgencyID
+}
+
+func (r Route) GetRouteType() raptor.GtfsRouteType {
+	return r.RouteType
+}
+
+/** a trips.txt entry - implements the raptor GtfsTrip interface using the trip_id as the unique ID */
+type Trip struct {
+	TripID               string
+	RouteID              string
+	ServiceID            string
+	TripHeadsign         string
+	TripShortName        string
+	DirectionID          int
+	ShapeID              string
+	WheelchairAccessible raptor.GtfsWheelchairAccessibility
+	BikesAllowed         raptor.GtfsBikesAllowed
+}
+
+func (t Trip) GetUniqueID() string {
+	return t.TripID
+}
+
+func (t Trip) GetUniqueRouteID() string {
+	return t.RouteID
+}
+
+func (t Trip) GetWheelchairAccessible() raptor.GtfsWheelchairAccessibility {
+	return t.WheelchairAccessible
+}
+
+func (t Trip) GetBikesAllowed() raptor.GtfsBikesAllowed {
+	return t.BikesAllowed
+}
+
+func ReadAgencies(reader io.Reader) ([]Agency, error) {
+	agencies := []Agency{}
+	err := readCsv(reader, func(row csvRow) error {
+		agencies = append(agencies, Agency{
+			AgencyID:       row.Get("agency_id"),
+			AgencyName:     row.Get("agency_name"),
+			AgencyURL:      row.Get("agency_url"),
+			AgencyTimezone: row.Get("agency_timezone"),
+		})
+		return nil
+	})
+	return agencies, err
+}
+
+func ReadRoutes(reader io.Reader) ([]Route, error) {
+	routes := []Route{}
+	err := readCsv(reader, func(row csvRow) error {
+		route := Route{
+			RouteID:        row.Get("route_id"),
+			AgencyID:       row.Get("agency_id"),
+			RouteShortName: row.Get("route_short_name"),
+			RouteLongName:  row.Get("route_long_name"),
+			RouteColor:     row.Get("route_color"),
+			RouteTextColor: row.Get("route_text_color"),
+		}
+		var err error
+		if route.RouteType, err = row.GetInt("route_type", raptor.GtfsRouteTypeBus); err != nil {
+			return err
+		}
+		routes = append(routes, route)
+		return nil
+	})
+	return routes, err
+}
+
+func ReadTrips(reader io.Reader) ([]Trip, error) {
+	trips := []Trip{}
+	err := readCsv(reader, func(row csvRow) error {
+		trip := Trip{
+			TripID:        row.Get("trip_id"),
+			RouteID:       row.Get("route_id"),
+			ServiceID:     row.Get("service_id"),
+			TripHeadsign:  row.Get("trip_headsign"),
+			TripShortName: row.Get("trip_short_name"),
+			ShapeID:       row.Get("shape_id"),
+		}
+		var err error
+		if trip.DirectionID, err = row.GetInt("direction_id", 0); err != nil {
+			return err
+		}
+		if trip.WheelchairAccessible, err = row.GetInt("wheelchair_accessible", raptor.GtfsWheelchairAccessibilityUnknown); err != nil {
+			return err
+		}
+		if trip.BikesAllowed, err = row.GetInt("bikes_allowed", raptor.GtfsBikesAllowedUnknown); err != nil {
+			return err
+		}
+		trips = append(trips, trip)
+		return nil
+	})
+	return trips, err
+}
@@ -0,0 +1,53 @@
+package gtfs
+
+import (
+	"io"
+	"sort"
+)
+
+/** a shapes.txt entry */
+type ShapePoint struct {
+	ShapeID         string
+	Latitude        float64
+	Longitude       float64
+	Sequence        int
+	DistTraveled    float64
+	HasDistTraveled bool
+}
+
+/** reads the shape points ordered by shape and sequence */
+func ReadShapes(reader io.Reader) ([]ShapePoint, error) {
+	shape_points := []ShapePoint{}
+	err := readCsv(reader, func(row csvRow) error {
+		shape_point := ShapePoint{
+			ShapeID:         row.Get("shape_id"),
+			HasDistTraveled: row.Get("shape_dist_traveled") != "",
+		}
+		var err error
+		if shape_point.Latitude, err = row.GetFloat("shape_pt_lat", 0); err != nil {
+			return err
+		}
+		if shape_point.Longitude, err = row.GetFloat("shape_pt_lon", 0); err != nil {
+			return err
+		}
+		if shape_point.Sequence, err = row.GetInt("shape_pt_sequence", 0); err != nil {
+			return err
+		}
+		if shape_point.DistTraveled, err = row.GetFloat("shape_dist_traveled", 0); err != nil {
+			return err
+		}
+		shape_points = append(shape_points, shape_point)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(shape_points, func(i, j int) bool {
+		if shape_points[i].ShapeID != shape_points[j].ShapeID {
+			return shape_points[i].ShapeID < shape_points[j].ShapeID
+		}
+		return shape_points[i].Sequence < shape_points[j].Sequence
+	})
+	return shape_points, nil
+}
@@ -0,0 +1,165 @@
+package gtfs
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+/**
+ * a stop_times.txt entry - the times are in seconds since the start of the service day (noon minus 12 hours)
+ * this implements the raptor GtfsStopTime interface using the trip_id as the trip service ID - which is only unique for a single service day
+ */
+type StopTime struct {
+	TripID                 string
+	StopID                 string
+	StopSequence           int
+	ArrivalTimeInSeconds   raptor.TimestampInSeconds
+	DepartureTimeInSeconds raptor.TimestampInSeconds
+	StopHeadsign           string
+	PickupType             int
+	DropOffType            int
+	ShapeDistTraveled      float64
+	/* since 0 is a valid distance for the first stop */
+	HasShapeDistTraveled bool
+	/* whether the times were interpolated because the stop is not a timepoint */
+	IsInterpolated bool
+}
+
+func (s StopTime) GetUniqueStopID() string {
+	return s.StopID
+}
+
+func (s StopTime) GetUniqueTripID() string {
+	return s.TripID
+}
+
+func (s StopTime) GetUniqueTripServiceID() string {
+	return s.TripID
+}
+
+func (s StopTime) GetStopSequence() int {
+	return s.StopSequence
+}
+
+func (s StopTime) GetArrivalTimeInSeconds() raptor.TimestampInSeconds {
+	return s.ArrivalTimeInSeconds
+}
+
+func (s StopTime) GetDepartureTimeInSeconds() raptor.TimestampInSeconds {
+	return s.DepartureTimeInSeconds
+}
+
+/** parses a HH:MM:SS GTFS time into seconds - the hours can exceed 24 for trips running past midnight */
+func ParseTime(value string) (raptor.TimestampInSeconds, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	seconds := raptor.TimestampInSeconds(0)
+	for _, part := range parts {
+		parsed_part, err := strconv.Atoi(part)
+		if err != nil || parsed_part < 0 {
+			return 0, fmt.Errorf("invalid time %q", value)
+		}
+		seconds = seconds*60 + raptor.TimestampInSeconds(parsed_part)
+	}
+	return seconds, nil
+}
+
+/** formats seconds since the start of the service day as a HH:MM:SS GTFS time */
+func FormatTime(seconds raptor.TimestampInSeconds) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+}
+
+/**
+ * reads the stop times ordered by trip and stop sequence - the times of stops which are not timepoints (empty times)
+ * are interpolated between the surrounding timepoints based on the number of stops in between
+ */
+func ReadStopTimes(reader io.Reader) ([]StopTime, error) {
+	stop_times := []StopTime{}
+	/* the stops without any times are marked as -1 until they are interpolated */
+	err := readCsv(reader, func(row csvRow) error {
+		stop_time := StopTime{
+			TripID:                 row.Get("trip_id"),
+			StopID:                 row.Get("stop_id"),
+			StopHeadsign:           row.Get("stop_headsign"),
+			ArrivalTimeInSeconds:   -1,
+			DepartureTimeInSeconds: -1,
+		}
+		var err error
+		if stop_time.StopSequence, err = row.GetInt("stop_sequence", 0); err != nil {
+			return err
+		}
+		if stop_time.PickupType, err = row.GetInt("pickup_type", 0); err != nil {
+			return err
+		}
+		if stop_time.DropOffType, err = row.GetInt("drop_off_type", 0); err != nil {
+			return err
+		}
+		if stop_time.ShapeDistTraveled, err = row.GetFloat("shape_dist_traveled", 0); err != nil {
+			return err
+		}
+		stop_time.HasShapeDistTraveled = row.Get("shape_dist_traveled") != ""
+		if arrival_time := row.Get("arrival_time"); arrival_time != "" {
+			if stop_time.ArrivalTimeInSeconds, err = ParseTime(arrival_time); err != nil {
+				return err
+			}
+		}
+		if departure_time := row.Get("departure_time"); departure_time != "" {
+			if stop_time.DepartureTimeInSeconds, err = ParseTime(departure_time); err != nil {
+				return err
+			}
+		}
+		/* only one of the times is required for timepoints */
+		if stop_time.ArrivalTimeInSeconds == -1 {
+			stop_time.ArrivalTimeInSeconds = stop_time.DepartureTimeInSeconds
+		}
+		if stop_time.DepartureTimeInSeconds == -1 {
+			stop_time.DepartureTimeInSeconds = stop_time.ArrivalTimeInSeconds
+		}
+		stop_times = append(stop_times, stop_time)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(stop_times, func(i, j int) bool {
+		if stop_times[i].TripID != stop_times[j].TripID {
+			return stop_times[i].TripID < stop_times[j].TripID
+		}
+		return stop_times[i].StopSequence < stop_times[j].StopSequence
+	})
+	interpolateStopTimes(stop_times)
+	return stop_times, nil
+}
+
+/** interpolates the times of the stops between timepoints - expects the stop times to be ordered by trip and stop sequence */
+func interpolateStopTimes(stop_times []StopTime) {
+	previous_timepoint_index := -1
+	for index := range stop_times {
+		if index > 0 && stop_times[index].TripID != stop_times[index-1].TripID {
+			previous_timepoint_index = -1
+		}
+		if stop_times[index].ArrivalTimeInSeconds == -1 {
+			continue
+		}
+		if previous_timepoint_index != -1 && index-previous_timepoint_index > 1 {
+			from_time := stop_times[previous_timepoint_index].DepartureTimeInSeconds
+			to_time := stop_times[index].ArrivalTimeInSeconds
+			steps := raptor.TimestampInSeconds(index - previous_timepoint_index)
+			for step := previous_timepoint_index + 1; step < index; step++ {
+				interpolated_time := from_time + (to_time-from_time)*raptor.TimestampInSeconds(step-previous_timepoint_index)/steps
+				stop_times[step].ArrivalTimeInSeconds = interpolated_time
+				stop_times[step].DepartureTimeInSeconds = interpolated_time
+				stop_times[step].IsInterpolated = true
+			}
+		}
+		previous_timepoint_index = index
+	}
+}
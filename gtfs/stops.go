@@ -0,0 +1,76 @@
+package gtfs
+
+import (
+	"io"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+type LocationType = int
+
+const (
+	LocationTypeStop         LocationType = 0
+	LocationTypeStation      LocationType = 1
+	LocationTypeEntrance     LocationType = 2
+	LocationTypeGenericNode  LocationType = 3
+	LocationTypeBoardingArea LocationType = 4
+)
+
+/** a stops.txt entry - implements the raptor GtfsStop interface using the stop_id as the unique ID */
+type Stop struct {
+	StopID    string
+	StopCode  string
+	StopName  string
+	Latitude  float64
+	Longitude float64
+	/* whether the stop_lat and stop_lon are set - they are optional for generic nodes and boarding areas */
+	HasCoordinates     bool
+	LocationType       LocationType
+	ParentStation      string
+	WheelchairBoarding raptor.GtfsWheelchairAccessibility
+	LevelID            string
+	PlatformCode       string
+}
+
+func (s Stop) GetUniqueID() string {
+	return s.StopID
+}
+
+func (s Stop) GetWheelchairBoarding() raptor.GtfsWheelchairAccessibility {
+	return s.WheelchairBoarding
+}
+
+func (s Stop) GetCoordinates() (float64, float64, bool) {
+	return s.Latitude, s.Longitude, s.HasCoordinates
+}
+
+func ReadStops(reader io.Reader) ([]Stop, error) {
+	stops := []Stop{}
+	err := readCsv(reader, func(row csvRow) error {
+		stop := Stop{
+			StopID:        row.Get("stop_id"),
+			StopCode:      row.Get("stop_code"),
+			StopName:      row.Get("stop_name"),
+			ParentStation: row.Get("parent_station"),
+			LevelID:       row.Get("level_id"),
+			PlatformCode:  row.Get("platform_code"),
+		}
+		var err error
+		if stop.Latitude, err = row.GetFloat("stop_lat", 0); err != nil {
+			return err
+		}
+		if stop.Longitude, err = row.GetFloat("stop_lon", 0); err != nil {
+			return err
+		}
+		stop.HasCoordinates = row.Get("stop_lat") != "" && row.Get("stop_lon") != ""
+		if stop.LocationType, err = row.GetInt("location_type", LocationTypeStop); err != nil {
+			return err
+		}
+		if stop.WheelchairBoarding, err = row.GetInt("wheelchair_boarding", raptor.GtfsWheelchairAccessibilityUnknown); err != nil {
+			return err
+		}
+		stops = append(stops, stop)
+		return nil
+	})
+	return stops, err
+}
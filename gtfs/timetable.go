@@ -0,0 +1,192 @@
+package gtfs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+/** the date format of the calendars */
+const DateLayout = "20060102"
+
+/** the stop times of the feed for specific dates - with unix timestamps and a trip service ID per trip and date */
+type Timetable struct {
+	StopTimes []raptor.GtfsStopTimeStruct[string]
+	/* the trip service IDs of the frequency based trips without exact times */
+	EstimatedUniqueTripServiceIDs []string
+}
+
+/** gets the timezone of the feed - which is the timezone of the agencies */
+func (feed *Feed) GetLocation() (*time.Location, error) {
+	if len(feed.Agencies) == 0 {
+		return nil, fmt.Errorf("the feed has no agencies")
+	}
+	location, err := time.LoadLocation(feed.Agencies[0].AgencyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agency timezone %q: %w", feed.Agencies[0].AgencyTimezone, err)
+	}
+	return location, nil
+}
+
+/** gets the service IDs which run on the date - using the calendars and their exceptions */
+func (feed *Feed) GetActiveServiceIDs(date time.Time) map[string]bool {
+	formatted_date := date.Format(DateLayout)
+	service_ids := map[string]bool{}
+	for _, calendar := range feed.Calendars {
+		if calendar.Weekdays[date.Weekday()] && calendar.StartDate <= formatted_date && formatted_date <= calendar.EndDate {
+			service_ids[calendar.ServiceID] = true
+		}
+	}
+	for _, calendar_date := range feed.CalendarDates {
+		if calendar_date.Date != formatted_date {
+			continue
+		}
+		switch calendar_date.ExceptionType {
+		case ExceptionTypeAdded:
+			service_ids[calendar_date.ServiceID] = true
+		case ExceptionTypeRemoved:
+			delete(service_ids, calendar_date.ServiceID)
+		}
+	}
+	return service_ids
+}
+
+/** the times of the stop times are relative to noon minus 12 hours - which is not midnight on the days the clocks change */
+func GetServiceDayStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, date.Location()).Add(-12 * time.Hour)
+}
+
+/** the trip service ID of a trip on a service date - since the trip IDs repeat every day the trip runs */
+func GetDatedTripServiceID(trip_id string, date time.Time) string {
+	return trip_id + "@" + date.Format(DateLayout)
+}
+
+/** gets the trip ID of a dated trip service ID - or the ID itself when it is not dated */
+func GetTripIDFromTripServiceID(trip_service_id string) string {
+	if separator_index := strings.LastIndex(trip_service_id, "@"); separator_index != -1 {
+		return trip_service_id[:separator_index]
+	}
+	return trip_service_id
+}
+
+/**
+ * builds the timetable of the trips running on the dates (in the timezone of the feed) ordered by arrival time
+ * the frequency based trips are expanded into their trip instances
+ */
+func (feed *Feed) BuildTimetable(dates []time.Time) (*Timetable, error) {
+	location, err := feed.GetLocation()
+	if err != nil {
+		return nil, err
+	}
+	service_ids_by_trip_id := make(map[string]string, len(feed.Trips))
+	for _, trip := range feed.Trips {
+		service_ids_by_trip_id[trip.TripID] = trip.ServiceID
+	}
+
+	timetable := &Timetable{StopTimes: []raptor.GtfsStopTimeStruct[string]{}, EstimatedUniqueTripServiceIDs: []string{}}
+	for _, date := range dates {
+		date = date.In(location)
+		service_ids := feed.GetActiveServiceIDs(date)
+		service_day_start := GetServiceDayStart(date).Unix()
+
+		/* the times are relative to the service day until the frequencies are expanded */
+		stop_times := []raptor.GtfsStopTimeStruct[string]{}
+		for _, stop_time := range feed.StopTimes {
+			if !service_ids[service_ids_by_trip_id[stop_time.TripID]] {
+				continue
+			}
+			stop_times = append(stop_times, raptor.GtfsStopTimeStruct[string]{
+				UniqueStopID:           stop_time.StopID,
+				UniqueTripID:           stop_time.TripID,
+				UniqueTripServiceID:    GetDatedTripServiceID(stop_time.TripID, date),
+				StopSequence:           stop_time.StopSequence,
+				ArrivalTimeInSeconds:   stop_time.ArrivalTimeInSeconds,
+				DepartureTimeInSeconds: stop_time.DepartureTimeInSeconds,
+			})
+		}
+		if len(feed.Frequencies) > 0 {
+			expanded := raptor.ExpandFrequencies[string](stop_times, feed.Frequencies, func(template_unique_trip_service_id string, start_time raptor.TimestampInSeconds) string {
+				return fmt.Sprintf("%s/%s", template_unique_trip_service_id, FormatTime(start_time))
+			})
+			stop_times = expanded.StopTimes
+			timetable.EstimatedUniqueTripServiceIDs = append(timetable.EstimatedUniqueTripServiceIDs, expanded.EstimatedUniqueTripServiceIDs...)
+		}
+		for _, stop_time := range stop_times {
+			stop_time.ArrivalTimeInSeconds += service_day_start
+			stop_time.DepartureTimeInSeconds += service_day_start
+			timetable.StopTimes = append(timetable.StopTimes, stop_time)
+		}
+	}
+
+	sort.SliceStable(timetable.StopTimes, func(i, j int) bool {
+		return timetable.StopTimes[i].ArrivalTimeInSeconds < timetable.StopTimes[j].ArrivalTimeInSeconds
+	})
+	return timetable, nil
+}
+
+/** the number of trips which run on a service date */
+type ServiceDateTripCount struct {
+	Date      time.Time
+	TripCount int
+}
+
+/** gets the first and last date (in the timezone of the feed) of the calendars and the added calendar exceptions */
+func (feed *Feed) GetServiceDateRange() (time.Time, time.Time, error) {
+	location, err := feed.GetLocation()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	first_date, last_date := "", ""
+	include := func(start_date string, end_date string) {
+		if first_date == "" || start_date < first_date {
+			first_date = start_date
+		}
+		if last_date == "" || end_date > last_date {
+			last_date = end_date
+		}
+	}
+	for _, calendar := range feed.Calendars {
+		include(calendar.StartDate, calendar.EndDate)
+	}
+	for _, calendar_date := range feed.CalendarDates {
+		if calendar_date.ExceptionType == ExceptionTypeAdded {
+			include(calendar_date.Date, calendar_date.Date)
+		}
+	}
+	if first_date == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("the feed has no service dates")
+	}
+	first, err := time.ParseInLocation(DateLayout, first_date, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid service date %q: %w", first_date, err)
+	}
+	last, err := time.ParseInLocation(DateLayout, last_date, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid service date %q: %w", last_date, err)
+	}
+	return first, last, nil
+}
+
+/** counts the trips which run on every date of the service date range */
+func (feed *Feed) GetTripCountsByServiceDate() ([]ServiceDateTripCount, error) {
+	first, last, err := feed.GetServiceDateRange()
+	if err != nil {
+		return nil, err
+	}
+	trip_counts_by_service_id := map[string]int{}
+	for _, trip := range feed.Trips {
+		trip_counts_by_service_id[trip.ServiceID]++
+	}
+	trip_counts := []ServiceDateTripCount{}
+	for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+		trip_count := ServiceDateTripCount{Date: date}
+		for service_id := range feed.GetActiveServiceIDs(date) {
+			trip_count.TripCount += trip_counts_by_service_id[service_id]
+		}
+		trip_counts = append(trip_counts, trip_count)
+	}
+	return trip_counts, nil
+}
@@ -0,0 +1,95 @@
+package gtfs
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTimetable(t *testing.T) {
+	fsys := fstest.MapFS{
+		"agency.txt":         {Data: []byte("agency_id,agency_name,agency_url,agency_timezone\nMTA,MTA,https://mta.info,America/New_York\n")},
+		"stops.txt":          {Data: []byte("stop_id,stop_name,stop_lat,stop_lon\nA,A,40.1,-73.1\nB,B,40.2,-73.2\n")},
+		"routes.txt":         {Data: []byte("route_id,agency_id,route_short_name,route_type\nR,MTA,R,1\n")},
+		"trips.txt":          {Data: []byte("route_id,service_id,trip_id\nR,WKD,T1\nR,WKD,F1\nR,HOL,H1\n")},
+		"calendar.txt":       {Data: []byte("service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\nWKD,1,1,1,1,1,0,0,20250101,20251231\n")},
+		"calendar_dates.txt": {Data: []byte("service_id,date,exception_type\nWKD,20250704,2\nHOL,20250704,1\n")},
+		"frequencies.txt":    {Data: []byte("trip_id,start_time,end_time,headway_secs,exact_times\nF1,06:00:00,07:00:00,1800,0\n")},
+		"stop_times.txt": {Data: []byte("trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"T1,08:00:00,08:00:00,A,1\n" +
+			"T1,24:10:00,24:10:00,B,2\n" +
+			"F1,00:00:00,00:00:00,A,1\n" +
+			"F1,00:05:00,00:05:00,B,2\n" +
+			"H1,10:00:00,10:00:00,A,1\n" +
+			"H1,10:05:00,10:05:00,B,2\n")},
+	}
+	feed, err := LoadFeed(fsys)
+	assert.NoError(t, err)
+	location, err := feed.GetLocation()
+	assert.NoError(t, err)
+
+	thursday := time.Date(2025, 7, 3, 0, 0, 0, 0, location)
+	holiday := time.Date(2025, 7, 4, 0, 0, 0, 0, location)
+	assert.Equal(t, map[string]bool{"WKD": true}, feed.GetActiveServiceIDs(thursday))
+	assert.Equal(t, map[string]bool{"HOL": true}, feed.GetActiveServiceIDs(holiday), "should apply the calendar exceptions")
+
+	timetable, err := feed.BuildTimetable([]time.Time{thursday})
+	assert.NoError(t, err)
+	/* the 2 stop times of T1 and 2 instances of F1 */
+	assert.Len(t, timetable.StopTimes, 6)
+	assert.Equal(t, []string{"F1@20250703/06:00:00", "F1@20250703/06:30:00"}, timetable.EstimatedUniqueTripServiceIDs)
+	assert.Equal(t, time.Date(2025, 7, 3, 6, 0, 0, 0, location).Unix(), timetable.StopTimes[0].DepartureTimeInSeconds)
+	last_stop_time := timetable.StopTimes[len(timetable.StopTimes)-1]
+	assert.Equal(t, "T1@20250703", last_stop_time.UniqueTripServiceID)
+	assert.Equal(t, "T1", GetTripIDFromTripServiceID(last_stop_time.UniqueTripServiceID))
+	assert.Equal(t, time.Date(2025, 7, 4, 0, 10, 0, 0, location).Unix(), last_stop_time.ArrivalTimeInSeconds, "should continue past midnight")
+
+	timetable, err = feed.BuildTimetable([]time.Time{holiday})
+	assert.NoError(t, err)
+	assert.Len(t, timetable.StopTimes, 2)
+	assert.Equal(t, "H1@20250704", timetable.StopTimes[0].UniqueTripServiceID)
+
+	var snapshot bytes.Buffer
+	assert.NoError(t, feed.Encode(&snapshot))
+	decoded_feed, err := DecodeFeed(&snapshot)
+	assert.NoError(t, err)
+	/* the empty files are decoded as nil */
+	assert.Equal(t, feed.Stops, decoded_feed.Stops)
+	assert.Equal(t, feed.StopTimes, decoded_feed.StopTimes)
+	assert.Equal(t, feed.Calendars, decoded_feed.Calendars)
+	assert.Equal(t, feed.CalendarDates, decoded_feed.CalendarDates)
+	assert.Equal(t, feed.Frequencies, decoded_feed.Frequencies)
+	assert.Empty(t, decoded_feed.Transfers)
+}
+
+func TestGetTripCountsByServiceDate(t *testing.T) {
+	feed, err := LoadFeed(fstest.MapFS{
+		"agency.txt":         {Data: []byte("agency_id,agency_name,agency_url,agency_timezone\nMTA,MTA,https://mta.info,America/New_York\n")},
+		"stops.txt":          {Data: []byte("stop_id,stop_name,stop_lat,stop_lon\nA,A,40.1,-73.1\n")},
+		"routes.txt":         {Data: []byte("route_id,agency_id,route_short_name,route_type\nR,MTA,R,1\n")},
+		"trips.txt":          {Data: []byte("route_id,service_id,trip_id\nR,WKD,T1\nR,WKD,T2\nR,HOL,H1\n")},
+		"calendar.txt":       {Data: []byte("service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\nWKD,1,1,1,1,1,0,0,20250703,20250705\n")},
+		"calendar_dates.txt": {Data: []byte("service_id,date,exception_type\nWKD,20250704,2\nHOL,20250704,1\nHOL,20250706,1\n")},
+		"stop_times.txt":     {Data: []byte("trip_id,arrival_time,departure_time,stop_id,stop_sequence\nT1,08:00:00,08:00:00,A,1\n")},
+	})
+	assert.NoError(t, err)
+	first, last, err := feed.GetServiceDateRange()
+	assert.NoError(t, err)
+	assert.Equal(t, "20250703", first.Format(DateLayout))
+	assert.Equal(t, "20250706", last.Format(DateLayout), "should include the added calendar dates")
+
+	trip_counts, err := feed.GetTripCountsByServiceDate()
+	assert.NoError(t, err)
+	counts := []int{}
+	for _, trip_count := range trip_counts {
+		counts = append(counts, trip_count.TripCount)
+	}
+	assert.Equal(t, []int{2, 1, 0, 1}, counts)
+
+	feed.Calendars, feed.CalendarDates = nil, nil
+	_, _, err = feed.GetServiceDateRange()
+	assert.Error(t, err)
+}
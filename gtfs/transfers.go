@@ -0,0 +1,130 @@
+package gtfs
+
+import (
+	"io"
+
+	raptor "github.com/liammartens/go-raptor"
+)
+
+type TransferType = int
+
+const (
+	TransferTypeRecommended   TransferType = 0
+	TransferTypeTimed         TransferType = 1
+	TransferTypeMinimumTime   TransferType = 2
+	TransferTypeNotPossible   TransferType = 3
+	TransferTypeInSeatAllowed TransferType = 4
+	TransferTypeInSeatDenied  TransferType = 5
+)
+
+/** a transfers.txt entry - implements the raptor GtfsTransfer interface */
+type Transfer struct {
+	FromStopID                   string
+	ToStopID                     string
+	FromTripID                   string
+	ToTripID                     string
+	TransferType                 TransferType
+	MinimumTransferTimeInSeconds int
+}
+
+func (t Transfer) GetFromUniqueStopID() string {
+	return t.FromStopID
+}
+
+func (t Transfer) GetToUniqueStopID() string {
+	return t.ToStopID
+}
+
+func (t Transfer) GetMinimumTransferTimeInSeconds() int {
+	return t.MinimumTransferTimeInSeconds
+}
+
+func (t Transfer) GetWheelchairAccessible() raptor.GtfsWheelchairAccessibility {
+	return raptor.GtfsWheelchairAccessibilityUnknown
+}
+
+func ReadTransfers(reader io.Reader) ([]Transfer, error) {
+	transfers := []Transfer{}
+	err := readCsv(reader, func(row csvRow) error {
+		transfer := Transfer{
+			FromStopID: row.Get("from_stop_id"),
+			ToStopID:   row.Get("to_stop_id"),
+			FromTripID: row.Get("from_trip_id"),
+			ToTripID:   row.Get("to_trip_id"),
+		}
+		var err error
+		if transfer.TransferType, err = row.GetInt("transfer_type", TransferTypeRecommended); err != nil {
+			return err
+		}
+		if transfer.MinimumTransferTimeInSeconds, err = row.GetInt("min_transfer_time", 0); err != nil {
+			return err
+		}
+		transfers = append(transfers, transfer)
+		return nil
+	})
+	return transfers, err
+}
+
+/** gets the transfers which can be used as footpaths - the impossible, trip specific and same stop transfers can not be represented as such */
+func (feed *Feed) GetFootpathTransfers() []Transfer {
+	transfers := []Transfer{}
+	for _, transfer := range feed.Transfers {
+		if transfer.TransferType == TransferTypeNotPossible || transfer.FromTripID != "" || transfer.ToTripID != "" || transfer.FromStopID == transfer.ToStopID {
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers
+}
+
+/** a frequencies.txt entry - implements the raptor GtfsFrequency interface */
+type Frequency struct {
+	TripID             string
+	StartTimeInSeconds raptor.TimestampInSeconds
+	EndTimeInSeconds   raptor.TimestampInSeconds
+	HeadwayInSeconds   int
+	ExactTimes         bool
+}
+
+func (f Frequency) GetUniqueTripID() string {
+	return f.TripID
+}
+
+func (f Frequency) GetStartTimeInSeconds() raptor.TimestampInSeconds {
+	return f.StartTimeInSeconds
+}
+
+func (f Frequency) GetEndTimeInSeconds() raptor.TimestampInSeconds {
+	return f.EndTimeInSeconds
+}
+
+func (f Frequency) GetHeadwayInSeconds() int {
+	return f.HeadwayInSeconds
+}
+
+func (f Frequency) GetExactTimes() bool {
+	return f.ExactTimes
+}
+
+func ReadFrequencies(reader io.Reader) ([]Frequency, error) {
+	frequencies := []Frequency{}
+	err := readCsv(reader, func(row csvRow) error {
+		frequency := Frequency{
+			TripID:     row.Get("trip_id"),
+			ExactTimes: row.Get("exact_times") == "1",
+		}
+		var err error
+		if frequency.StartTimeInSeconds, err = ParseTime(row.Get("start_time")); err != nil {
+			return err
+		}
+		if frequency.EndTimeInSeconds, err = ParseTime(row.Get("end_time")); err != nil {
+			return err
+		}
+		if frequency.HeadwayInSeconds, err = row.GetInt("headway_secs", 0); err != nil {
+			return err
+		}
+		frequencies = append(frequencies, frequency)
+		return nil
+	})
+	return frequencies, err
+}
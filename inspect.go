@@ -0,0 +1,197 @@
+package go_raptor
+
+import (
+	"fmt"
+	"sort"
+)
+
+/** a time partition of the stop times (see TimePartitionInterval) */
+type RaptorInputPartition struct {
+	StartTimeInSeconds TimestampInSeconds
+	StopTimeCount      int
+	/* the trip services of which the first stop time arrives in this partition */
+	TripServiceCount int
+}
+
+/** describes what an input contains - useful to check the loaded data before routing on it */
+type RaptorInputReport[ID UniqueGtfsIdLike] struct {
+	/* the stops of the stop times, transfers and stop lookup */
+	StopCount        int
+	TripCount        int
+	TripServiceCount int
+	StopTimeCount    int
+	TransferCount    int
+	/* the range of the arrival times of the stop times */
+	FirstArrivalTimeInSeconds TimestampInSeconds
+	LastArrivalTimeInSeconds  TimestampInSeconds
+	TimePartitionInterval     TimestampInSeconds
+	/* ordered by their start time */
+	Partitions []RaptorInputPartition
+	/* the stops from which no trip departs - ie. because they are only the last stop of trips or have no stop times at all */
+	StopsWithoutDepartures []ID
+	/* the stops without stop times which can not be reached by transferring from any stop with stop times either */
+	IsolatedStops []ID
+	/* problems with the data which will lead to missed or invalid journeys */
+	Warnings []string
+}
+
+/** reports the contents of the prepared input along with validation warnings for the stop times, transfers and lookups */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) Inspect() RaptorInputReport[ID] {
+	input := prepared_input.Input
+	report := RaptorInputReport[ID]{
+		TripServiceCount:       len(prepared_input.StopTimesByUniqueTripServiceId),
+		StopTimeCount:          len(input.StopTimes),
+		TransferCount:          len(input.Transfers),
+		TimePartitionInterval:  prepared_input.TimePartitionInterval,
+		StopsWithoutDepartures: []ID{},
+		IsolatedStops:          []ID{},
+		Warnings:               []string{},
+	}
+
+	/* the partitions are counted from the stop times so they are correct even when the stop times are not sorted */
+	stop_time_counts_by_partition := map[TimestampInSeconds]int{}
+	is_sorted := true
+	for index, stop_time := range input.StopTimes {
+		arrival_time := stop_time.GetArrivalTimeInSeconds()
+		if index == 0 || arrival_time < report.FirstArrivalTimeInSeconds {
+			report.FirstArrivalTimeInSeconds = arrival_time
+		}
+		if index == 0 || arrival_time > report.LastArrivalTimeInSeconds {
+			report.LastArrivalTimeInSeconds = arrival_time
+		}
+		if is_sorted && index > 0 && arrival_time < input.StopTimes[index-1].GetArrivalTimeInSeconds() {
+			is_sorted = false
+			report.Warnings = append(report.Warnings, fmt.Sprintf("the stop times are not sorted by arrival time (first at index %d)", index))
+		}
+		stop_time_counts_by_partition[GetTimePartition(arrival_time, prepared_input.TimePartitionInterval, false)]++
+	}
+
+	/* the trip services are validated in the order of their IDs so the warnings are stable */
+	unique_trip_service_ids := make([]ID, 0, len(prepared_input.StopTimesByUniqueTripServiceId))
+	for unique_trip_service_id := range prepared_input.StopTimesByUniqueTripServiceId {
+		unique_trip_service_ids = append(unique_trip_service_ids, unique_trip_service_id)
+	}
+	sort.Slice(unique_trip_service_ids, func(i, j int) bool { return unique_trip_service_ids[i] < unique_trip_service_ids[j] })
+
+	departing_unique_stop_ids := map[ID]bool{}
+	unique_trip_ids := map[ID]bool{}
+	trip_service_counts_by_partition := map[TimestampInSeconds]int{}
+	for _, unique_trip_service_id := range unique_trip_service_ids {
+		stop_time_indexes := append([]int{}, prepared_input.StopTimesByUniqueTripServiceId[unique_trip_service_id]...)
+		sort.SliceStable(stop_time_indexes, func(i, j int) bool {
+			return input.StopTimes[stop_time_indexes[i]].GetStopSequence() < input.StopTimes[stop_time_indexes[j]].GetStopSequence()
+		})
+		first_stop_time := input.StopTimes[stop_time_indexes[0]]
+		trip_service_counts_by_partition[GetTimePartition(first_stop_time.GetArrivalTimeInSeconds(), prepared_input.TimePartitionInterval, false)]++
+		unique_trip_id := first_stop_time.GetUniqueTripID()
+		if !unique_trip_ids[unique_trip_id] {
+			unique_trip_ids[unique_trip_id] = true
+			report.Warnings = append(report.Warnings, prepared_input.getTripLookupWarnings(unique_trip_id)...)
+		}
+		if len(stop_time_indexes) == 1 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("trip service %v has a single stop time", unique_trip_service_id))
+		}
+		report.Warnings = append(report.Warnings, prepared_input.getTripServiceWarnings(unique_trip_service_id, stop_time_indexes)...)
+		for _, stop_time_index := range stop_time_indexes[:len(stop_time_indexes)-1] {
+			departing_unique_stop_ids[input.StopTimes[stop_time_index].GetUniqueStopID()] = true
+		}
+	}
+	report.TripCount = len(unique_trip_ids)
+
+	for partition := range prepared_input.TimePartitions.Partitions {
+		report.Partitions = append(report.Partitions, RaptorInputPartition{
+			StartTimeInSeconds: partition,
+			StopTimeCount:      stop_time_counts_by_partition[partition],
+			TripServiceCount:   trip_service_counts_by_partition[partition],
+		})
+	}
+	sort.Slice(report.Partitions, func(i, j int) bool {
+		return report.Partitions[i].StartTimeInSeconds < report.Partitions[j].StartTimeInSeconds
+	})
+
+	/* the stops are known from the stop times, the stop lookup and the transfers */
+	unique_stop_ids := map[ID]bool{}
+	for unique_stop_id := range prepared_input.StopTimesByUniqueStopId {
+		unique_stop_ids[unique_stop_id] = true
+	}
+	for unique_stop_id := range input.StopsByUniqueStopId {
+		unique_stop_ids[unique_stop_id] = true
+	}
+	for index, transfer := range input.Transfers {
+		unique_stop_ids[transfer.GetFromUniqueStopID()] = true
+		unique_stop_ids[transfer.GetToUniqueStopID()] = true
+		if transfer.GetMinimumTransferTimeInSeconds() < 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("transfer %d from %v to %v has a negative minimum transfer time", index, transfer.GetFromUniqueStopID(), transfer.GetToUniqueStopID()))
+		}
+	}
+	report.StopCount = len(unique_stop_ids)
+
+	/* the stops with stop times are reached by trips - the other stops can only be reached by (possibly hopping) transfers from them */
+	reachable_unique_stop_ids := map[ID]bool{}
+	queue := []ID{}
+	for unique_stop_id := range prepared_input.StopTimesByUniqueStopId {
+		reachable_unique_stop_ids[unique_stop_id] = true
+		queue = append(queue, unique_stop_id)
+	}
+	for len(queue) > 0 {
+		unique_stop_id := queue[0]
+		queue = queue[1:]
+		for _, transfer_index := range prepared_input.TransfersByUniqueStopId[unique_stop_id] {
+			to_unique_stop_id := input.Transfers[transfer_index].GetToUniqueStopID()
+			if !reachable_unique_stop_ids[to_unique_stop_id] {
+				reachable_unique_stop_ids[to_unique_stop_id] = true
+				queue = append(queue, to_unique_stop_id)
+			}
+		}
+	}
+	for unique_stop_id := range unique_stop_ids {
+		if !departing_unique_stop_ids[unique_stop_id] {
+			report.StopsWithoutDepartures = append(report.StopsWithoutDepartures, unique_stop_id)
+		}
+		if !reachable_unique_stop_ids[unique_stop_id] {
+			report.IsolatedStops = append(report.IsolatedStops, unique_stop_id)
+		}
+	}
+	sort.Slice(report.StopsWithoutDepartures, func(i, j int) bool { return report.StopsWithoutDepartures[i] < report.StopsWithoutDepartures[j] })
+	sort.Slice(report.IsolatedStops, func(i, j int) bool { return report.IsolatedStops[i] < report.IsolatedStops[j] })
+	return report
+}
+
+/** validates the stop times of a trip service - the indexes should be ordered by stop sequence */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) getTripServiceWarnings(unique_trip_service_id ID, stop_time_indexes []int) []string {
+	warnings := []string{}
+	for position, stop_time_index := range stop_time_indexes {
+		stop_time := prepared_input.Input.StopTimes[stop_time_index]
+		if stop_time.GetDepartureTimeInSeconds() < stop_time.GetArrivalTimeInSeconds() {
+			warnings = append(warnings, fmt.Sprintf("trip service %v departs before it arrives at stop sequence %d", unique_trip_service_id, stop_time.GetStopSequence()))
+		}
+		if position == 0 {
+			continue
+		}
+		previous_stop_time := prepared_input.Input.StopTimes[stop_time_indexes[position-1]]
+		if previous_stop_time.GetStopSequence() == stop_time.GetStopSequence() {
+			warnings = append(warnings, fmt.Sprintf("trip service %v has duplicate stop sequence %d", unique_trip_service_id, stop_time.GetStopSequence()))
+		} else if stop_time.GetArrivalTimeInSeconds() < previous_stop_time.GetDepartureTimeInSeconds() {
+			warnings = append(warnings, fmt.Sprintf("trip service %v arrives at stop sequence %d before departing the previous stop", unique_trip_service_id, stop_time.GetStopSequence()))
+		}
+	}
+	return warnings
+}
+
+/** validates that the trip and its route can be looked up - only when the lookups are passed */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) getTripLookupWarnings(unique_trip_id ID) []string {
+	if len(prepared_input.Input.TripsByUniqueTripId) == 0 {
+		return nil
+	}
+	trip, has_trip := prepared_input.Input.TripsByUniqueTripId[unique_trip_id]
+	if !has_trip {
+		return []string{fmt.Sprintf("trip %v is not in the trip lookup", unique_trip_id)}
+	}
+	if len(prepared_input.Input.RoutesByUniqueRouteId) == 0 {
+		return nil
+	}
+	if _, has_route := prepared_input.Input.RoutesByUniqueRouteId[trip.GetUniqueRouteID()]; !has_route {
+		return []string{fmt.Sprintf("route %v of trip %v is not in the route lookup", trip.GetUniqueRouteID(), unique_trip_id)}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package go_raptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreparedRaptorInput_Inspect(t *testing.T) {
+	input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1@1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "B", UniqueTripID: "T1", UniqueTripServiceID: "T1@1", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "A", UniqueTripID: "T2", UniqueTripServiceID: "T2@1", StopSequence: 1, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1150},
+			{UniqueStopID: "B", UniqueTripID: "T2", UniqueTripServiceID: "T2@1", StopSequence: 2, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1@2", StopSequence: 1, ArrivalTimeInSeconds: 87400, DepartureTimeInSeconds: 87400},
+			{UniqueStopID: "B", UniqueTripID: "T1", UniqueTripServiceID: "T1@2", StopSequence: 2, ArrivalTimeInSeconds: 87300, DepartureTimeInSeconds: 87300},
+		},
+		Transfers: []GtfsTransferStruct[string]{
+			{FromUniqueStopID: "B", ToUniqueStopID: "C", MinimumTransferTimeInSeconds: 60},
+			{FromUniqueStopID: "D", ToUniqueStopID: "E", MinimumTransferTimeInSeconds: -60},
+		},
+		TripsByUniqueTripId: map[string]GtfsTrip[string]{
+			"T1": GtfsTripStruct[string]{UniqueID: "T1", UniqueRouteID: "R1"},
+		},
+	}
+	prepared_input := PrepareRaptorInput(input)
+	report := prepared_input.Inspect()
+
+	assert.Equal(t, 5, report.StopCount)
+	assert.Equal(t, 2, report.TripCount)
+	assert.Equal(t, 3, report.TripServiceCount)
+	assert.Equal(t, 6, report.StopTimeCount)
+	assert.Equal(t, 2, report.TransferCount)
+	assert.Equal(t, TimestampInSeconds(1000), report.FirstArrivalTimeInSeconds)
+	assert.Equal(t, TimestampInSeconds(87400), report.LastArrivalTimeInSeconds)
+	assert.Equal(t, []RaptorInputPartition{
+		{StartTimeInSeconds: 0, StopTimeCount: 4, TripServiceCount: 2},
+		{StartTimeInSeconds: 86400, StopTimeCount: 2, TripServiceCount: 1},
+	}, report.Partitions)
+	assert.Equal(t, []string{"B", "C", "D", "E"}, report.StopsWithoutDepartures)
+	assert.Equal(t, []string{"D", "E"}, report.IsolatedStops, "should reach C by transferring")
+	assert.Equal(t, []string{
+		"the stop times are not sorted by arrival time (first at index 5)",
+		"trip service T1@2 arrives at stop sequence 2 before departing the previous stop",
+		"trip T2 is not in the trip lookup",
+		"trip service T2@1 departs before it arrives at stop sequence 1",
+		"transfer 1 from D to E has a negative minimum transfer time",
+	}, report.Warnings)
+}
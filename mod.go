@@ -1,5 +1,10 @@
 package go_raptor
 
+import (
+	"math"
+	"sort"
+)
+
 func PrepareRaptorInput[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
 	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
 ) PreparedRaptorInput[ID, StopType, TransferType, StopTimeType] {
@@ -30,6 +35,23 @@ func PrepareRaptorInput[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType
 		}
 	}
 
+	/** create a map of the transfers walked towards stop IDs for the arrive by searches - transfers without a transfer in the other direction are assumed to be symmetric */
+	arrive_by_transfers_by_unique_stop_id := map[ID][]int{}
+	if input.ArriveByTransfersByUniqueStopId != nil {
+		arrive_by_transfers_by_unique_stop_id = *input.ArriveByTransfersByUniqueStopId
+	} else {
+		has_transfer_between_stops := map[[2]ID]bool{}
+		for _, transfer := range input.Transfers {
+			has_transfer_between_stops[[2]ID{transfer.GetFromUniqueStopID(), transfer.GetToUniqueStopID()}] = true
+		}
+		for index, transfer := range input.Transfers {
+			arrive_by_transfers_by_unique_stop_id[transfer.GetToUniqueStopID()] = append(arrive_by_transfers_by_unique_stop_id[transfer.GetToUniqueStopID()], index)
+			if !has_transfer_between_stops[[2]ID{transfer.GetToUniqueStopID(), transfer.GetFromUniqueStopID()}] {
+				arrive_by_transfers_by_unique_stop_id[transfer.GetFromUniqueStopID()] = append(arrive_by_transfers_by_unique_stop_id[transfer.GetFromUniqueStopID()], index)
+			}
+		}
+	}
+
 	/* get time partition interval */
 	partition_interval := input.TimePartitionInterval
 	if partition_interval == 0 {
@@ -103,14 +125,16 @@ func PrepareRaptorInput[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType
 	}
 
 	return PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]{
-		Input:                          &input,
-		FromStopsByUniqueStopId:        from_stops_by_unique_stop_id,
-		ToStopsByUniqueStopId:          to_stops_by_unique_stop_id,
-		TransfersByUniqueStopId:        transfers_by_unique_stop_id,
-		StopTimesByUniqueStopId:        stop_times_by_unique_stop_id,
-		StopTimesByUniqueTripServiceId: stop_times_by_unique_trip_service_id,
-		TimePartitionInterval:          partition_interval,
-		TimePartitions:                 time_partitions,
+		Input:                           &input,
+		Filters:                         PrepareRaptorFilters(input),
+		FromStopsByUniqueStopId:         from_stops_by_unique_stop_id,
+		ToStopsByUniqueStopId:           to_stops_by_unique_stop_id,
+		TransfersByUniqueStopId:         transfers_by_unique_stop_id,
+		ArriveByTransfersByUniqueStopId: arrive_by_transfers_by_unique_stop_id,
+		StopTimesByUniqueStopId:         stop_times_by_unique_stop_id,
+		StopTimesByUniqueTripServiceId:  stop_times_by_unique_trip_service_id,
+		TimePartitionInterval:           partition_interval,
+		TimePartitions:                  time_partitions,
 	}
 }
 
@@ -125,6 +149,25 @@ func PrepareRaptorInput[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType
 func SimpleRaptorDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
 	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
 ) []Journey[ID] {
+	journeys, _ := simpleRaptorDepartAt(input)
+	return journeys
+}
+
+/**
+ * one-to-all variant of the depart at implementation - the ToStops are ignored and instead of journeys
+ * the earliest arrival segment for every reachable stop is returned (including the from stops themselves without any spans)
+ */
+func SimpleRaptorDepartAtOneToAll[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) map[ID]RoundSegment[ID] {
+	input.ToStops = nil
+	_, earliest_arrival_time_segments_by_unique_stop_id := simpleRaptorDepartAt(input)
+	return earliest_arrival_time_segments_by_unique_stop_id
+}
+
+func simpleRaptorDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) ([]Journey[ID], map[ID]RoundSegment[ID]) {
 	prepared_input := PrepareRaptorInput(input)
 
 	/* below is the start of the raptor based algorithm */
@@ -132,13 +175,16 @@ func SimpleRaptorDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferTy
 	earliest_arrival_time_segments_by_unique_stop_id := map[ID]RoundSegment[ID]{}
 	/* this is the result slice which contains all the potential journeys (meaning segments which reach the end destination) */
 	potential_journeys_found := []Journey[ID]{}
-	potential_journey_fingerprints := map[string]bool{}
+	potential_journey_spans := newJourneySpansSet[ID]()
+	stats_recorder := newRaptorStatsRecorder(input.Context, input.Observer, RaptorEngineRaptor, RaptorModeDepartAt)
+	/* the labels arriving at or after this time can not lead to a journey within the slack of the best journey found so far */
+	pruning_bound := TimestampInSeconds(math.MaxInt64)
 
 	/* we will also initialize the initial segments for the from_stops -> essentially saying we have arrived at said stops at the depart_at time */
 	for _, from_stop := range input.FromStops {
 		earliest_arrival_time_segments_by_unique_stop_id[from_stop.GetUniqueID()] = RoundSegment[ID]{
 			UniqueStopID:         from_stop.GetUniqueID(),
-			ArrivalTimeInSeconds: input.TimeInSeconds,
+			ArrivalTimeInSeconds: input.TimeInSeconds + prepared_input.GetAccessDurationInSeconds(from_stop.GetUniqueID()),
 			/* we arrived here "as-is" so no spans yet */
 			Spans: []RoundSegmentSpan[ID]{},
 		}
@@ -155,12 +201,18 @@ func SimpleRaptorDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferTy
 	}
 
 	/* now we can start the rounds up until N transfers */
-	trips_scanned_from_sequence := map[ID]int{}
 	for range input.MaximumTransfers {
+		stats_recorder.startRound(len(stops_marked_for_round))
 		had_improvements_this_round := false
 		/* this will be the set of next stops to check for the next round */
 		stops_marked_for_next_round := map[ID]RaptorMarkedStop[ID]{}
-		/* in each round we will check all marked stops for the trips we could take - we will do this by going through the stop times */
+		improved_destination_stop_ids := map[ID]bool{}
+
+		/*
+		 * first we collect the trips which can be boarded at the marked stops - this is done before any arrival is improved this round
+		 * so every trip is boarded with the arrivals of the previous round. each trip is then scanned once from the earliest stop it can be boarded at
+		 */
+		boardings_by_unique_trip_service_id := map[ID][]raptorTripBoarding[ID]{}
 		for _, marked_stop := range stops_marked_for_round {
 			/* this should always exist because any marked stop should have been added to the segment list */
 			current_segment_for_stop := earliest_arrival_time_segments_by_unique_stop_id[marked_stop.ID]
@@ -180,91 +232,132 @@ func SimpleRaptorDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferTy
 
 			stop_times_for_marked_stop_it := NewSliceIterator(stop_times_for_marked_stop[prepared_input.TimePartitions.PartitionsByUniqueStopID[marked_stop.ID][current_segment_for_stop_arrival_time_partition]:partition_end_index], false)
 			for stop_times_for_marked_stop_it.HasNext() {
-				stop_time_for_marked_stop := prepared_input.Input.StopTimes[stop_times_for_marked_stop_it.Next()]
-				trip_already_scanned_from_sequence, has_already_scanned_trip_from_sequence := trips_scanned_from_sequence[stop_time_for_marked_stop.GetUniqueTripServiceID()]
-				/* skip scanning if trip was already forward scanned past or from this sequence */
-				if stop_time_for_marked_stop.GetDepartureTimeInSeconds() < current_segment_for_stop.ArrivalTimeInSeconds ||
-					has_already_scanned_trip_from_sequence && stop_time_for_marked_stop.GetStopSequence() >= trip_already_scanned_from_sequence {
-					/* if the departure time of this stop time happens before my earliest arrival time - I won't be able to make it -> skipping */
+				stop_time_index := stop_times_for_marked_stop_it.Next()
+				stop_time_for_marked_stop := prepared_input.Input.StopTimes[stop_time_index]
+				stats_recorder.round.StopTimesScanned++
+				/* the stop times are ordered by arrival so none of the following ones can depart before the pruning bound either */
+				if stop_time_for_marked_stop.GetArrivalTimeInSeconds() >= pruning_bound {
+					break
+				}
+				/* if the departure time of this stop time happens before my earliest arrival time - I won't be able to make it -> skipping */
+				if stop_time_for_marked_stop.GetDepartureTimeInSeconds() < current_segment_for_stop.ArrivalTimeInSeconds {
 					continue
 				}
-
-				// /* mark trip as scanned from sequence */
-				// there's a bug where they may not be scanned because when arriving at a destination stop
-				trips_scanned_from_sequence[stop_time_for_marked_stop.GetUniqueTripServiceID()] = stop_time_for_marked_stop.GetStopSequence()
-
-				/*
-				 * if we CAN make it we will want to look up the stop times after the current one in the trip.
-				 * we're essentially just going down the line and storing each stop time if the arrival time is earlier than the currently stored one
-				 * (meaning I could get to this stop earlier than initially expected)
-				 */
-
-				/* we want to only take the required slice ; ie if we already scanned some stop times after the current sequence we only need to check the missing ones */
-				/* we'll also check the sequence offset because the input may have omitted a number of irrelevant stop times at the start */
-				var stop_times_for_unique_trip_id_after_current_stop_it *SliceIterator[int]
-				stop_times_for_unique_trip_id_it := NewSliceIterator(prepared_input.StopTimesByUniqueTripServiceId[stop_time_for_marked_stop.GetUniqueTripServiceID()], false)
-				trip_stop_times_sequence_offset := prepared_input.Input.StopTimes[stop_times_for_unique_trip_id_it.First()].GetStopSequence()
-				/* we want to subtract the first stop time sequence and add 1 to skip the current one if the current one is the same */
-				stop_times_start_offset := stop_time_for_marked_stop.GetStopSequence() - trip_stop_times_sequence_offset + 1
-				stop_times_end_offset := trip_already_scanned_from_sequence - trip_stop_times_sequence_offset
-				if !has_already_scanned_trip_from_sequence {
-					stop_times_for_unique_trip_id_after_current_stop_it = stop_times_for_unique_trip_id_it.SliceIterator(stop_times_start_offset, stop_times_for_unique_trip_id_it.Length())
-				} else {
-					stop_times_for_unique_trip_id_after_current_stop_it = stop_times_for_unique_trip_id_it.SliceIterator(stop_times_start_offset, stop_times_end_offset)
+				/* skip trips which are banned or can not be boarded at this stop */
+				if !prepared_input.CanBoardStopTime(stop_time_for_marked_stop) {
+					continue
 				}
+				boardings_by_unique_trip_service_id[stop_time_for_marked_stop.GetUniqueTripServiceID()] = append(boardings_by_unique_trip_service_id[stop_time_for_marked_stop.GetUniqueTripServiceID()], raptorTripBoarding[ID]{
+					StopTimeIndex:    stop_time_index,
+					PositionInTrip:   prepared_input.getPositionInTrip(stop_time_for_marked_stop),
+					Segment:          current_segment_for_stop,
+					Source:           marked_stop.Source,
+					PenaltyInSeconds: current_segment_for_stop.PenaltyInSeconds + prepared_input.GetTripPenaltyInSeconds(stop_time_for_marked_stop),
+				})
+			}
+		}
+
+		/*
+		 * the trips are scanned in order of their first departure so the earliest arrivals are usually found first and improved the least often
+		 * - with the ID breaking ties so the results do not depend on the map iteration order
+		 */
+		for _, boardings := range boardings_by_unique_trip_service_id {
+			sort.Slice(boardings, func(i, j int) bool { return boardings[i].PositionInTrip < boardings[j].PositionInTrip })
+		}
+		unique_trip_service_ids := getSortedKeys(boardings_by_unique_trip_service_id)
+		sort.SliceStable(unique_trip_service_ids, func(i, j int) bool {
+			return prepared_input.Input.StopTimes[boardings_by_unique_trip_service_id[unique_trip_service_ids[i]][0].StopTimeIndex].GetDepartureTimeInSeconds() <
+				prepared_input.Input.StopTimes[boardings_by_unique_trip_service_id[unique_trip_service_ids[j]][0].StopTimeIndex].GetDepartureTimeInSeconds()
+		})
+		for _, unique_trip_service_id := range unique_trip_service_ids {
+			boardings := boardings_by_unique_trip_service_id[unique_trip_service_id]
+			stop_times_for_trip := prepared_input.StopTimesByUniqueTripServiceId[unique_trip_service_id]
+			boarding := boardings[0]
+			next_boarding_index := 1
+			stats_recorder.round.TripsScanned++
 
-				/* the stop times are expected to be in order of sequence ascending */
-			following_stop_times_loop:
-				for stop_times_for_unique_trip_id_after_current_stop_it.HasNext() {
-					following_stop_time := prepared_input.Input.StopTimes[stop_times_for_unique_trip_id_after_current_stop_it.Next()]
+			/*
+			 * we're essentially just going down the line and storing each stop time if the arrival time is earlier than the currently stored one
+			 * (meaning I could get to this stop earlier than initially expected). the stop times are expected to be in order of sequence ascending
+			 * and the whole remainder of the trip is scanned - stopping at a destination would hide the improvements further down the line
+			 */
+			for position := boarding.PositionInTrip + 1; position < len(stop_times_for_trip); position++ {
+				following_stop_time := prepared_input.Input.StopTimes[stop_times_for_trip[position]]
+				stats_recorder.round.StopTimesScanned++
+				/* the rest of the trip arrives even later */
+				if following_stop_time.GetArrivalTimeInSeconds() >= pruning_bound {
+					break
+				}
+				/* we can not get off at a banned stop - but the trip itself continues past it */
+				if prepared_input.CanAlightStopTime(following_stop_time) {
+					boarded_stop_time := prepared_input.Input.StopTimes[boarding.StopTimeIndex]
 					existing_segment, has_existing_segment := earliest_arrival_time_segments_by_unique_stop_id[following_stop_time.GetUniqueStopID()]
-					is_improvement_to_existing_arrival_time := !has_existing_segment || existing_segment.ArrivalTimeInSeconds > following_stop_time.GetArrivalTimeInSeconds()
+					is_improvement_to_existing_arrival_time := following_stop_time.GetArrivalTimeInSeconds()+boarding.PenaltyInSeconds < pruning_bound &&
+						(!has_existing_segment || existing_segment.ArrivalTimeInSeconds+existing_segment.PenaltyInSeconds > following_stop_time.GetArrivalTimeInSeconds()+boarding.PenaltyInSeconds)
+					_, is_destination_stop := prepared_input.ToStopsByUniqueStopId[following_stop_time.GetUniqueStopID()]
 
 					/* if this stop was not arrived at yet OR if this arrival is before the recorded arrival */
 					if is_improvement_to_existing_arrival_time {
 						/* mark improvement this round */
 						had_improvements_this_round = true
+						stats_recorder.round.LabelsImproved++
 
-						updated_spans := make([]RoundSegmentSpan[ID], len(current_segment_for_stop.Spans)+1)
+						updated_spans := make([]RoundSegmentSpan[ID], len(boarding.Segment.Spans)+1)
 						/* copy current segment spans + add a new span for how to get to this stop */
-						copy(updated_spans, current_segment_for_stop.Spans)
+						copy(updated_spans, boarding.Segment.Spans)
 						updated_spans[len(updated_spans)-1] = RoundSegmentSpan[ID]{
-							FromUniqueStopID: stop_time_for_marked_stop.GetUniqueStopID(),
+							FromUniqueStopID: boarded_stop_time.GetUniqueStopID(),
 							ToUniqueStopID:   following_stop_time.GetUniqueStopID(),
 							ViaTrip: &ViaTrip[ID]{
 								UniqueTripID:           following_stop_time.GetUniqueTripID(),
 								UniqueTripServiceID:    following_stop_time.GetUniqueTripServiceID(),
-								FromStopSequenceInTrip: stop_time_for_marked_stop.GetStopSequence(),
+								FromStopSequenceInTrip: boarded_stop_time.GetStopSequence(),
 								ToStopSequenceInTrip:   following_stop_time.GetStopSequence(),
 							},
-							DepartureTimeInSecondsFromUniqueStopID: stop_time_for_marked_stop.GetDepartureTimeInSeconds(),
+							DepartureTimeInSecondsFromUniqueStopID: boarded_stop_time.GetDepartureTimeInSeconds(),
 							ArrivalTimeInSecondsToUniqueStopID:     following_stop_time.GetArrivalTimeInSeconds(),
+							TransferIndex:                          -1,
 						}
 						earliest_arrival_time_segments_by_unique_stop_id[following_stop_time.GetUniqueStopID()] = RoundSegment[ID]{
 							UniqueStopID:         following_stop_time.GetUniqueStopID(),
 							ArrivalTimeInSeconds: following_stop_time.GetArrivalTimeInSeconds(),
 							Spans:                updated_spans,
+							PenaltyInSeconds:     boarding.PenaltyInSeconds,
 						}
 						/* update existing segment in place for later */
 						existing_segment = earliest_arrival_time_segments_by_unique_stop_id[following_stop_time.GetUniqueStopID()]
 
-						/* only allow looking for transfers again if transfer hopping is allowed or the currently marked stop was arrived at by a trip not by a transfer */
-						if input.AllowTransferHopping || marked_stop.Source == RaptorMarkedStopSourceArrival {
+						/* we can mark this stop to check in the next round - unless we have arrived at a destination which completes a journey instead */
+						if is_destination_stop {
+							improved_destination_stop_ids[following_stop_time.GetUniqueStopID()] = true
+							if input.PruneByBestArrival {
+								pruning_bound = min(pruning_bound, following_stop_time.GetArrivalTimeInSeconds()+boarding.PenaltyInSeconds+prepared_input.GetEgressDurationInSeconds(following_stop_time.GetUniqueStopID())+input.BestArrivalSlackInSeconds)
+							}
+						} else {
+							stops_marked_for_next_round[following_stop_time.GetUniqueStopID()] = RaptorMarkedStop[ID]{
+								ID:     following_stop_time.GetUniqueStopID(),
+								Source: RaptorMarkedStopSourceArrival,
+							}
+						}
+
+						/* only allow looking for transfers again if transfer hopping is allowed or the boarded stop was arrived at by a trip not by a transfer */
+						if input.AllowTransferHopping || boarding.Source == RaptorMarkedStopSourceArrival {
 							potential_transfers_for_stop := prepared_input.TransfersByUniqueStopId[following_stop_time.GetUniqueStopID()]
 							for _, transfer_stop_index := range potential_transfers_for_stop {
 								transfer_stop := prepared_input.Input.Transfers[transfer_stop_index]
-								/* we don't want to override a direct arrival marked stop */
-								if _, has_already_marked_stop := stops_marked_for_next_round[transfer_stop.GetToUniqueStopID()]; !has_already_marked_stop {
-									stops_marked_for_next_round[transfer_stop.GetToUniqueStopID()] = RaptorMarkedStop[ID]{
-										ID:     transfer_stop.GetToUniqueStopID(),
-										Source: RaptorMarkedStopSourceTransfer,
-									}
+								if !prepared_input.IsTransferAllowed(transfer_stop) {
+									continue
 								}
+								stats_recorder.round.TransfersRelaxed++
 								/* for each transferrable station we'll also add an earliest arrival segment which is the current arrival time + the minimum transfer time (if the arrival is earlier than the previously recorded one) */
 								arrival_time_at_transfer_stop := following_stop_time.GetArrivalTimeInSeconds() + int64(transfer_stop.GetMinimumTransferTimeInSeconds())
 
 								existing_transfer_segment, has_existing_transfer_segment := earliest_arrival_time_segments_by_unique_stop_id[transfer_stop.GetToUniqueStopID()]
-								if !has_existing_transfer_segment || existing_transfer_segment.ArrivalTimeInSeconds > arrival_time_at_transfer_stop {
+								if arrival_time_at_transfer_stop+existing_segment.PenaltyInSeconds >= pruning_bound {
+									continue
+								}
+								if !has_existing_transfer_segment || existing_transfer_segment.ArrivalTimeInSeconds+existing_transfer_segment.PenaltyInSeconds > arrival_time_at_transfer_stop+existing_segment.PenaltyInSeconds {
+									stats_recorder.round.LabelsImproved++
 									/* copy current segment spans from the original arrival station + add a new one for the transfer itself */
 									updated_spans := make([]RoundSegmentSpan[ID], len(existing_segment.Spans)+1)
 									copy(updated_spans, existing_segment.Spans)
@@ -274,55 +367,47 @@ func SimpleRaptorDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferTy
 										ViaTrip:                                nil,
 										DepartureTimeInSecondsFromUniqueStopID: following_stop_time.GetArrivalTimeInSeconds(),
 										ArrivalTimeInSecondsToUniqueStopID:     arrival_time_at_transfer_stop,
+										TransferIndex:                          transfer_stop_index,
 									}
 									earliest_arrival_time_segments_by_unique_stop_id[transfer_stop.GetToUniqueStopID()] = RoundSegment[ID]{
 										UniqueStopID:         transfer_stop.GetToUniqueStopID(),
 										ArrivalTimeInSeconds: arrival_time_at_transfer_stop,
 										Spans:                updated_spans,
+										PenaltyInSeconds:     existing_segment.PenaltyInSeconds,
+									}
+									/* we don't want to override a direct arrival marked stop */
+									if _, has_already_marked_stop := stops_marked_for_next_round[transfer_stop.GetToUniqueStopID()]; !has_already_marked_stop {
+										stops_marked_for_next_round[transfer_stop.GetToUniqueStopID()] = RaptorMarkedStop[ID]{
+											ID:     transfer_stop.GetToUniqueStopID(),
+											Source: RaptorMarkedStopSourceTransfer,
+										}
 									}
 								}
 							}
 						}
 					}
 
-					_, is_destination_stop := prepared_input.ToStopsByUniqueStopId[following_stop_time.GetUniqueStopID()]
-					if !is_destination_stop {
-						/*
-							next we can mark this stop to check in the next round AND add any potential transfers from this stop to mark;
-							but only if we haven't already arrived in which we early break
-						*/
-						stops_marked_for_next_round[following_stop_time.GetUniqueStopID()] = RaptorMarkedStop[ID]{
-							ID:     following_stop_time.GetUniqueStopID(),
-							Source: RaptorMarkedStopSourceArrival,
-						}
-					} else if is_destination_stop {
-						/* lastly we can check if this stop is actually one of our destination stops - in which case the segment is corresponding to a complete journe7 */
-						segment := earliest_arrival_time_segments_by_unique_stop_id[following_stop_time.GetUniqueStopID()]
-						segment_fingerprint := segment.GetFingerPrint()
-						if _, has_same_trip := potential_journey_fingerprints[segment_fingerprint]; !has_same_trip && len(segment.Spans) > 0 && segment.Spans[0].ViaTrip != nil && segment.Spans[len(segment.Spans)-1].ViaTrip != nil {
-							/* if the spans are 0 it means we were already at our stop in the first place */
-							segment_spans := make([]RoundSegmentSpan[ID], len(segment.Spans))
-							copy(segment_spans, segment.Spans)
-							first_segment_span := segment_spans[0]
-							last_segment_span := segment_spans[len(segment_spans)-1]
-							journey := Journey[ID]{
-								FromUniqueStopID:       first_segment_span.FromUniqueStopID,
-								ToUniqueStopID:         last_segment_span.ToUniqueStopID,
-								DepartureTimeInSeconds: first_segment_span.DepartureTimeInSecondsFromUniqueStopID,
-								ArrivalTimeInSeconds:   last_segment_span.ArrivalTimeInSecondsToUniqueStopID,
-								Legs:                   segment_spans,
-							}
-
-							potential_journeys_found = append(potential_journeys_found, journey)
-							potential_journey_fingerprints[segment_fingerprint] = true
+				}
 
-							/* this also means we can stop this loop */
-							break following_stop_times_loop
-						}
+				/* the trip can also be boarded at this stop - which is preferred for the rest of the trip when it was reached with a lower penalty */
+				if next_boarding_index < len(boardings) && boardings[next_boarding_index].PositionInTrip == position {
+					if boardings[next_boarding_index].PenaltyInSeconds < boarding.PenaltyInSeconds {
+						boarding = boardings[next_boarding_index]
 					}
+					next_boarding_index++
 				}
 			}
 		}
+		/* lastly the destinations which were improved this round are complete journeys - as long as they were arrived at by a trip */
+		for _, unique_stop_id := range getSortedKeys(improved_destination_stop_ids) {
+			segment := earliest_arrival_time_segments_by_unique_stop_id[unique_stop_id]
+			if len(segment.Spans) > 0 && segment.Spans[0].ViaTrip != nil && segment.Spans[len(segment.Spans)-1].ViaTrip != nil {
+				if journey := segment.ToJourney(); potential_journey_spans.Add(journey.Legs) {
+					potential_journeys_found = append(potential_journeys_found, journey)
+				}
+			}
+		}
+		stats_recorder.endRound()
 		/* replace stops marked map */
 		stops_marked_for_round = stops_marked_for_next_round
 		/* if no improvements were found this round we can stop */
@@ -331,7 +416,9 @@ func SimpleRaptorDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferTy
 		}
 	}
 
-	return potential_journeys_found
+	journeys := prepared_input.finalizeJourneys(potential_journeys_found, RaptorModeDepartAt)
+	stats_recorder.endQuery(len(journeys))
+	return journeys, earliest_arrival_time_segments_by_unique_stop_id
 }
 
 func SimpleRaptorArriveBy[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
@@ -345,7 +432,10 @@ func SimpleRaptorArriveBy[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferTy
 	latest_arrival_time_segments_by_unique_stop_id := map[ID]RoundSegment[ID]{}
 	/* this is the result slice which contains all the potential journeys (meaning segments which reach the end destination) */
 	potential_journeys_found := []Journey[ID]{}
-	potential_journey_fingerprints := map[string]bool{}
+	potential_journey_spans := newJourneySpansSet[ID]()
+	stats_recorder := newRaptorStatsRecorder(input.Context, input.Observer, RaptorEngineRaptor, RaptorModeArriveBy)
+	/* the labels departing at or before this time can not lead to a journey within the slack of the best journey found so far */
+	pruning_bound := TimestampInSeconds(math.MinInt64)
 
 	/* to start we need to mark which stops we are going to check during the current round - at the start this will only be the destinations stops */
 	/* this will be replaced between rounds because we will be checking the next set of transferred to stops */
@@ -361,20 +451,26 @@ func SimpleRaptorArriveBy[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferTy
 	for _, to_stop := range input.ToStops {
 		latest_arrival_time_segments_by_unique_stop_id[to_stop.GetUniqueID()] = RoundSegment[ID]{
 			UniqueStopID:         to_stop.GetUniqueID(),
-			ArrivalTimeInSeconds: input.TimeInSeconds,
+			ArrivalTimeInSeconds: input.TimeInSeconds - prepared_input.GetEgressDurationInSeconds(to_stop.GetUniqueID()),
 			/* no spans yet since we need to calculate the arrival route */
 			Spans: []RoundSegmentSpan[ID]{},
 		}
 	}
 
 	/* now we can start the rounds up until N transfers */
-	trips_scanned_from_sequence := map[ID]int{}
 	for range input.MaximumTransfers {
+		stats_recorder.startRound(len(stops_marked_for_round))
 		/* keep track of whether any improvements were found this round */
 		had_improvements_this_round := false
 		/* this will be the set of next stops to check for the next round */
 		stops_marked_for_next_round := map[ID]RaptorMarkedStop[ID]{}
-		/* in each round we will check all marked stops for the trips we could take - we will do this by going through the stop times in  reverse */
+		improved_origin_stop_ids := map[ID]bool{}
+
+		/*
+		 * first we collect the trips which can be alighted at the marked stops - this is done before any arrival is improved this round
+		 * so every trip is alighted with the arrivals of the previous round. each trip is then scanned once (in reverse) from the latest stop it can be alighted at
+		 */
+		alightings_by_unique_trip_service_id := map[ID][]raptorTripBoarding[ID]{}
 		for _, marked_stop := range stops_marked_for_round {
 			/* this should always exist because any marked stop should have been added to the segment list */
 			current_segment_for_stop := latest_arrival_time_segments_by_unique_stop_id[marked_stop.ID]
@@ -393,142 +489,181 @@ func SimpleRaptorArriveBy[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferTy
 			}
 			stop_times_for_marked_stop_it := NewSliceIterator(stop_times_for_marked_stop[prepared_input.TimePartitions.PartitionsByUniqueStopID[marked_stop.ID][current_segment_for_stop_arrival_time_partition]:partition_end_index], true)
 			for stop_times_for_marked_stop_it.HasNext() {
-				stop_time_for_marked_stop := prepared_input.Input.StopTimes[stop_times_for_marked_stop_it.Next()]
-				trip_already_scanned_from_sequence, has_already_scanned_trip_from_sequence := trips_scanned_from_sequence[stop_time_for_marked_stop.GetUniqueTripServiceID()]
-				/* we don't want to scan the preceeding stops if they were already scanned before -> unless this stop sequence is after the already scanned sequence in which case we are missing a few */
-				if stop_time_for_marked_stop.GetArrivalTimeInSeconds() > current_segment_for_stop.ArrivalTimeInSeconds ||
-					has_already_scanned_trip_from_sequence && stop_time_for_marked_stop.GetStopSequence() <= trip_already_scanned_from_sequence {
-					/* if the arrival time of this stop time happens after the current segment arrival time then we are too late */
+				stop_time_index := stop_times_for_marked_stop_it.Next()
+				stop_time_for_marked_stop := prepared_input.Input.StopTimes[stop_time_index]
+				stats_recorder.round.StopTimesScanned++
+				/* the stop times are ordered by arrival (in reverse) so none of the following ones can arrive after the pruning bound either */
+				if stop_time_for_marked_stop.GetArrivalTimeInSeconds() <= pruning_bound {
+					break
+				}
+				/* if the arrival time of this stop time happens after the current segment arrival time then we are too late */
+				if stop_time_for_marked_stop.GetArrivalTimeInSeconds() > current_segment_for_stop.ArrivalTimeInSeconds {
 					continue
 				}
-
-				/* mark trip as scanned from sequence */
-				trips_scanned_from_sequence[stop_time_for_marked_stop.GetUniqueTripServiceID()] = stop_time_for_marked_stop.GetStopSequence()
-
-				/*
-				 * if we CAN make it we will want to look up the stop times before the current one in the trip.
-				 * we're essentially just going down the line in reverse and storing each stop time if the arrival time is later than the currently stored one
-				 * (meaning I could get to this stop later than initially expected)
-				 */
-				/* to get these we want to reverse the stop sequence and skip one to exclude my current stop which I already checked */
-				var stop_times_for_unique_trip_id_after_current_stop_it *SliceIterator[int]
-				stop_times_for_unique_trip_id_it := NewSliceIterator(prepared_input.StopTimesByUniqueTripServiceId[stop_time_for_marked_stop.GetUniqueTripServiceID()], true)
-				stop_times_last_sequence := prepared_input.Input.StopTimes[stop_times_for_unique_trip_id_it.First()].GetStopSequence()
-				stop_times_start_offset := stop_times_last_sequence - stop_time_for_marked_stop.GetStopSequence() + 1
-				stop_times_end_offset := stop_times_last_sequence - trip_already_scanned_from_sequence
-				if !has_already_scanned_trip_from_sequence {
-					stop_times_for_unique_trip_id_after_current_stop_it = stop_times_for_unique_trip_id_it.SliceIterator(stop_times_start_offset, stop_times_for_unique_trip_id_it.Length())
-				} else {
-					stop_times_for_unique_trip_id_after_current_stop_it = stop_times_for_unique_trip_id_it.SliceIterator(stop_times_start_offset, stop_times_end_offset)
+				/* skip trips which are banned or can not be alighted at this stop */
+				if !prepared_input.IsTripAllowed(stop_time_for_marked_stop) || !prepared_input.CanAlightStopTime(stop_time_for_marked_stop) {
+					continue
 				}
+				alightings_by_unique_trip_service_id[stop_time_for_marked_stop.GetUniqueTripServiceID()] = append(alightings_by_unique_trip_service_id[stop_time_for_marked_stop.GetUniqueTripServiceID()], raptorTripBoarding[ID]{
+					StopTimeIndex:    stop_time_index,
+					PositionInTrip:   prepared_input.getPositionInTrip(stop_time_for_marked_stop),
+					Segment:          current_segment_for_stop,
+					Source:           marked_stop.Source,
+					PenaltyInSeconds: current_segment_for_stop.PenaltyInSeconds + prepared_input.GetTripPenaltyInSeconds(stop_time_for_marked_stop),
+				})
+			}
+		}
+
+		/*
+		 * the trips are scanned in reverse order of their last arrival so the latest departures are usually found first and improved the least often
+		 * - with the ID breaking ties so the results do not depend on the map iteration order
+		 */
+		for _, alightings := range alightings_by_unique_trip_service_id {
+			sort.Slice(alightings, func(i, j int) bool { return alightings[i].PositionInTrip > alightings[j].PositionInTrip })
+		}
+		unique_trip_service_ids := getSortedKeys(alightings_by_unique_trip_service_id)
+		sort.SliceStable(unique_trip_service_ids, func(i, j int) bool {
+			return prepared_input.Input.StopTimes[alightings_by_unique_trip_service_id[unique_trip_service_ids[i]][0].StopTimeIndex].GetArrivalTimeInSeconds() >
+				prepared_input.Input.StopTimes[alightings_by_unique_trip_service_id[unique_trip_service_ids[j]][0].StopTimeIndex].GetArrivalTimeInSeconds()
+		})
+		for _, unique_trip_service_id := range unique_trip_service_ids {
+			alightings := alightings_by_unique_trip_service_id[unique_trip_service_id]
+			stop_times_for_trip := prepared_input.StopTimesByUniqueTripServiceId[unique_trip_service_id]
+			alighting := alightings[0]
+			next_alighting_index := 1
+			stats_recorder.round.TripsScanned++
 
-				/* the stop times are expected to be in order of sequence descending */
-			preceeding_stop_times_loop:
-				for stop_times_for_unique_trip_id_after_current_stop_it.HasNext() {
-					preceeding_stop_time := prepared_input.Input.StopTimes[stop_times_for_unique_trip_id_after_current_stop_it.Next()]
+			/*
+			 * we're essentially just going down the line in reverse and storing each stop time if the arrival time is later than the currently stored one
+			 * (meaning I could get to this stop later than initially expected). the whole start of the trip is scanned - stopping at an origin would hide
+			 * the improvements further up the line
+			 */
+			for position := alighting.PositionInTrip - 1; position >= 0; position-- {
+				preceeding_stop_time := prepared_input.Input.StopTimes[stop_times_for_trip[position]]
+				stats_recorder.round.StopTimesScanned++
+				/* the start of the trip departs even earlier */
+				if preceeding_stop_time.GetArrivalTimeInSeconds() <= pruning_bound {
+					break
+				}
+				/* we can not board at a banned stop - but the trip itself passes through it */
+				if prepared_input.IsStopAllowed(preceeding_stop_time.GetUniqueStopID()) {
+					alighted_stop_time := prepared_input.Input.StopTimes[alighting.StopTimeIndex]
 					existing_segment, has_existing_segment := latest_arrival_time_segments_by_unique_stop_id[preceeding_stop_time.GetUniqueStopID()]
-					is_improvement_to_existing_arrival_time := !has_existing_segment || preceeding_stop_time.GetArrivalTimeInSeconds() > existing_segment.ArrivalTimeInSeconds
+					is_improvement_to_existing_arrival_time := preceeding_stop_time.GetArrivalTimeInSeconds()-alighting.PenaltyInSeconds > pruning_bound &&
+						(!has_existing_segment || preceeding_stop_time.GetArrivalTimeInSeconds()-alighting.PenaltyInSeconds > existing_segment.ArrivalTimeInSeconds-existing_segment.PenaltyInSeconds)
+					_, is_origin_stop := prepared_input.FromStopsByUniqueStopId[preceeding_stop_time.GetUniqueStopID()]
+
 					/* if this stop was not arrived at yet OR if this arrival is after the recorded arrival */
 					if is_improvement_to_existing_arrival_time {
 						had_improvements_this_round = true
-						/* we'll want to update the segment spans of the current marked stop NOT the preceeding stop since we don't know yet how we can arrive at the preceeding */
-						/* however we do now now how we could arrive at the current marked stop which is through this stop time */
+						stats_recorder.round.LabelsImproved++
+						/* we'll want to update the segment spans of the alighted stop NOT the preceeding stop since we don't know yet how we can arrive at the preceeding */
+						/* however we do now now how we could arrive at the alighted stop which is through this stop time */
 						updated_spans := append([]RoundSegmentSpan[ID]{
 							{
 								FromUniqueStopID: preceeding_stop_time.GetUniqueStopID(),
-								ToUniqueStopID:   stop_time_for_marked_stop.GetUniqueStopID(),
+								ToUniqueStopID:   alighted_stop_time.GetUniqueStopID(),
 								ViaTrip: &ViaTrip[ID]{
 									UniqueTripID:           preceeding_stop_time.GetUniqueTripID(),
 									UniqueTripServiceID:    preceeding_stop_time.GetUniqueTripServiceID(),
 									FromStopSequenceInTrip: preceeding_stop_time.GetStopSequence(),
-									ToStopSequenceInTrip:   stop_time_for_marked_stop.GetStopSequence(),
+									ToStopSequenceInTrip:   alighted_stop_time.GetStopSequence(),
 								},
 								DepartureTimeInSecondsFromUniqueStopID: preceeding_stop_time.GetDepartureTimeInSeconds(),
-								ArrivalTimeInSecondsToUniqueStopID:     stop_time_for_marked_stop.GetArrivalTimeInSeconds(),
+								ArrivalTimeInSecondsToUniqueStopID:     alighted_stop_time.GetArrivalTimeInSeconds(),
+								TransferIndex:                          -1,
 							},
-						}, current_segment_for_stop.Spans...)
+						}, alighting.Segment.Spans...)
 						latest_arrival_time_segments_by_unique_stop_id[preceeding_stop_time.GetUniqueStopID()] = RoundSegment[ID]{
 							UniqueStopID:         preceeding_stop_time.GetUniqueStopID(),
 							ArrivalTimeInSeconds: preceeding_stop_time.GetArrivalTimeInSeconds(),
 							Spans:                updated_spans,
+							PenaltyInSeconds:     alighting.PenaltyInSeconds,
 						}
 						/* update existing segment in place for later */
 						existing_segment = latest_arrival_time_segments_by_unique_stop_id[preceeding_stop_time.GetUniqueStopID()]
 
-						/* only allow looking for transfers again if transfer hopping is allowed or the currently marked stop was arrived at by a trip not by a transfer */
-						if input.AllowTransferHopping || marked_stop.Source == RaptorMarkedStopSourceArrival {
-							potential_transfers_for_stop := prepared_input.TransfersByUniqueStopId[preceeding_stop_time.GetUniqueStopID()]
+						/* we can mark this stop to check in the next round - unless we have arrived at an origin which completes a journey instead */
+						if is_origin_stop {
+							improved_origin_stop_ids[preceeding_stop_time.GetUniqueStopID()] = true
+							if input.PruneByBestArrival {
+								pruning_bound = max(pruning_bound, preceeding_stop_time.GetArrivalTimeInSeconds()-alighting.PenaltyInSeconds-prepared_input.GetAccessDurationInSeconds(preceeding_stop_time.GetUniqueStopID())-input.BestArrivalSlackInSeconds)
+							}
+						} else {
+							stops_marked_for_next_round[preceeding_stop_time.GetUniqueStopID()] = RaptorMarkedStop[ID]{
+								ID:     preceeding_stop_time.GetUniqueStopID(),
+								Source: RaptorMarkedStopSourceArrival,
+							}
+						}
+
+						/* only allow looking for transfers again if transfer hopping is allowed or the alighted stop was arrived at by a trip not by a transfer */
+						if input.AllowTransferHopping || alighting.Source == RaptorMarkedStopSourceArrival {
+							potential_transfers_for_stop := prepared_input.ArriveByTransfersByUniqueStopId[preceeding_stop_time.GetUniqueStopID()]
 							for _, transfer_stop_index := range potential_transfers_for_stop {
 								transfer_stop := prepared_input.Input.Transfers[transfer_stop_index]
-								/* we don't want to override a direct arrival mark */
-								if _, has_already_marked_stop := stops_marked_for_next_round[transfer_stop.GetToUniqueStopID()]; !has_already_marked_stop {
-									stops_marked_for_next_round[transfer_stop.GetToUniqueStopID()] = RaptorMarkedStop[ID]{
-										ID:     transfer_stop.GetToUniqueStopID(),
-										Source: RaptorMarkedStopSourceTransfer,
-									}
+								if !prepared_input.isArriveByTransferAllowed(transfer_stop, preceeding_stop_time.GetUniqueStopID()) {
+									continue
 								}
+								stats_recorder.round.TransfersRelaxed++
+								transfer_from_unique_stop_id := getArriveByTransferFromStop(transfer_stop, preceeding_stop_time.GetUniqueStopID())
 								/* for each transferrable station we'll also add a latest arrival segment which is the current arrival time - the minimum transfer time (if the arrival is later than the previously recorded one) */
 								departure_time_from_transfer_stop := preceeding_stop_time.GetArrivalTimeInSeconds() - int64(transfer_stop.GetMinimumTransferTimeInSeconds())
-								existing_transfer_segment, has_existing_transfer_segment := latest_arrival_time_segments_by_unique_stop_id[transfer_stop.GetToUniqueStopID()]
-								if !has_existing_transfer_segment || departure_time_from_transfer_stop > existing_transfer_segment.ArrivalTimeInSeconds {
+								existing_transfer_segment, has_existing_transfer_segment := latest_arrival_time_segments_by_unique_stop_id[transfer_from_unique_stop_id]
+								if departure_time_from_transfer_stop-existing_segment.PenaltyInSeconds <= pruning_bound {
+									continue
+								}
+								if !has_existing_transfer_segment || departure_time_from_transfer_stop-existing_segment.PenaltyInSeconds > existing_transfer_segment.ArrivalTimeInSeconds-existing_transfer_segment.PenaltyInSeconds {
+									stats_recorder.round.LabelsImproved++
 									/* copy current segment spans from the original arrival station + add a new one for the transfer itself */
 									updated_spans := append([]RoundSegmentSpan[ID]{
 										{
-											FromUniqueStopID:                       transfer_stop.GetToUniqueStopID(),
+											FromUniqueStopID:                       transfer_from_unique_stop_id,
 											ToUniqueStopID:                         preceeding_stop_time.GetUniqueStopID(),
 											ViaTrip:                                nil,
 											DepartureTimeInSecondsFromUniqueStopID: departure_time_from_transfer_stop,
 											ArrivalTimeInSecondsToUniqueStopID:     preceeding_stop_time.GetArrivalTimeInSeconds(),
+											TransferIndex:                          transfer_stop_index,
 										},
 									}, existing_segment.Spans...)
-									latest_arrival_time_segments_by_unique_stop_id[transfer_stop.GetToUniqueStopID()] = RoundSegment[ID]{
-										UniqueStopID:         transfer_stop.GetToUniqueStopID(),
+									latest_arrival_time_segments_by_unique_stop_id[transfer_from_unique_stop_id] = RoundSegment[ID]{
+										UniqueStopID:         transfer_from_unique_stop_id,
 										ArrivalTimeInSeconds: departure_time_from_transfer_stop,
 										Spans:                updated_spans,
+										PenaltyInSeconds:     existing_segment.PenaltyInSeconds,
+									}
+									/* we don't want to override a direct arrival mark */
+									if _, has_already_marked_stop := stops_marked_for_next_round[transfer_from_unique_stop_id]; !has_already_marked_stop {
+										stops_marked_for_next_round[transfer_from_unique_stop_id] = RaptorMarkedStop[ID]{
+											ID:     transfer_from_unique_stop_id,
+											Source: RaptorMarkedStopSourceTransfer,
+										}
 									}
 								}
 							}
 						}
 					}
 
-					_, is_origin_stop := prepared_input.FromStopsByUniqueStopId[preceeding_stop_time.GetUniqueStopID()]
-					if !is_origin_stop {
-						/*
-							next we can mark this stop to check in the next round AND add any potential transfers from this stop to mark;
-							but only if we haven't already arrived in which we early break
-						*/
-						stops_marked_for_next_round[preceeding_stop_time.GetUniqueStopID()] = RaptorMarkedStop[ID]{
-							ID:     preceeding_stop_time.GetUniqueStopID(),
-							Source: RaptorMarkedStopSourceArrival,
-						}
-					} else if is_origin_stop {
-						/* lastly we can check if this stop is actually one of our origin stops - in which case the segment is corresponding to a complete journe7 */
-						segment := latest_arrival_time_segments_by_unique_stop_id[preceeding_stop_time.GetUniqueStopID()]
-						segment_fingerprint := segment.GetFingerPrint()
-						if _, has_same_trip := potential_journey_fingerprints[segment_fingerprint]; !has_same_trip && len(segment.Spans) > 0 && segment.Spans[0].ViaTrip != nil && segment.Spans[len(segment.Spans)-1].ViaTrip != nil {
-							/* if the spans are 0 it means we were already at our stop in the first place */
-							segment_spans := make([]RoundSegmentSpan[ID], len(segment.Spans))
-							copy(segment_spans, segment.Spans)
-							first_segment_span := segment_spans[0]
-							last_segment_span := segment_spans[len(segment_spans)-1]
-							journey := Journey[ID]{
-								FromUniqueStopID:       first_segment_span.FromUniqueStopID,
-								ToUniqueStopID:         last_segment_span.ToUniqueStopID,
-								DepartureTimeInSeconds: first_segment_span.DepartureTimeInSecondsFromUniqueStopID,
-								ArrivalTimeInSeconds:   last_segment_span.ArrivalTimeInSecondsToUniqueStopID,
-								Legs:                   segment_spans,
-							}
-
-							potential_journeys_found = append(potential_journeys_found, journey)
-							potential_journey_fingerprints[segment_fingerprint] = true
+				}
 
-							/* this also means we can stop this loop */
-							break preceeding_stop_times_loop
-						}
+				/* the trip can also be alighted at this stop - which is preferred for the rest of the trip when it was reached with a lower penalty */
+				if next_alighting_index < len(alightings) && alightings[next_alighting_index].PositionInTrip == position {
+					if alightings[next_alighting_index].PenaltyInSeconds < alighting.PenaltyInSeconds {
+						alighting = alightings[next_alighting_index]
 					}
+					next_alighting_index++
+				}
+			}
+		}
+		/* lastly the origins which were improved this round are complete journeys - as long as they were departed from by a trip */
+		for _, unique_stop_id := range getSortedKeys(improved_origin_stop_ids) {
+			segment := latest_arrival_time_segments_by_unique_stop_id[unique_stop_id]
+			if len(segment.Spans) > 0 && segment.Spans[0].ViaTrip != nil && segment.Spans[len(segment.Spans)-1].ViaTrip != nil {
+				if journey := segment.ToJourney(); potential_journey_spans.Add(journey.Legs) {
+					potential_journeys_found = append(potential_journeys_found, journey)
 				}
 			}
 		}
+		stats_recorder.endRound()
 		/* replace stops marked map */
 		stops_marked_for_round = stops_marked_for_next_round
 		/* if no improvements were found this round we can stop */
@@ -537,14 +672,42 @@ func SimpleRaptorArriveBy[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferTy
 		}
 	}
 
-	return potential_journeys_found
+	journeys := prepared_input.finalizeJourneys(potential_journeys_found, RaptorModeArriveBy)
+	stats_recorder.endQuery(len(journeys))
+	return journeys
 }
 
 func SimpleRaptor[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
 	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
 ) []Journey[ID] {
+	if len(input.ViaStops) > 0 {
+		return SimpleRaptorVia(input)
+	}
+	if input.Engine == RaptorEngineCsa {
+		return SimpleCsa(input)
+	}
+	if input.Engine == RaptorEngineTripBased {
+		return SimpleTripBased(input)
+	}
 	if input.Mode == RaptorModeDepartAt {
 		return SimpleRaptorDepartAt(input)
 	}
 	return SimpleRaptorArriveBy(input)
 }
+
+/** finds the position of the stop time in the stop times of its trip service - which are ordered by stop sequence (but may skip sequences) */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) getPositionInTrip(stop_time StopTimeType) int {
+	stop_times_for_trip := prepared_input.StopTimesByUniqueTripServiceId[stop_time.GetUniqueTripServiceID()]
+	return sort.Search(len(stop_times_for_trip), func(i int) bool {
+		return prepared_input.Input.StopTimes[stop_times_for_trip[i]].GetStopSequence() >= stop_time.GetStopSequence()
+	})
+}
+
+func getSortedKeys[ID UniqueGtfsIdLike, V any](values_by_id map[ID]V) []ID {
+	ids := make([]ID, 0, len(values_by_id))
+	for id := range values_by_id {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
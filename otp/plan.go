@@ -0,0 +1,266 @@
+package otp
+
+import (
+	"fmt"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/geo"
+)
+
+/** the OpenTripPlanner REST plan response - the times are in milliseconds since the epoch and the durations in seconds */
+type PlanResponse struct {
+	RequestParameters map[string]string `json:"requestParameters"`
+	Plan              *Plan             `json:"plan,omitempty"`
+	Error             *PlanError        `json:"error,omitempty"`
+}
+
+type Plan struct {
+	Date        int64       `json:"date"`
+	From        Place       `json:"from"`
+	To          Place       `json:"to"`
+	Itineraries []Itinerary `json:"itineraries"`
+}
+
+type PlanError struct {
+	ID      int    `json:"id"`
+	Msg     string `json:"msg"`
+	Message string `json:"message"`
+	NoPath  bool   `json:"noPath"`
+}
+
+type Itinerary struct {
+	Duration     int64   `json:"duration"`
+	StartTime    int64   `json:"startTime"`
+	EndTime      int64   `json:"endTime"`
+	WalkTime     int64   `json:"walkTime"`
+	TransitTime  int64   `json:"transitTime"`
+	WaitingTime  int64   `json:"waitingTime"`
+	WalkDistance float64 `json:"walkDistance"`
+	Transfers    int     `json:"transfers"`
+	Legs         []Leg   `json:"legs"`
+}
+
+type Place struct {
+	Name         string  `json:"name"`
+	StopID       string  `json:"stopId,omitempty"`
+	StopCode     string  `json:"stopCode,omitempty"`
+	PlatformCode string  `json:"platformCode,omitempty"`
+	Lat          float64 `json:"lat"`
+	Lon          float64 `json:"lon"`
+	Arrival      int64   `json:"arrival,omitempty"`
+	Departure    int64   `json:"departure,omitempty"`
+	StopSequence int     `json:"stopSequence,omitempty"`
+	VertexType   string  `json:"vertexType"`
+}
+
+type LegGeometry struct {
+	Points string `json:"points"`
+	Length int    `json:"length"`
+}
+
+type Leg struct {
+	StartTime                int64        `json:"startTime"`
+	EndTime                  int64        `json:"endTime"`
+	Duration                 float64      `json:"duration"`
+	Distance                 float64      `json:"distance"`
+	Mode                     string       `json:"mode"`
+	TransitLeg               bool         `json:"transitLeg"`
+	RealTime                 bool         `json:"realTime"`
+	DepartureDelay           int          `json:"departureDelay"`
+	ArrivalDelay             int          `json:"arrivalDelay"`
+	InterlineWithPreviousLeg bool         `json:"interlineWithPreviousLeg"`
+	RentedBike               bool         `json:"rentedBike"`
+	Route                    string       `json:"route"`
+	RouteID                  string       `json:"routeId,omitempty"`
+	RouteShortName           string       `json:"routeShortName,omitempty"`
+	RouteLongName            string       `json:"routeLongName,omitempty"`
+	RouteColor               string       `json:"routeColor,omitempty"`
+	RouteTextColor           string       `json:"routeTextColor,omitempty"`
+	RouteType                *int         `json:"routeType,omitempty"`
+	TripID                   string       `json:"tripId,omitempty"`
+	TripShortName            string       `json:"tripShortName,omitempty"`
+	Headsign                 string       `json:"headsign,omitempty"`
+	From                     Place        `json:"from"`
+	To                       Place        `json:"to"`
+	IntermediateStops        []Place      `json:"intermediateStops,omitempty"`
+	LegGeometry              *LegGeometry `json:"legGeometry,omitempty"`
+}
+
+type Options[ID raptor.UniqueGtfsIdLike] struct {
+	/* OpenTripPlanner prefixes every ID with the feed ID (ie "1:237") - the IDs are used as-is when this is empty */
+	FeedID string
+	/* optional - used for the leg geometries and distances (which are straight lines between the stops otherwise) */
+	GeometryStore geo.GeometryStore[ID]
+	/* echoed as the request parameters of the response */
+	RequestParameters map[string]string
+}
+
+/** the OpenTripPlanner modes of the transit modes - OTP has no equivalent for the modes which are not listed */
+var otpModesByTransitMode = map[raptor.TransitMode]string{
+	raptor.TransitModeTram:       "TRAM",
+	raptor.TransitModeSubway:     "SUBWAY",
+	raptor.TransitModeRail:       "RAIL",
+	raptor.TransitModeBus:        "BUS",
+	raptor.TransitModeFerry:      "FERRY",
+	raptor.TransitModeCableTram:  "CABLE_CAR",
+	raptor.TransitModeAerialLift: "GONDOLA",
+	raptor.TransitModeFunicular:  "FUNICULAR",
+	raptor.TransitModeTrolleybus: "TROLLEYBUS",
+	raptor.TransitModeMonorail:   "MONORAIL",
+	raptor.TransitModeAir:        "AIRPLANE",
+	raptor.TransitModeWalk:       "WALK",
+}
+
+/**
+ * maps the journeys to an OpenTripPlanner plan response - the journeys should use unix timestamps (in seconds)
+ * as their times. when there are no journeys the response contains the OTP path not found error instead of a plan
+ */
+func NewPlanResponse[ID raptor.UniqueGtfsIdLike](journeys []raptor.Journey[ID], store raptor.MetadataStore[ID], options Options[ID]) PlanResponse {
+	response := PlanResponse{RequestParameters: options.RequestParameters}
+	if response.RequestParameters == nil {
+		response.RequestParameters = map[string]string{}
+	}
+	if len(journeys) == 0 {
+		response.Error = &PlanError{
+			ID:      404,
+			Msg:     "No trip found. There may be no transit service within the maximum specified distance or at the specified time, or your start or end point might not be safely accessible.",
+			Message: "PATH_NOT_FOUND",
+			NoPath:  true,
+		}
+		return response
+	}
+
+	itineraries := make([]Itinerary, len(journeys))
+	for index, journey := range journeys {
+		itineraries[index] = newItinerary(journey, raptor.EnrichJourney(journey, store), options)
+	}
+	response.Plan = &Plan{
+		Date:        toMilliseconds(journeys[0].DepartureTimeInSeconds),
+		From:        itineraries[0].Legs[0].From,
+		To:          itineraries[0].Legs[len(itineraries[0].Legs)-1].To,
+		Itineraries: itineraries,
+	}
+	response.Plan.From.Departure = 0
+	response.Plan.To.Arrival = 0
+	return response
+}
+
+func newItinerary[ID raptor.UniqueGtfsIdLike](journey raptor.Journey[ID], enriched_journey raptor.EnrichedJourney[ID], options Options[ID]) Itinerary {
+	itinerary := Itinerary{
+		Duration:  int64(journey.ArrivalTimeInSeconds - journey.DepartureTimeInSeconds),
+		StartTime: toMilliseconds(journey.DepartureTimeInSeconds),
+		EndTime:   toMilliseconds(journey.ArrivalTimeInSeconds),
+		WalkTime:  int64(journey.AccessDurationInSeconds + journey.EgressDurationInSeconds),
+		Legs:      make([]Leg, len(journey.Legs)),
+	}
+	transit_legs := 0
+	for index, leg := range journey.Legs {
+		otp_leg := newLeg(leg, enriched_journey.Legs[index], options)
+		duration := int64(leg.ArrivalTimeInSecondsToUniqueStopID - leg.DepartureTimeInSecondsFromUniqueStopID)
+		if otp_leg.TransitLeg {
+			transit_legs++
+			itinerary.TransitTime += duration
+		} else {
+			itinerary.WalkTime += duration
+			itinerary.WalkDistance += otp_leg.Distance
+		}
+		itinerary.Legs[index] = otp_leg
+	}
+	itinerary.WaitingTime = itinerary.Duration - itinerary.WalkTime - itinerary.TransitTime
+	if transit_legs > 0 {
+		itinerary.Transfers = transit_legs - 1
+	}
+	return itinerary
+}
+
+func newLeg[ID raptor.UniqueGtfsIdLike](leg raptor.RoundSegmentSpan[ID], enriched_leg raptor.EnrichedLeg[ID], options Options[ID]) Leg {
+	otp_leg := Leg{
+		StartTime:  toMilliseconds(leg.DepartureTimeInSecondsFromUniqueStopID),
+		EndTime:    toMilliseconds(leg.ArrivalTimeInSecondsToUniqueStopID),
+		Duration:   float64(leg.ArrivalTimeInSecondsToUniqueStopID - leg.DepartureTimeInSecondsFromUniqueStopID),
+		Mode:       getMode(leg),
+		TransitLeg: leg.ViaTrip != nil,
+		From:       newPlace(enriched_leg.FromStop, options),
+		To:         newPlace(enriched_leg.ToStop, options),
+	}
+	otp_leg.From.Departure = otp_leg.StartTime
+	otp_leg.To.Arrival = otp_leg.EndTime
+
+	if enriched_leg.Trip != nil {
+		otp_leg.From.StopSequence = leg.ViaTrip.FromStopSequenceInTrip
+		otp_leg.To.StopSequence = leg.ViaTrip.ToStopSequenceInTrip
+		otp_leg.TripID = getID(enriched_leg.Trip.UniqueTripID, options)
+		otp_leg.TripShortName = enriched_leg.Trip.ShortName
+		otp_leg.Headsign = enriched_leg.Trip.Headsign
+		if route := enriched_leg.Trip.Route; route != nil {
+			otp_leg.RouteID = getID(route.UniqueRouteID, options)
+			otp_leg.RouteShortName = route.ShortName
+			otp_leg.RouteLongName = route.LongName
+			otp_leg.RouteColor = route.Color
+			otp_leg.RouteTextColor = route.TextColor
+			route_type := route.RouteType
+			otp_leg.RouteType = &route_type
+			otp_leg.Route = route.ShortName
+			if otp_leg.Route == "" {
+				otp_leg.Route = route.LongName
+			}
+		}
+		for _, stop_call := range enriched_leg.Trip.IntermediateStops {
+			place := newPlace(stop_call.Stop, options)
+			place.Arrival = toMilliseconds(stop_call.ArrivalTimeInSeconds)
+			place.Departure = toMilliseconds(stop_call.DepartureTimeInSeconds)
+			place.StopSequence = stop_call.StopSequence
+			otp_leg.IntermediateStops = append(otp_leg.IntermediateStops, place)
+		}
+	}
+
+	coordinates := []geo.Coordinate{
+		{Latitude: otp_leg.From.Lat, Longitude: otp_leg.From.Lon},
+		{Latitude: otp_leg.To.Lat, Longitude: otp_leg.To.Lon},
+	}
+	if options.GeometryStore != nil {
+		if leg_coordinates := geo.GetLegCoordinates(leg, options.GeometryStore); len(leg_coordinates) > 0 {
+			coordinates = leg_coordinates
+		}
+	}
+	for index := 1; index < len(coordinates); index++ {
+		otp_leg.Distance += raptor.GetHaversineDistanceInMeters(coordinates[index-1].Latitude, coordinates[index-1].Longitude, coordinates[index].Latitude, coordinates[index].Longitude)
+	}
+	otp_leg.LegGeometry = &LegGeometry{Points: geo.EncodePolyline(coordinates, geo.PolylinePrecision5), Length: len(coordinates)}
+	return otp_leg
+}
+
+func newPlace[ID raptor.UniqueGtfsIdLike](stop raptor.EnrichedStop[ID], options Options[ID]) Place {
+	return Place{
+		Name:         stop.Name,
+		StopID:       getID(stop.UniqueStopID, options),
+		PlatformCode: stop.PlatformCode,
+		Lat:          stop.Latitude,
+		Lon:          stop.Longitude,
+		VertexType:   "TRANSIT",
+	}
+}
+
+func getMode[ID raptor.UniqueGtfsIdLike](leg raptor.RoundSegmentSpan[ID]) string {
+	if leg.ViaTrip == nil && leg.WithBike {
+		return "BICYCLE"
+	}
+	if mode, has_mode := otpModesByTransitMode[leg.Mode]; has_mode {
+		return mode
+	}
+	if leg.ViaTrip == nil {
+		return "WALK"
+	}
+	return "TRANSIT"
+}
+
+func getID[ID raptor.UniqueGtfsIdLike](id ID, options Options[ID]) string {
+	if options.FeedID == "" {
+		return fmt.Sprint(id)
+	}
+	return fmt.Sprintf("%s:%v", options.FeedID, id)
+}
+
+func toMilliseconds(seconds raptor.TimestampInSeconds) int64 {
+	return int64(seconds) * 1000
+}
@@ -0,0 +1,110 @@
+package otp
+
+import (
+	"encoding/json"
+	"testing"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMetadataStore struct{}
+
+func (testMetadataStore) GetRouteMetadata(route_id string) (raptor.RouteMetadata, bool) {
+	return raptor.RouteMetadata{ShortName: route_id, LongName: "Route " + route_id, Color: "FCCC0A", RouteType: raptor.GtfsRouteTypeSubway}, true
+}
+
+func (testMetadataStore) GetTripMetadata(trip_id string) (raptor.TripMetadata[string], bool) {
+	return raptor.TripMetadata[string]{UniqueRouteID: trip_id[:1], Headsign: "Headsign " + trip_id}, true
+}
+
+func (testMetadataStore) GetStopMetadata(stop_id string) (raptor.StopMetadata, bool) {
+	coordinates := map[string][2]float64{"A": {40.70, -73.90}, "B": {40.71, -73.90}, "C": {40.711, -73.90}, "D": {40.72, -73.90}, "X": {40.705, -73.90}}
+	return raptor.StopMetadata{Name: "Stop " + stop_id, Latitude: coordinates[stop_id][0], Longitude: coordinates[stop_id][1]}, true
+}
+
+func (testMetadataStore) GetTripServiceStopCalls(trip_service_id string) []raptor.StopCall[string] {
+	return []raptor.StopCall[string]{}
+}
+
+func TestNewPlanResponse(t *testing.T) {
+	journey := raptor.Journey[string]{
+		FromUniqueStopID:       "A",
+		ToUniqueStopID:         "D",
+		DepartureTimeInSeconds: 100,
+		ArrivalTimeInSeconds:   900,
+		Legs: []raptor.RoundSegmentSpan[string]{
+			{
+				FromUniqueStopID:                       "A",
+				ToUniqueStopID:                         "B",
+				DepartureTimeInSecondsFromUniqueStopID: 100,
+				ArrivalTimeInSecondsToUniqueStopID:     400,
+				Mode:                                   raptor.TransitModeSubway,
+				ViaTrip: &raptor.ViaTrip[string]{
+					UniqueTripID:           "N1",
+					UniqueTripServiceID:    "N1",
+					FromStopSequenceInTrip: 1,
+					ToStopSequenceInTrip:   3,
+					IntermediateStops:      []raptor.StopCall[string]{{UniqueStopID: "X", StopSequence: 2, ArrivalTimeInSeconds: 250, DepartureTimeInSeconds: 260}},
+				},
+			},
+			{
+				FromUniqueStopID:                       "B",
+				ToUniqueStopID:                         "C",
+				DepartureTimeInSecondsFromUniqueStopID: 400,
+				ArrivalTimeInSecondsToUniqueStopID:     460,
+				Mode:                                   raptor.TransitModeWalk,
+			},
+			{
+				FromUniqueStopID:                       "C",
+				ToUniqueStopID:                         "D",
+				DepartureTimeInSecondsFromUniqueStopID: 600,
+				ArrivalTimeInSecondsToUniqueStopID:     900,
+				Mode:                                   raptor.TransitModeSubway,
+				ViaTrip:                                &raptor.ViaTrip[string]{UniqueTripID: "Q1", UniqueTripServiceID: "Q1", FromStopSequenceInTrip: 5, ToStopSequenceInTrip: 6},
+			},
+		},
+	}
+	response := NewPlanResponse([]raptor.Journey[string]{journey}, testMetadataStore{}, Options[string]{FeedID: "MTA"})
+	assert.Nil(t, response.Error)
+	assert.Len(t, response.Plan.Itineraries, 1)
+	assert.Equal(t, "MTA:A", response.Plan.From.StopID)
+	assert.Equal(t, "MTA:D", response.Plan.To.StopID)
+
+	itinerary := response.Plan.Itineraries[0]
+	assert.Equal(t, int64(800), itinerary.Duration)
+	assert.Equal(t, int64(100000), itinerary.StartTime)
+	assert.Equal(t, int64(900000), itinerary.EndTime)
+	assert.Equal(t, int64(60), itinerary.WalkTime)
+	assert.Equal(t, int64(600), itinerary.TransitTime)
+	assert.Equal(t, int64(140), itinerary.WaitingTime)
+	assert.Equal(t, 1, itinerary.Transfers)
+	assert.InDelta(t, 111, itinerary.WalkDistance, 1)
+
+	assert.Equal(t, []string{"SUBWAY", "WALK", "SUBWAY"}, []string{itinerary.Legs[0].Mode, itinerary.Legs[1].Mode, itinerary.Legs[2].Mode})
+	transit_leg := itinerary.Legs[0]
+	assert.True(t, transit_leg.TransitLeg)
+	assert.Equal(t, "N", transit_leg.Route)
+	assert.Equal(t, "MTA:N", transit_leg.RouteID)
+	assert.Equal(t, "MTA:N1", transit_leg.TripID)
+	assert.Equal(t, "Headsign N1", transit_leg.Headsign)
+	assert.Equal(t, raptor.GtfsRouteTypeSubway, *transit_leg.RouteType)
+	assert.Equal(t, int64(100000), transit_leg.From.Departure)
+	assert.Equal(t, int64(400000), transit_leg.To.Arrival)
+	assert.Equal(t, []Place{{Name: "Stop X", StopID: "MTA:X", Lat: 40.705, Lon: -73.90, Arrival: 250000, Departure: 260000, StopSequence: 2, VertexType: "TRANSIT"}}, transit_leg.IntermediateStops)
+	assert.Equal(t, 2, transit_leg.LegGeometry.Length)
+	assert.False(t, itinerary.Legs[1].TransitLeg)
+	assert.Empty(t, itinerary.Legs[1].TripID)
+
+	encoded, err := json.Marshal(response)
+	assert.NoError(t, err)
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	legs := decoded["plan"].(map[string]any)["itineraries"].([]any)[0].(map[string]any)["legs"].([]any)
+	assert.Equal(t, float64(100000), legs[0].(map[string]any)["startTime"])
+	assert.NotContains(t, legs[1].(map[string]any), "routeType")
+
+	response = NewPlanResponse([]raptor.Journey[string]{}, testMetadataStore{}, Options[string]{})
+	assert.Nil(t, response.Plan)
+	assert.Equal(t, "PATH_NOT_FOUND", response.Error.Message)
+}
@@ -0,0 +1,172 @@
+package go_raptor
+
+import (
+	"cmp"
+	"slices"
+)
+
+/**
+ * the post-processing of the journeys found by any of the engines - first the transfer variants and dominated journeys are removed
+ * after which the journeys are sorted and limited to the maximum number of results
+ */
+func RankJourneys[ID UniqueGtfsIdLike](journeys []Journey[ID], ranking RaptorJourneyRanking) []Journey[ID] {
+	if ranking.RemoveTransferVariants {
+		journeys = removeTransferVariantJourneys(journeys)
+	}
+	if ranking.RemoveDominated {
+		journeys = removeDominatedJourneys(journeys)
+	}
+	if ranking.SortBy != "" {
+		slices.SortStableFunc(journeys, func(a Journey[ID], b Journey[ID]) int {
+			return compareJourneys(a, b, ranking.SortBy)
+		})
+	}
+	if ranking.MaxResults > 0 && len(journeys) > ranking.MaxResults {
+		journeys = journeys[:ranking.MaxResults]
+	}
+	return journeys
+}
+
+func (j Journey[ID]) GetDurationInSeconds() TimestampInSeconds {
+	return j.ArrivalTimeInSeconds - j.DepartureTimeInSeconds
+}
+
+/** the number of times we change between trips - walking legs are not counted */
+func (j Journey[ID]) GetTransferCount() int {
+	transit_legs := 0
+	for _, leg := range j.Legs {
+		if leg.ViaTrip != nil {
+			transit_legs++
+		}
+	}
+	return max(transit_legs-1, 0)
+}
+
+/** the time spent on the walking legs including the access and egress durations */
+func (j Journey[ID]) GetWalkingDurationInSeconds() TimestampInSeconds {
+	walking_duration := j.AccessDurationInSeconds + j.EgressDurationInSeconds
+	for _, leg := range j.Legs {
+		if leg.ViaTrip == nil {
+			walking_duration += leg.ArrivalTimeInSecondsToUniqueStopID - leg.DepartureTimeInSecondsFromUniqueStopID
+		}
+	}
+	return walking_duration
+}
+
+/** compares the journeys by the sort order first - the journeys which are equal in it are ordered by arrival, departure, transfers and walking */
+func compareJourneys[ID UniqueGtfsIdLike](a Journey[ID], b Journey[ID], sort_by RaptorJourneySortOrder) int {
+	var by_sort_order int
+	switch sort_by {
+	case RaptorJourneySortByArrival:
+		by_sort_order = cmp.Compare(a.ArrivalTimeInSeconds, b.ArrivalTimeInSeconds)
+	case RaptorJourneySortByDeparture:
+		by_sort_order = cmp.Compare(b.DepartureTimeInSeconds, a.DepartureTimeInSeconds)
+	case RaptorJourneySortByDuration:
+		by_sort_order = cmp.Compare(a.GetDurationInSeconds(), b.GetDurationInSeconds())
+	case RaptorJourneySortByTransfers:
+		by_sort_order = cmp.Compare(a.GetTransferCount(), b.GetTransferCount())
+	case RaptorJourneySortByWalking:
+		by_sort_order = cmp.Compare(a.GetWalkingDurationInSeconds(), b.GetWalkingDurationInSeconds())
+	}
+	return cmp.Or(
+		by_sort_order,
+		cmp.Compare(a.ArrivalTimeInSeconds, b.ArrivalTimeInSeconds),
+		cmp.Compare(b.DepartureTimeInSeconds, a.DepartureTimeInSeconds),
+		cmp.Compare(a.GetTransferCount(), b.GetTransferCount()),
+		cmp.Compare(a.GetWalkingDurationInSeconds(), b.GetWalkingDurationInSeconds()),
+	)
+}
+
+/** whether the journey is at least as good as the other journey in every criterion and better in at least one of them */
+func isDominatingJourney[ID UniqueGtfsIdLike](journey Journey[ID], other Journey[ID]) bool {
+	if journey.ArrivalTimeInSeconds > other.ArrivalTimeInSeconds ||
+		journey.DepartureTimeInSeconds < other.DepartureTimeInSeconds ||
+		journey.GetTransferCount() > other.GetTransferCount() ||
+		journey.GetWalkingDurationInSeconds() > other.GetWalkingDurationInSeconds() ||
+		journey.PenaltyInSeconds > other.PenaltyInSeconds {
+		return false
+	}
+	return journey.ArrivalTimeInSeconds < other.ArrivalTimeInSeconds ||
+		journey.DepartureTimeInSeconds > other.DepartureTimeInSeconds ||
+		journey.GetTransferCount() < other.GetTransferCount() ||
+		journey.GetWalkingDurationInSeconds() < other.GetWalkingDurationInSeconds() ||
+		journey.PenaltyInSeconds < other.PenaltyInSeconds
+}
+
+func removeDominatedJourneys[ID UniqueGtfsIdLike](journeys []Journey[ID]) []Journey[ID] {
+	non_dominated_journeys := []Journey[ID]{}
+	for index, journey := range journeys {
+		is_dominated := false
+		for other_index, other := range journeys {
+			if other_index != index && isDominatingJourney(other, journey) {
+				is_dominated = true
+				break
+			}
+		}
+		if !is_dominated {
+			non_dominated_journeys = append(non_dominated_journeys, journey)
+		}
+	}
+	return non_dominated_journeys
+}
+
+/** a hash of the trips of the journey and where it starts and ends - ignoring the stops at which it transfers between the trips */
+func getTripsFingerPrint[ID UniqueGtfsIdLike](journey Journey[ID]) uint64 {
+	fingerprint := addIdToFingerPrint(fingerPrintOffsetBasis, journey.FromUniqueStopID)
+	fingerprint = addIdToFingerPrint(fingerprint, journey.ToUniqueStopID)
+	for _, leg := range journey.Legs {
+		if leg.ViaTrip != nil {
+			fingerprint = addIdToFingerPrint(fingerprint, leg.ViaTrip.UniqueTripServiceID)
+		}
+	}
+	return fingerprint
+}
+
+/** whether the journeys ride the same trips between the same origin and destination - which is what the trips fingerprint is a hash of */
+func hasSameTrips[ID UniqueGtfsIdLike](journey Journey[ID], other_journey Journey[ID]) bool {
+	if journey.FromUniqueStopID != other_journey.FromUniqueStopID || journey.ToUniqueStopID != other_journey.ToUniqueStopID {
+		return false
+	}
+	trip_service_ids := []ID{}
+	for _, leg := range journey.Legs {
+		if leg.ViaTrip != nil {
+			trip_service_ids = append(trip_service_ids, leg.ViaTrip.UniqueTripServiceID)
+		}
+	}
+	other_trip_service_ids := []ID{}
+	for _, leg := range other_journey.Legs {
+		if leg.ViaTrip != nil {
+			other_trip_service_ids = append(other_trip_service_ids, leg.ViaTrip.UniqueTripServiceID)
+		}
+	}
+	return slices.Equal(trip_service_ids, other_trip_service_ids)
+}
+
+/** keeps the journey walking the least of the journeys riding the same trips - in the position of the first of them */
+func removeTransferVariantJourneys[ID UniqueGtfsIdLike](journeys []Journey[ID]) []Journey[ID] {
+	unique_journeys := []Journey[ID]{}
+	/* the journeys with the same fingerprint are compared so a collision does not merge different journeys */
+	indexes_by_trips_fingerprint := map[uint64][]int{}
+	for _, journey := range journeys {
+		trips_fingerprint := getTripsFingerPrint(journey)
+		index, has_variant := -1, false
+		for _, variant_index := range indexes_by_trips_fingerprint[trips_fingerprint] {
+			if hasSameTrips(journey, unique_journeys[variant_index]) {
+				index, has_variant = variant_index, true
+				break
+			}
+		}
+		if !has_variant {
+			indexes_by_trips_fingerprint[trips_fingerprint] = append(indexes_by_trips_fingerprint[trips_fingerprint], len(unique_journeys))
+			unique_journeys = append(unique_journeys, journey)
+			continue
+		}
+		if cmp.Or(
+			cmp.Compare(journey.GetWalkingDurationInSeconds(), unique_journeys[index].GetWalkingDurationInSeconds()),
+			compareJourneys(journey, unique_journeys[index], ""),
+		) < 0 {
+			unique_journeys[index] = journey
+		}
+	}
+	return unique_journeys
+}
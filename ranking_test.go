@@ -0,0 +1,229 @@
+package go_raptor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getRankingTestTripLeg(from_stop_id string, to_stop_id string, trip_id string, departure TimestampInSeconds, arrival TimestampInSeconds) RoundSegmentSpan[string] {
+	return RoundSegmentSpan[string]{
+		FromUniqueStopID:                       from_stop_id,
+		ToUniqueStopID:                         to_stop_id,
+		ViaTrip:                                &ViaTrip[string]{UniqueTripID: trip_id, UniqueTripServiceID: trip_id},
+		DepartureTimeInSecondsFromUniqueStopID: departure,
+		ArrivalTimeInSecondsToUniqueStopID:     arrival,
+	}
+}
+
+func getRankingTestWalkingLeg(from_stop_id string, to_stop_id string, departure TimestampInSeconds, arrival TimestampInSeconds) RoundSegmentSpan[string] {
+	return RoundSegmentSpan[string]{
+		FromUniqueStopID:                       from_stop_id,
+		ToUniqueStopID:                         to_stop_id,
+		DepartureTimeInSecondsFromUniqueStopID: departure,
+		ArrivalTimeInSecondsToUniqueStopID:     arrival,
+	}
+}
+
+func getRankingTestJourney(legs ...RoundSegmentSpan[string]) Journey[string] {
+	return RoundSegment[string]{Spans: legs}.ToJourney()
+}
+
+func getRankingTestJourneys() []Journey[string] {
+	return []Journey[string]{
+		/* direct - slow but without transfers */
+		getRankingTestJourney(getRankingTestTripLeg("A", "Z", "T1", 1000, 3000)),
+		/* fastest arrival with a transfer and a long walk */
+		getRankingTestJourney(
+			getRankingTestTripLeg("A", "B", "T2", 1000, 1500),
+			getRankingTestWalkingLeg("B", "C", 1500, 1800),
+			getRankingTestTripLeg("C", "Z", "T3", 1900, 2000),
+		),
+		/* leaves later and arrives at the same time with the same walk as the above */
+		getRankingTestJourney(
+			getRankingTestTripLeg("A", "B", "T4", 1200, 1600),
+			getRankingTestWalkingLeg("B", "C", 1600, 1900),
+			getRankingTestTripLeg("C", "Z", "T3", 1900, 2000),
+		),
+	}
+}
+
+func TestJourney_Criteria(t *testing.T) {
+	journeys := getRankingTestJourneys()
+	assert.Equal(t, 0, journeys[0].GetTransferCount())
+	assert.Equal(t, 1, journeys[1].GetTransferCount())
+	assert.Equal(t, TimestampInSeconds(1000), journeys[1].GetDurationInSeconds())
+	assert.Equal(t, TimestampInSeconds(300), journeys[1].GetWalkingDurationInSeconds())
+
+	journeys[1].AccessDurationInSeconds = 120
+	assert.Equal(t, TimestampInSeconds(420), journeys[1].GetWalkingDurationInSeconds(), "should include the access duration")
+}
+
+func TestRankJourneys(t *testing.T) {
+	getTripIds := func(journeys []Journey[string]) [][]string {
+		trip_ids := [][]string{}
+		for _, journey := range journeys {
+			journey_trip_ids := []string{}
+			for _, leg := range journey.Legs {
+				if leg.ViaTrip != nil {
+					journey_trip_ids = append(journey_trip_ids, leg.ViaTrip.UniqueTripID)
+				}
+			}
+			trip_ids = append(trip_ids, journey_trip_ids)
+		}
+		return trip_ids
+	}
+
+	assert.Equal(t, [][]string{{"T1"}, {"T2", "T3"}, {"T4", "T3"}}, getTripIds(RankJourneys(getRankingTestJourneys(), RaptorJourneyRanking{})), "should keep the order without a ranking")
+	assert.Equal(t, [][]string{{"T4", "T3"}, {"T2", "T3"}, {"T1"}}, getTripIds(RankJourneys(getRankingTestJourneys(), RaptorJourneyRanking{SortBy: RaptorJourneySortByArrival})), "should prefer the later departure for the same arrival")
+	assert.Equal(t, [][]string{{"T4", "T3"}, {"T2", "T3"}, {"T1"}}, getTripIds(RankJourneys(getRankingTestJourneys(), RaptorJourneyRanking{SortBy: RaptorJourneySortByDeparture})))
+	assert.Equal(t, [][]string{{"T4", "T3"}, {"T2", "T3"}, {"T1"}}, getTripIds(RankJourneys(getRankingTestJourneys(), RaptorJourneyRanking{SortBy: RaptorJourneySortByDuration})))
+	assert.Equal(t, [][]string{{"T1"}, {"T4", "T3"}, {"T2", "T3"}}, getTripIds(RankJourneys(getRankingTestJourneys(), RaptorJourneyRanking{SortBy: RaptorJourneySortByTransfers})))
+	assert.Equal(t, [][]string{{"T1"}, {"T4", "T3"}, {"T2", "T3"}}, getTripIds(RankJourneys(getRankingTestJourneys(), RaptorJourneyRanking{SortBy: RaptorJourneySortByWalking})))
+	assert.Equal(t, [][]string{{"T1"}}, getTripIds(RankJourneys(getRankingTestJourneys(), RaptorJourneyRanking{SortBy: RaptorJourneySortByTransfers, MaxResults: 1})))
+
+	/* the second journey leaves earlier for the same arrival, transfers and walking */
+	assert.Equal(t, [][]string{{"T1"}, {"T4", "T3"}}, getTripIds(RankJourneys(getRankingTestJourneys(), RaptorJourneyRanking{RemoveDominated: true})))
+
+	penalized_journeys := getRankingTestJourneys()
+	penalized_journeys[2].PenaltyInSeconds = 60
+	assert.Len(t, RankJourneys(penalized_journeys, RaptorJourneyRanking{RemoveDominated: true}), 3, "should keep the journeys with a lower penalty")
+}
+
+func TestRankJourneys_RemoveTransferVariants(t *testing.T) {
+	journeys := []Journey[string]{
+		getRankingTestJourney(
+			getRankingTestTripLeg("A", "B", "T1", 1000, 1500),
+			getRankingTestWalkingLeg("B", "C", 1500, 1800),
+			getRankingTestTripLeg("C", "Z", "T2", 1900, 2000),
+		),
+		/* the same trips but getting off at another stop with a shorter walk */
+		getRankingTestJourney(
+			getRankingTestTripLeg("A", "D", "T1", 1000, 1600),
+			getRankingTestWalkingLeg("D", "E", 1600, 1700),
+			getRankingTestTripLeg("E", "Z", "T2", 1800, 2000),
+		),
+		/* the same trips but to another destination */
+		getRankingTestJourney(
+			getRankingTestTripLeg("A", "B", "T1", 1000, 1500),
+			getRankingTestWalkingLeg("B", "C", 1500, 1800),
+			getRankingTestTripLeg("C", "Y", "T2", 1900, 2100),
+		),
+	}
+	ranked_journeys := RankJourneys(journeys, RaptorJourneyRanking{RemoveTransferVariants: true})
+	assert.Len(t, ranked_journeys, 2)
+	assert.Equal(t, "D", ranked_journeys[0].Legs[0].ToUniqueStopID, "should keep the variant walking the least")
+	assert.Equal(t, "Y", ranked_journeys[1].ToUniqueStopID)
+}
+
+func TestGetFingerPrintHash(t *testing.T) {
+	journeys := getRankingTestJourneys()
+	assert.Equal(t, journeys[1].GetFingerPrintHash(), RoundSegment[string]{Spans: journeys[1].Legs}.GetFingerPrintHash())
+	assert.NotEqual(t, journeys[1].GetFingerPrintHash(), journeys[2].GetFingerPrintHash())
+
+	/* the boundaries between the IDs are part of the fingerprint */
+	assert.NotEqual(t,
+		getRankingTestJourney(getRankingTestTripLeg("AB", "C", "T1", 0, 0)).GetFingerPrintHash(),
+		getRankingTestJourney(getRankingTestTripLeg("A", "BC", "T1", 0, 0)).GetFingerPrintHash(),
+	)
+	walking_journey := getRankingTestJourney(getRankingTestWalkingLeg("A", "B", 0, 0))
+	assert.NotEqual(t, walking_journey.GetFingerPrintHash(), getRankingTestJourney(getRankingTestTripLeg("A", "B", "", 0, 0)).GetFingerPrintHash())
+
+	int_journey := Journey[int64]{Legs: []RoundSegmentSpan[int64]{{FromUniqueStopID: 1, ToUniqueStopID: 2}}}
+	assert.NotEqual(t, int_journey.GetFingerPrintHash(), Journey[int64]{Legs: []RoundSegmentSpan[int64]{{FromUniqueStopID: 2, ToUniqueStopID: 1}}}.GetFingerPrintHash())
+}
+
+func TestJourneySpansSet(t *testing.T) {
+	journeys := getRankingTestJourneys()
+	set := newJourneySpansSet[string]()
+	assert.True(t, set.Add(journeys[1].Legs))
+	assert.False(t, set.Add(getRankingTestJourneys()[1].Legs), "should not add the same spans twice")
+	assert.True(t, journeys[1].HasSameLegs(getRankingTestJourneys()[1]))
+	assert.False(t, journeys[1].HasSameLegs(journeys[2]))
+
+	/* a different journey which happens to have the same fingerprint is still added */
+	fingerprint := journeys[2].GetFingerPrintHash()
+	set.spans_by_fingerprint[fingerprint] = append(set.spans_by_fingerprint[fingerprint], journeys[0].Legs)
+	assert.True(t, set.Add(journeys[2].Legs))
+	assert.False(t, set.Add(journeys[2].Legs))
+}
+
+func TestSimpleRaptor_Ranking(t *testing.T) {
+	/* A -> C -> D on T1 and D -> F on T2 - the journey to C arrives earlier without transfers */
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "C", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "D", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 3, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+			{UniqueStopID: "D", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 1, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+			{UniqueStopID: "F", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 2, ArrivalTimeInSeconds: 1400, DepartureTimeInSeconds: 1400},
+		},
+		FromStops:        []GtfsStopStruct[string]{{UniqueID: "A"}},
+		ToStops:          []GtfsStopStruct[string]{{UniqueID: "F"}, {UniqueID: "C"}},
+		Mode:             RaptorModeDepartAt,
+		TimeInSeconds:    900,
+		MaximumTransfers: 4,
+	}
+
+	for _, engine := range []RaptorEngine{RaptorEngineRaptor, RaptorEngineCsa} {
+		input := base_input
+		input.Engine = engine
+		assert.Len(t, SimpleRaptor(input), 2, engine)
+
+		input.Ranking = RaptorJourneyRanking{SortBy: RaptorJourneySortByArrival, MaxResults: 1}
+		journeys := SimpleRaptor(input)
+		assert.Len(t, journeys, 1, engine)
+		assert.Equal(t, "C", journeys[0].ToUniqueStopID, engine)
+
+		input.Ranking = RaptorJourneyRanking{RemoveDominated: true}
+		journeys = SimpleRaptor(input)
+		assert.Len(t, journeys, 1, engine)
+		assert.Equal(t, "C", journeys[0].ToUniqueStopID, engine)
+	}
+}
+
+func TestSimpleRaptor_RankingOrder(t *testing.T) {
+	/* T1 calls at all the destinations (and origins for arrive by) - which are found in the same round */
+	stop_times := []GtfsStopTimeStruct[string]{}
+	stops := []GtfsStopStruct[string]{}
+	for index := range 8 {
+		stop_id := fmt.Sprintf("S%d", index)
+		stop_times = append(stop_times, GtfsStopTimeStruct[string]{UniqueStopID: stop_id, UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: index + 2, ArrivalTimeInSeconds: TimestampInSeconds(1100 + index*100), DepartureTimeInSeconds: TimestampInSeconds(1100 + index*100)})
+		stops = append(stops, GtfsStopStruct[string]{UniqueID: stop_id})
+	}
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		StopTimes: append([]GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+		}, append(stop_times,
+			GtfsStopTimeStruct[string]{UniqueStopID: "Z", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 20, ArrivalTimeInSeconds: 2000, DepartureTimeInSeconds: 2000},
+		)...),
+		MaximumTransfers: 4,
+		Ranking:          RaptorJourneyRanking{MaxResults: 1},
+	}
+
+	for _, engine := range testEngines {
+		for _, mode := range []RaptorMode{RaptorModeDepartAt, RaptorModeArriveBy} {
+			input := base_input
+			input.Engine = engine
+			input.Mode = mode
+			input.FromStops = []GtfsStopStruct[string]{{UniqueID: "A"}}
+			input.ToStops = stops
+			input.TimeInSeconds = 900
+			if mode == RaptorModeArriveBy {
+				input.FromStops = stops
+				input.ToStops = []GtfsStopStruct[string]{{UniqueID: "Z"}}
+				input.TimeInSeconds = 2100
+			}
+			for range 10 {
+				journeys := SimpleRaptor(input)
+				assert.Len(t, journeys, 1, "%s %s", engine, mode)
+				if mode == RaptorModeDepartAt {
+					assert.Equal(t, "S0", journeys[0].ToUniqueStopID, "%s %s should keep the journeys in the order of the stops", engine, mode)
+				} else {
+					assert.Equal(t, "S0", journeys[0].FromUniqueStopID, "%s %s should keep the journeys in the order of the stops", engine, mode)
+				}
+			}
+		}
+	}
+}
@@ -2,6 +2,18 @@ package go_raptor
 
 type RaptorMode string
 type RaptorMarkedStopSource = string
+type RaptorEngine string
+type TransitMode string
+type RaptorJourneySortOrder string
+
+/* the basic GTFS route types - extended route types (100-1799) are mapped onto these using GetTransitModeForRouteType */
+type GtfsRouteType = int
+
+/* the GTFS wheelchair_boarding and wheelchair_accessible values */
+type GtfsWheelchairAccessibility = int
+
+/* the GTFS bikes_allowed values */
+type GtfsBikesAllowed = int
 
 const (
 	RaptorModeDepartAt RaptorMode = "depart_at"
@@ -12,3 +24,76 @@ const (
 	RaptorMarkedStopSourceArrival  RaptorMarkedStopSource = "arrival"
 	RaptorMarkedStopSourceTransfer RaptorMarkedStopSource = "transfer"
 )
+
+const (
+	RaptorEngineRaptor RaptorEngine = "raptor"
+	RaptorEngineCsa    RaptorEngine = "csa"
+	/* the trip based engine only supports depart at - arrive by queries fall back to raptor */
+	RaptorEngineTripBased RaptorEngine = "trip_based"
+)
+
+const (
+	/* earliest arrival first */
+	RaptorJourneySortByArrival RaptorJourneySortOrder = "arrival"
+	/* latest departure first */
+	RaptorJourneySortByDeparture RaptorJourneySortOrder = "departure"
+	/* shortest time between the departure and arrival first */
+	RaptorJourneySortByDuration RaptorJourneySortOrder = "duration"
+	/* fewest transfers between trips first */
+	RaptorJourneySortByTransfers RaptorJourneySortOrder = "transfers"
+	/* least time spent walking (including the access and egress) first */
+	RaptorJourneySortByWalking RaptorJourneySortOrder = "walking"
+)
+
+const (
+	GtfsRouteTypeTram       GtfsRouteType = 0
+	GtfsRouteTypeSubway     GtfsRouteType = 1
+	GtfsRouteTypeRail       GtfsRouteType = 2
+	GtfsRouteTypeBus        GtfsRouteType = 3
+	GtfsRouteTypeFerry      GtfsRouteType = 4
+	GtfsRouteTypeCableTram  GtfsRouteType = 5
+	GtfsRouteTypeAerialLift GtfsRouteType = 6
+	GtfsRouteTypeFunicular  GtfsRouteType = 7
+	GtfsRouteTypeTrolleybus GtfsRouteType = 11
+	GtfsRouteTypeMonorail   GtfsRouteType = 12
+)
+
+const (
+	TransitModeTram       TransitMode = "tram"
+	TransitModeSubway     TransitMode = "subway"
+	TransitModeRail       TransitMode = "rail"
+	TransitModeBus        TransitMode = "bus"
+	TransitModeFerry      TransitMode = "ferry"
+	TransitModeCableTram  TransitMode = "cable_tram"
+	TransitModeAerialLift TransitMode = "aerial_lift"
+	TransitModeFunicular  TransitMode = "funicular"
+	TransitModeTrolleybus TransitMode = "trolleybus"
+	TransitModeMonorail   TransitMode = "monorail"
+	TransitModeAir        TransitMode = "air"
+	TransitModeTaxi       TransitMode = "taxi"
+	TransitModeOther      TransitMode = "other"
+	/* used for walking transfers */
+	TransitModeWalk TransitMode = "walk"
+	/* used when the trip or route lookups are not available */
+	TransitModeUnknown TransitMode = "unknown"
+)
+
+const (
+	GtfsWheelchairAccessibilityUnknown       GtfsWheelchairAccessibility = 0
+	GtfsWheelchairAccessibilityAccessible    GtfsWheelchairAccessibility = 1
+	GtfsWheelchairAccessibilityNotAccessible GtfsWheelchairAccessibility = 2
+)
+
+const (
+	GtfsBikesAllowedUnknown    GtfsBikesAllowed = 0
+	GtfsBikesAllowedAllowed    GtfsBikesAllowed = 1
+	GtfsBikesAllowedNotAllowed GtfsBikesAllowed = 2
+)
+
+const (
+	DefaultWalkingSpeedInMetersPerSecond float64 = 1.4
+	DefaultCyclingSpeedInMetersPerSecond float64 = 4.5
+)
+
+/* the number of queries a RaptorStatsCollector keeps when its MaxQueries is not set */
+const DefaultRaptorStatsCollectorMaxQueries = 100
@@ -0,0 +1,168 @@
+package go_raptor
+
+/** the per query lookups used to determine which stop times can be used */
+type RaptorFilters[ID UniqueGtfsIdLike] struct {
+	BannedUniqueTripIds       map[ID]bool
+	BannedUniqueRouteIds      map[ID]bool
+	BannedUniqueAgencyIds     map[ID]bool
+	BannedUniqueStopIds       map[ID]bool
+	PreferredUniqueRouteIds   map[ID]bool
+	UnpreferredUniqueRouteIds map[ID]bool
+	AllowedTransitModes       map[TransitMode]bool
+	/* not a filter but used to mark the legs of the journeys */
+	EstimatedUniqueTripServiceIds map[ID]bool
+	/* whether any of the filters need the trip and route lookups - this allows skipping them entirely */
+	HasRouteFilters bool
+}
+
+func PrepareRaptorFilters[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) RaptorFilters[ID] {
+	filters := RaptorFilters[ID]{
+		BannedUniqueTripIds:           toUniqueIdSet(input.BannedUniqueTripIds),
+		BannedUniqueRouteIds:          toUniqueIdSet(input.BannedUniqueRouteIds),
+		BannedUniqueAgencyIds:         toUniqueIdSet(input.BannedUniqueAgencyIds),
+		BannedUniqueStopIds:           toUniqueIdSet(input.BannedUniqueStopIds),
+		PreferredUniqueRouteIds:       toUniqueIdSet(input.PreferredUniqueRouteIds),
+		UnpreferredUniqueRouteIds:     toUniqueIdSet(input.UnpreferredUniqueRouteIds),
+		AllowedTransitModes:           toUniqueIdSet(input.AllowedTransitModes),
+		EstimatedUniqueTripServiceIds: toUniqueIdSet(input.EstimatedUniqueTripServiceIDs),
+	}
+	filters.HasRouteFilters = len(filters.BannedUniqueRouteIds) > 0 ||
+		len(filters.BannedUniqueAgencyIds) > 0 ||
+		len(filters.AllowedTransitModes) > 0 ||
+		input.RoutePreferencePenaltyInSeconds != 0 && (len(filters.PreferredUniqueRouteIds) > 0 || len(filters.UnpreferredUniqueRouteIds) > 0)
+	return filters
+}
+
+func toUniqueIdSet[ID comparable](unique_ids []ID) map[ID]bool {
+	unique_id_set := make(map[ID]bool, len(unique_ids))
+	for _, unique_id := range unique_ids {
+		unique_id_set[unique_id] = true
+	}
+	return unique_id_set
+}
+
+/** looks up the route of the stop time's trip - only available when the trip and route lookups were passed */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) getStopTimeRoute(stop_time StopTimeType) (GtfsRoute[ID], bool) {
+	trip, has_trip := prepared_input.Input.TripsByUniqueTripId[stop_time.GetUniqueTripID()]
+	if !has_trip {
+		return nil, false
+	}
+	route, has_route := prepared_input.Input.RoutesByUniqueRouteId[trip.GetUniqueRouteID()]
+	return route, has_route
+}
+
+/** checks whether the stop can be used for boarding, alighting or transferring */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) IsStopAllowed(unique_stop_id ID) bool {
+	if prepared_input.Filters.BannedUniqueStopIds[unique_stop_id] {
+		return false
+	}
+	if prepared_input.Input.AccessibilityProfile.Wheelchair {
+		wheelchair_boarding := GtfsWheelchairAccessibilityUnknown
+		if stop, has_stop := prepared_input.Input.StopsByUniqueStopId[unique_stop_id]; has_stop {
+			if stop_with_wheelchair_boarding, has_wheelchair_boarding := any(stop).(GtfsStopWithWheelchairBoarding[ID]); has_wheelchair_boarding {
+				wheelchair_boarding = stop_with_wheelchair_boarding.GetWheelchairBoarding()
+			}
+		}
+		return prepared_input.isWheelchairAccessible(wheelchair_boarding)
+	}
+	return true
+}
+
+/** checks whether the transfer can be walked - meaning the stop it leads to is allowed as well */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) IsTransferAllowed(transfer TransferType) bool {
+	return prepared_input.isTransferAccessible(transfer) && prepared_input.IsStopAllowed(transfer.GetToUniqueStopID())
+}
+
+/** checks whether the transfer can be walked to the stop in an arrive by search - meaning the stop it is walked from is allowed as well */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) isArriveByTransferAllowed(transfer TransferType, to_unique_stop_id ID) bool {
+	return prepared_input.isTransferAccessible(transfer) && prepared_input.IsStopAllowed(getArriveByTransferFromStop(transfer, to_unique_stop_id))
+}
+
+/** gets the stop the transfer is walked from to reach the stop in an arrive by search - the other end of a transfer which is walked backwards */
+func getArriveByTransferFromStop[ID UniqueGtfsIdLike, TransferType GtfsTransfer[ID]](transfer TransferType, to_unique_stop_id ID) ID {
+	if transfer.GetToUniqueStopID() == to_unique_stop_id {
+		return transfer.GetFromUniqueStopID()
+	}
+	return transfer.GetToUniqueStopID()
+}
+
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) isTransferAccessible(transfer TransferType) bool {
+	if !prepared_input.Input.AccessibilityProfile.Wheelchair {
+		return true
+	}
+	wheelchair_accessible := GtfsWheelchairAccessibilityUnknown
+	if transfer_with_wheelchair_accessibility, has_wheelchair_accessibility := any(transfer).(GtfsTransferWithWheelchairAccessibility[ID]); has_wheelchair_accessibility {
+		wheelchair_accessible = transfer_with_wheelchair_accessibility.GetWheelchairAccessible()
+	}
+	return prepared_input.isWheelchairAccessible(wheelchair_accessible)
+}
+
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) isWheelchairAccessible(accessibility GtfsWheelchairAccessibility) bool {
+	return accessibility == GtfsWheelchairAccessibilityAccessible ||
+		accessibility == GtfsWheelchairAccessibilityUnknown && prepared_input.Input.AccessibilityProfile.AllowUnknownAccessibility
+}
+
+/** checks whether the stop time's trip can be boarded at all - stops are checked separately using IsStopAllowed */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) IsTripAllowed(stop_time StopTimeType) bool {
+	if prepared_input.Filters.BannedUniqueTripIds[stop_time.GetUniqueTripID()] {
+		return false
+	}
+	if prepared_input.Input.AccessibilityProfile.Wheelchair {
+		wheelchair_accessible := GtfsWheelchairAccessibilityUnknown
+		if trip, has_trip := prepared_input.Input.TripsByUniqueTripId[stop_time.GetUniqueTripID()]; has_trip {
+			wheelchair_accessible = trip.GetWheelchairAccessible()
+		}
+		if !prepared_input.isWheelchairAccessible(wheelchair_accessible) {
+			return false
+		}
+	}
+	if prepared_input.Input.BikeProfile.Enabled {
+		bikes_allowed := GtfsBikesAllowedUnknown
+		if trip, has_trip := prepared_input.Input.TripsByUniqueTripId[stop_time.GetUniqueTripID()]; has_trip {
+			bikes_allowed = trip.GetBikesAllowed()
+		}
+		if bikes_allowed != GtfsBikesAllowedAllowed && !(bikes_allowed == GtfsBikesAllowedUnknown && prepared_input.Input.BikeProfile.AllowUnknownBikesAllowed) {
+			return false
+		}
+	}
+	if !prepared_input.Filters.HasRouteFilters {
+		return true
+	}
+	route, has_route := prepared_input.getStopTimeRoute(stop_time)
+	if !has_route {
+		/* without route information we can not tell whether it is banned so we allow it - unless only some modes are allowed */
+		return len(prepared_input.Filters.AllowedTransitModes) == 0
+	}
+	if len(prepared_input.Filters.AllowedTransitModes) > 0 && !prepared_input.Filters.AllowedTransitModes[GetTransitModeForRouteType(route.GetRouteType())] {
+		return false
+	}
+	return !prepared_input.Filters.BannedUniqueRouteIds[route.GetUniqueID()] && !prepared_input.Filters.BannedUniqueAgencyIds[route.GetUniqueAgencyID()]
+}
+
+/** checks whether the stop time can be boarded - meaning both the trip and the stop are allowed */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) CanBoardStopTime(stop_time StopTimeType) bool {
+	return prepared_input.IsStopAllowed(stop_time.GetUniqueStopID()) && prepared_input.IsTripAllowed(stop_time)
+}
+
+/** checks whether the stop time can be alighted at */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) CanAlightStopTime(stop_time StopTimeType) bool {
+	return prepared_input.IsStopAllowed(stop_time.GetUniqueStopID())
+}
+
+/** the route preference penalty for riding the stop time's trip */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) GetTripPenaltyInSeconds(stop_time StopTimeType) TimestampInSeconds {
+	if prepared_input.Input.RoutePreferencePenaltyInSeconds == 0 || !prepared_input.Filters.HasRouteFilters {
+		return 0
+	}
+	route, has_route := prepared_input.getStopTimeRoute(stop_time)
+	if !has_route {
+		return 0
+	}
+	if prepared_input.Filters.UnpreferredUniqueRouteIds[route.GetUniqueID()] ||
+		len(prepared_input.Filters.PreferredUniqueRouteIds) > 0 && !prepared_input.Filters.PreferredUniqueRouteIds[route.GetUniqueID()] {
+		return TimestampInSeconds(prepared_input.Input.RoutePreferencePenaltyInSeconds)
+	}
+	return 0
+}
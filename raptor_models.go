@@ -1,6 +1,7 @@
 package go_raptor
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -38,9 +39,59 @@ type GtfsStopTime[ID UniqueGtfsIdLike] interface {
 	GetDepartureTimeInSeconds() TimestampInSeconds
 }
 
+/* optional - only stops implementing this are used when generating transfers or access and egress durations from coordinates */
+type GtfsStopWithCoordinates[ID UniqueGtfsIdLike] interface {
+	GtfsStop[ID]
+	/* the WGS84 latitude and longitude of the stop - false when the stop has no coordinates */
+	GetCoordinates() (float64, float64, bool)
+}
+
+/* optional - the wheelchair boarding of stops not implementing this is unknown */
+type GtfsStopWithWheelchairBoarding[ID UniqueGtfsIdLike] interface {
+	GtfsStop[ID]
+	/* the stops.txt wheelchair_boarding value */
+	GetWheelchairBoarding() GtfsWheelchairAccessibility
+}
+
+/* optional - the wheelchair accessibility of transfers not implementing this is unknown */
+type GtfsTransferWithWheelchairAccessibility[ID UniqueGtfsIdLike] interface {
+	GtfsTransfer[ID]
+	/* whether the transfer can be made in a wheelchair - transfers.txt has no such field so this is usually derived from pathways */
+	GetWheelchairAccessible() GtfsWheelchairAccessibility
+}
+
+/* optional - transfers implementing this will have their path attached to the walking legs of the journeys */
+type GtfsTransferWithPath[ID UniqueGtfsIdLike] interface {
+	GtfsTransfer[ID]
+	GetPath() []TransferPathStep[ID]
+}
+
+/* optional - only required when filtering or annotating journeys by trip or route attributes */
+type GtfsTrip[ID UniqueGtfsIdLike] interface {
+	/* this is the unique trip ID - matching the stop time GetUniqueTripID */
+	GetUniqueID() ID
+	GetUniqueRouteID() ID
+	/* the trips.txt wheelchair_accessible value */
+	GetWheelchairAccessible() GtfsWheelchairAccessibility
+	/* the trips.txt bikes_allowed value */
+	GetBikesAllowed() GtfsBikesAllowed
+}
+
+type GtfsRoute[ID UniqueGtfsIdLike] interface {
+	GetUniqueID() ID
+	GetUniqueAgencyID() ID
+	/* either a basic or an extended GTFS route type */
+	GetRouteType() GtfsRouteType
+}
+
 type GtfsStopStruct[ID UniqueGtfsIdLike] struct {
 	GtfsStop[ID]
-	UniqueID ID
+	UniqueID           ID
+	WheelchairBoarding GtfsWheelchairAccessibility
+	Latitude           float64
+	Longitude          float64
+	/* whether the latitude and longitude are set - (0, 0) is a valid coordinate */
+	HasCoordinates bool
 }
 
 type GtfsTransferStruct[ID UniqueGtfsIdLike] struct {
@@ -48,6 +99,7 @@ type GtfsTransferStruct[ID UniqueGtfsIdLike] struct {
 	FromUniqueStopID             ID
 	ToUniqueStopID               ID
 	MinimumTransferTimeInSeconds int
+	WheelchairAccessible         GtfsWheelchairAccessibility
 }
 
 type GtfsStopTimeStruct[ID UniqueGtfsIdLike] struct {
@@ -62,10 +114,33 @@ type GtfsStopTimeStruct[ID UniqueGtfsIdLike] struct {
 	DepartureTimeInSeconds TimestampInSeconds
 }
 
+type GtfsTripStruct[ID UniqueGtfsIdLike] struct {
+	GtfsTrip[ID]
+	UniqueID             ID
+	UniqueRouteID        ID
+	WheelchairAccessible GtfsWheelchairAccessibility
+	BikesAllowed         GtfsBikesAllowed
+}
+
+type GtfsRouteStruct[ID UniqueGtfsIdLike] struct {
+	GtfsRoute[ID]
+	UniqueID       ID
+	UniqueAgencyID ID
+	RouteType      GtfsRouteType
+}
+
 func (b GtfsStopStruct[T]) GetUniqueID() T {
 	return b.UniqueID
 }
 
+func (b GtfsStopStruct[T]) GetWheelchairBoarding() GtfsWheelchairAccessibility {
+	return b.WheelchairBoarding
+}
+
+func (b GtfsStopStruct[T]) GetCoordinates() (float64, float64, bool) {
+	return b.Latitude, b.Longitude, b.HasCoordinates
+}
+
 func (b GtfsTransferStruct[T]) GetFromUniqueStopID() T {
 	return b.FromUniqueStopID
 }
@@ -78,6 +153,10 @@ func (b GtfsTransferStruct[T]) GetMinimumTransferTimeInSeconds() int {
 	return b.MinimumTransferTimeInSeconds
 }
 
+func (b GtfsTransferStruct[T]) GetWheelchairAccessible() GtfsWheelchairAccessibility {
+	return b.WheelchairAccessible
+}
+
 func (b GtfsStopTimeStruct[T]) GetUniqueStopID() T {
 	return b.UniqueStopID
 }
@@ -102,11 +181,51 @@ func (b GtfsStopTimeStruct[T]) GetDepartureTimeInSeconds() TimestampInSeconds {
 	return b.DepartureTimeInSeconds
 }
 
+func (b GtfsTripStruct[T]) GetUniqueID() T {
+	return b.UniqueID
+}
+
+func (b GtfsTripStruct[T]) GetUniqueRouteID() T {
+	return b.UniqueRouteID
+}
+
+func (b GtfsTripStruct[T]) GetWheelchairAccessible() GtfsWheelchairAccessibility {
+	return b.WheelchairAccessible
+}
+
+func (b GtfsTripStruct[T]) GetBikesAllowed() GtfsBikesAllowed {
+	return b.BikesAllowed
+}
+
+func (b GtfsRouteStruct[T]) GetUniqueID() T {
+	return b.UniqueID
+}
+
+func (b GtfsRouteStruct[T]) GetUniqueAgencyID() T {
+	return b.UniqueAgencyID
+}
+
+func (b GtfsRouteStruct[T]) GetRouteType() GtfsRouteType {
+	return b.RouteType
+}
+
 type ViaTrip[ID UniqueGtfsIdLike] struct {
 	UniqueTripID           ID
 	UniqueTripServiceID    ID
 	FromStopSequenceInTrip int
 	ToStopSequenceInTrip   int
+	/* whether the times are estimated - as is the case for the frequency based trips without exact times */
+	IsEstimated bool
+	/* the calls between the from and to stop - only set when IncludeIntermediateStops is passed */
+	IntermediateStops []StopCall[ID]
+}
+
+/** a single call of a trip at a stop */
+type StopCall[ID UniqueGtfsIdLike] struct {
+	UniqueStopID           ID
+	StopSequence           int
+	ArrivalTimeInSeconds   TimestampInSeconds
+	DepartureTimeInSeconds TimestampInSeconds
 }
 
 /**
@@ -123,6 +242,26 @@ type RoundSegmentSpan[ID UniqueGtfsIdLike] struct {
 	ViaTrip                                *ViaTrip[ID]
 	ArrivalTimeInSecondsToUniqueStopID     TimestampInSeconds
 	DepartureTimeInSecondsFromUniqueStopID TimestampInSeconds
+	/* the mode of the trip (based on the route type) or walk for transfers */
+	Mode TransitMode
+	/* whether a bike is carried during this leg - set for all legs when using the bike profile */
+	WithBike bool
+	/* the steps of a walking leg - only available when the transfer implements GtfsTransferWithPath */
+	Path []TransferPathStep[ID]
+	/* the index of the walked transfer in the input Transfers - -1 for legs which do not walk a transfer */
+	TransferIndex int
+}
+
+/** a single step of a walking transfer within a station - eg. taking the stairs down to a platform */
+type TransferPathStep[ID UniqueGtfsIdLike] struct {
+	FromUniqueStopID ID
+	ToUniqueStopID   ID
+	PathwayID        string
+	/* the pathways.txt pathway_mode */
+	PathwayMode            int
+	TraversalTimeInSeconds int
+	SignpostedAs           string
+	ToLevelName            string
 }
 
 /**
@@ -134,6 +273,8 @@ type RoundSegment[ID UniqueGtfsIdLike] struct {
 	UniqueStopID         ID
 	ArrivalTimeInSeconds TimestampInSeconds
 	Spans                []RoundSegmentSpan[ID]
+	/* the accumulated route preference penalties - this is added to the arrival time (or subtracted for arrive by) when comparing segments */
+	PenaltyInSeconds TimestampInSeconds
 }
 
 type Journey[ID UniqueGtfsIdLike] struct {
@@ -142,6 +283,10 @@ type Journey[ID UniqueGtfsIdLike] struct {
 	DepartureTimeInSeconds TimestampInSeconds
 	ArrivalTimeInSeconds   TimestampInSeconds
 	Legs                   []RoundSegmentSpan[ID]
+	PenaltyInSeconds       TimestampInSeconds
+	/* the time it takes to get to the first stop and from the last stop - these are included in the departure and arrival times */
+	AccessDurationInSeconds TimestampInSeconds
+	EgressDurationInSeconds TimestampInSeconds
 }
 
 type StopTimePartitions[ID UniqueGtfsIdLike] struct {
@@ -161,6 +306,54 @@ type SimpleRaptorInput[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType
 	MaximumTransfers int
 	/* determines whether to allow walk-transferring more than once */
 	AllowTransferHopping bool
+	/* optional trip and route lookups by their unique IDs - these are required for filtering by routes or agencies */
+	TripsByUniqueTripId   map[ID]GtfsTrip[ID]
+	RoutesByUniqueRouteId map[ID]GtfsRoute[ID]
+	/* trips, routes, agencies and stops which should not be used at all (ie because they are out of service) */
+	BannedUniqueTripIds   []ID
+	BannedUniqueRouteIds  []ID
+	BannedUniqueAgencyIds []ID
+	BannedUniqueStopIds   []ID
+	/*
+	 * the route preference penalty is added for every leg on an unpreferred route
+	 * or on any route which is not one of the preferred routes when those are passed
+	 */
+	PreferredUniqueRouteIds         []ID
+	UnpreferredUniqueRouteIds       []ID
+	RoutePreferencePenaltyInSeconds int
+	/* only trips with these modes will be boarded (requires the route lookups - trips without a route are not boarded) - all modes are allowed when empty */
+	AllowedTransitModes []TransitMode
+	/* optional - the stop lookup is required for the wheelchair boarding checks of the accessibility profile */
+	StopsByUniqueStopId  map[ID]StopType
+	AccessibilityProfile RaptorAccessibilityProfile
+	BikeProfile          RaptorBikeProfile
+	/*
+	 * optional - the time it takes to get to the from stops and from the to stops (eg. from an address)
+	 * these can be calculated from coordinates using GetDurationsToStopsFromCoordinates
+	 */
+	AccessDurationsInSecondsByUniqueStopId map[ID]int
+	EgressDurationsInSecondsByUniqueStopId map[ID]int
+	/* optional - the trip service IDs of which the times are estimated (see ExpandFrequencies) */
+	EstimatedUniqueTripServiceIDs []ID
+	/* whether to include the intermediate stop calls of the transit legs */
+	IncludeIntermediateStops bool
+	/*
+	 * whether the searches skip anything arriving later than the best arrival at any destination found so far (departing earlier than the best departure
+	 * from any origin for arrive by) - the journeys to the other destinations are then only found when they are not later than the best one
+	 */
+	PruneByBestArrival bool
+	/* when pruning anything within this many seconds of the best is searched as well - and only the journeys within it are returned */
+	BestArrivalSlackInSeconds TimestampInSeconds
+	/*
+	 * whether to shift the departure of depart at journeys as late as possible without arriving later - ie. when a journey waits at a transfer
+	 * for a trip which could have been caught by departing later. this runs an arrive by search for every journey found
+	 */
+	CompressDepartureTimes bool
+	/* how the journeys are sorted and reduced once they are found (see RankJourneys) */
+	Ranking RaptorJourneyRanking
+
+	/* optional ordered list of stops the journey needs to pass through - the MaximumTransfers apply to the whole journey */
+	ViaStops []RaptorViaStop[ID, StopType]
 	/** determines the cut off time for any stop time lookup */
 	StopTimeCutOffTimestamp TimestampInSeconds
 
@@ -168,23 +361,88 @@ type SimpleRaptorInput[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType
 	TimePartitionInterval TimestampInSeconds
 
 	/** these can be passed if they are pre-calculated in memory before running raptor; useful for speeding up the actual raptor - uints refer to their list indexes from the input */
-	TransfersByUniqueStopId        *map[ID][]int
-	StopTimesByUniqueStopId        *map[ID][]int
-	StopTimesByUniqueTripServiceId *map[ID][]int
-	TimePartitions                 *StopTimePartitions[ID]
+	TransfersByUniqueStopId         *map[ID][]int
+	ArriveByTransfersByUniqueStopId *map[ID][]int
+	StopTimesByUniqueStopId         *map[ID][]int
+	StopTimesByUniqueTripServiceId  *map[ID][]int
+	TimePartitions                  *StopTimePartitions[ID]
+
+	/* determines which routing engine to use when calling SimpleRaptor - defaults to raptor */
+	Engine RaptorEngine
+	/* optional - receives the stats of every round of the query (see RaptorStatsCollector) */
+	Observer RaptorObserver
+	/* optional - passed to the observer ie. as the parent of the tracing spans - defaults to the background context */
+	Context context.Context
+	/* can be passed if the connections are pre-calculated before running the csa engine */
+	CsaConnections *CsaConnections
+	/* can be passed if the transfers are pre-calculated before running the trip based engine - these are expensive to calculate */
+	TripBasedTransfers *TripBasedTransfers[ID]
+}
+
+/** the accessibility requirements of the rider - the zero value does not restrict anything */
+type RaptorAccessibilityProfile struct {
+	/* only board wheelchair accessible trips at wheelchair boarding stops and only use wheelchair accessible transfers */
+	Wheelchair bool
+	/* whether stops, trips and transfers without any accessibility information are assumed to be accessible */
+	AllowUnknownAccessibility bool
+}
+
+/** the bike requirements of the rider - the zero value does not restrict anything */
+type RaptorBikeProfile struct {
+	/* only board trips which allow bikes - the journey legs will indicate a bike is carried */
+	Enabled bool
+	/* whether trips without bikes allowed information are assumed to allow bikes */
+	AllowUnknownBikesAllowed bool
+	/* the speed used for generated transfers and access and egress durations - defaults to DefaultCyclingSpeedInMetersPerSecond */
+	CyclingSpeedInMetersPerSecond float64
+}
+
+/** the post-processing of the journeys - the zero value returns all journeys in the order they were found */
+type RaptorJourneyRanking struct {
+	/* the journeys which are equally good are ordered by arrival, departure, transfers and walking */
+	SortBy RaptorJourneySortOrder
+	/* removes the journeys which are not better than another journey in any of arrival, departure, transfers, walking or penalty */
+	RemoveDominated bool
+	/* removes the journeys which ride the same trips between the same stops as another journey but walk between other stops - keeping the one walking the least */
+	RemoveTransferVariants bool
+	/* the number of journeys to return after sorting them - 0 returns all journeys */
+	MaxResults int
+}
+
+type RaptorViaStop[ID UniqueGtfsIdLike, StopType GtfsStop[ID]] struct {
+	Stop StopType
+	/* the minimum time to spend at the via stop before continuing the journey */
+	MinimumDwellTimeInSeconds int
 }
 
 type PreparedRaptorInput[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]] struct {
 	Input *SimpleRaptorInput[ID, StopType, TransferType, StopTimeType]
 
-	FromStopsByUniqueStopId        map[ID]ID
-	ToStopsByUniqueStopId          map[ID]ID
-	TransfersByUniqueStopId        map[ID][]int
-	StopTimesByUniqueStopId        map[ID][]int
-	StopTimesByUniqueTripServiceId map[ID][]int
+	FromStopsByUniqueStopId map[ID]ID
+	ToStopsByUniqueStopId   map[ID]ID
+	TransfersByUniqueStopId map[ID][]int
+	/* the transfers which can be walked to a stop - a transfer from the stop is walked backwards when there is no transfer in the other direction */
+	ArriveByTransfersByUniqueStopId map[ID][]int
+	StopTimesByUniqueStopId         map[ID][]int
+	StopTimesByUniqueTripServiceId  map[ID][]int
 
 	TimePartitionInterval TimestampInSeconds
 	TimePartitions        StopTimePartitions[ID]
+
+	Filters RaptorFilters[ID]
+}
+
+/** returns a copy of the input which re-uses the lookup maps of the prepared input - useful when running multiple searches on the same data */
+func (input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType]) WithPreparedInput(
+	prepared_input PreparedRaptorInput[ID, StopType, TransferType, StopTimeType],
+) SimpleRaptorInput[ID, StopType, TransferType, StopTimeType] {
+	input.TransfersByUniqueStopId = &prepared_input.TransfersByUniqueStopId
+	input.ArriveByTransfersByUniqueStopId = &prepared_input.ArriveByTransfersByUniqueStopId
+	input.StopTimesByUniqueStopId = &prepared_input.StopTimesByUniqueStopId
+	input.StopTimesByUniqueTripServiceId = &prepared_input.StopTimesByUniqueTripServiceId
+	input.TimePartitions = &prepared_input.TimePartitions
+	input.TimePartitionInterval = prepared_input.TimePartitionInterval
+	return input
 }
 
 type RaptorMarkedStop[ID UniqueGtfsIdLike] struct {
@@ -192,6 +450,17 @@ type RaptorMarkedStop[ID UniqueGtfsIdLike] struct {
 	Source RaptorMarkedStopSource
 }
 
+/** a stop time at which a trip can be boarded in a round (or alighted for arrive by) - along with how its stop was reached */
+type raptorTripBoarding[ID UniqueGtfsIdLike] struct {
+	StopTimeIndex int
+	/* the position of the stop time in the stop times of its trip service */
+	PositionInTrip int
+	Segment        RoundSegment[ID]
+	Source         RaptorMarkedStopSource
+	/* the penalty we will have accumulated once we ride the trip */
+	PenaltyInSeconds TimestampInSeconds
+}
+
 func (j RoundSegment[ID]) GetFingerPrint() string {
 	parts := []string{}
 	for _, leg := range j.Spans {
@@ -203,3 +472,36 @@ func (j RoundSegment[ID]) GetFingerPrint() string {
 	}
 	return strings.Join(parts, "->")
 }
+
+/**
+ * a hash of the stops and trips of the spans - segments and journeys taking the same trips between the same stops have the same hash.
+ * different spans can have the same hash as well so use hasSameSpans (or a journeySpansSet) before treating them as the same
+ */
+func (j RoundSegment[ID]) GetFingerPrintHash() uint64 {
+	return getSpansFingerPrint(j.Spans)
+}
+
+func (j Journey[ID]) GetFingerPrintHash() uint64 {
+	return getSpansFingerPrint(j.Legs)
+}
+
+/** whether the journeys take the same trips between the same stops - the journeys with the same fingerprint hash should be compared using this */
+func (j Journey[ID]) HasSameLegs(other Journey[ID]) bool {
+	return hasSameSpans(j.Legs, other.Legs)
+}
+
+/** converts a segment which was completed at a destination (or origin for arrive by) into a journey */
+func (j RoundSegment[ID]) ToJourney() Journey[ID] {
+	segment_spans := make([]RoundSegmentSpan[ID], len(j.Spans))
+	copy(segment_spans, j.Spans)
+	first_segment_span := segment_spans[0]
+	last_segment_span := segment_spans[len(segment_spans)-1]
+	return Journey[ID]{
+		FromUniqueStopID:       first_segment_span.FromUniqueStopID,
+		ToUniqueStopID:         last_segment_span.ToUniqueStopID,
+		DepartureTimeInSeconds: first_segment_span.DepartureTimeInSecondsFromUniqueStopID,
+		ArrivalTimeInSeconds:   last_segment_span.ArrivalTimeInSecondsToUniqueStopID,
+		Legs:                   segment_spans,
+		PenaltyInSeconds:       j.PenaltyInSeconds,
+	}
+}
@@ -2,12 +2,16 @@ package go_raptor
 
 import (
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+/* every fixture is run against each of the routing engines */
+var testEngines = []RaptorEngine{RaptorEngineRaptor, RaptorEngineCsa, RaptorEngineTripBased}
+
 func FormatSecondsSinceMidnight(secs int64) string {
 	hours := secs / 3600
 	minutes := (secs % 3600) / 60
@@ -16,299 +20,1105 @@ func FormatSecondsSinceMidnight(secs int64) string {
 }
 
 func TestSimpleForwardRaptor(t *testing.T) {
-	var epoch_20250822_120000_edt int64 = 1755878400
-	var epoch_20250823_120000_edt int64 = 1755964800
-	var epoch_20250824_120000_edt int64 = 1756051200
-
-	journeys := SimpleRaptor(
-		SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
-			FromStops: []GtfsStopStruct[string]{
-				{UniqueID: "High St"},
-			},
-			ToStops: []GtfsStopStruct[string]{
-				{UniqueID: "Franklin Av"},
-			},
-			Transfers: []GtfsTransferStruct[string]{},
-			StopTimes: []GtfsStopTimeStruct[string]{
-				{UniqueStopID: "High St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250822_120000_edt - 10, DepartureTimeInSeconds: epoch_20250822_120000_edt + 10},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 120, DepartureTimeInSeconds: epoch_20250822_120000_edt + 130},
-
-				{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
-
-				{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
-			},
-			Mode: RaptorModeDepartAt,
-			/* 2025/08/23 12:00:00PM EDT */
-			TimeInSeconds:        epoch_20250823_120000_edt,
-			MaximumTransfers:     4,
-			AllowTransferHopping: false,
-		},
-	)
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			var epoch_20250822_120000_edt int64 = 1755878400
+			var epoch_20250823_120000_edt int64 = 1755964800
+			var epoch_20250824_120000_edt int64 = 1756051200
 
-	if len(journeys) == 0 {
-		t.Fatalf(`did not find any journeys for stop times`)
-	}
+			journeys := SimpleRaptor(
+				SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+					FromStops: []GtfsStopStruct[string]{
+						{UniqueID: "High St"},
+					},
+					ToStops: []GtfsStopStruct[string]{
+						{UniqueID: "Franklin Av"},
+					},
+					Transfers: []GtfsTransferStruct[string]{},
+					StopTimes: []GtfsStopTimeStruct[string]{
+						{UniqueStopID: "High St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250822_120000_edt - 10, DepartureTimeInSeconds: epoch_20250822_120000_edt + 10},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 120, DepartureTimeInSeconds: epoch_20250822_120000_edt + 130},
+
+						{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
+
+						{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
+					},
+					Mode: RaptorModeDepartAt,
+					/* 2025/08/23 12:00:00PM EDT */
+					TimeInSeconds:        epoch_20250823_120000_edt,
+					MaximumTransfers:     4,
+					AllowTransferHopping: false,
+					Engine:               engine,
+				},
+			)
 
-	if journeys[0].ArrivalTimeInSeconds != epoch_20250823_120000_edt+120 {
-		t.Fatalf(`expected raptor to find arrival time %v but got %v`, epoch_20250823_120000_edt+120, journeys[0].ArrivalTimeInSeconds)
+			if len(journeys) == 0 {
+				t.Fatalf(`did not find any journeys for stop times`)
+			}
+
+			if journeys[0].ArrivalTimeInSeconds != epoch_20250823_120000_edt+120 {
+				t.Fatalf(`expected raptor to find arrival time %v but got %v`, epoch_20250823_120000_edt+120, journeys[0].ArrivalTimeInSeconds)
+			}
+		})
 	}
 }
 
 func TestSimpleReverseRaptor(t *testing.T) {
-	var epoch_20250822_120000_edt int64 = 1755878400
-	var epoch_20250823_120000_edt int64 = 1755964800
-	var epoch_20250824_120000_edt int64 = 1756051200
-
-	journeys := SimpleRaptor(
-		SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
-			FromStops: []GtfsStopStruct[string]{
-				{UniqueID: "High St"},
-			},
-			ToStops: []GtfsStopStruct[string]{
-				{UniqueID: "Franklin Av"},
-			},
-			Transfers: []GtfsTransferStruct[string]{},
-			StopTimes: []GtfsStopTimeStruct[string]{
-				{UniqueStopID: "High St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250822_120000_edt - 10, DepartureTimeInSeconds: epoch_20250822_120000_edt + 10},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 120, DepartureTimeInSeconds: epoch_20250822_120000_edt + 130},
-
-				{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
-
-				{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
-			},
-			Mode: RaptorModeArriveBy,
-			/* 2025/08/23 12:00:00PM EDT */
-			TimeInSeconds:        epoch_20250823_120000_edt + 120,
-			MaximumTransfers:     4,
-			AllowTransferHopping: false,
-		},
-	)
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			var epoch_20250822_120000_edt int64 = 1755878400
+			var epoch_20250823_120000_edt int64 = 1755964800
+			var epoch_20250824_120000_edt int64 = 1756051200
 
-	if len(journeys) == 0 {
-		t.Fatalf(`did not find any journeys for stop times`)
-	}
+			journeys := SimpleRaptor(
+				SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+					FromStops: []GtfsStopStruct[string]{
+						{UniqueID: "High St"},
+					},
+					ToStops: []GtfsStopStruct[string]{
+						{UniqueID: "Franklin Av"},
+					},
+					Transfers: []GtfsTransferStruct[string]{},
+					StopTimes: []GtfsStopTimeStruct[string]{
+						{UniqueStopID: "High St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250822_120000_edt - 10, DepartureTimeInSeconds: epoch_20250822_120000_edt + 10},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 120, DepartureTimeInSeconds: epoch_20250822_120000_edt + 130},
 
-	if journeys[0].DepartureTimeInSeconds != epoch_20250823_120000_edt+10 {
-		t.Fatalf(`expected raptor to find departure time %v but got %v`, epoch_20250823_120000_edt+10, journeys[0].DepartureTimeInSeconds)
+						{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
+
+						{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
+					},
+					Mode: RaptorModeArriveBy,
+					/* 2025/08/23 12:00:00PM EDT */
+					TimeInSeconds:        epoch_20250823_120000_edt + 120,
+					MaximumTransfers:     4,
+					AllowTransferHopping: false,
+					Engine:               engine,
+				},
+			)
+
+			if len(journeys) == 0 {
+				t.Fatalf(`did not find any journeys for stop times`)
+			}
+
+			if journeys[0].DepartureTimeInSeconds != epoch_20250823_120000_edt+10 {
+				t.Fatalf(`expected raptor to find departure time %v but got %v`, epoch_20250823_120000_edt+10, journeys[0].DepartureTimeInSeconds)
+			}
+		})
 	}
 }
 
 func TestSimpleForwardRaptor2(t *testing.T) {
-	now := time.Now()
-	journeys := SimpleRaptor(
-		SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
-			FromStops: []GtfsStopStruct[string]{
-				{UniqueID: "Franklin Ave"},
-			},
-			ToStops: []GtfsStopStruct[string]{
-				{UniqueID: "Jay Street"},
-			},
-			Transfers: []GtfsTransferStruct[string]{},
-			StopTimes: []GtfsStopTimeStruct[string]{
-				{
-					UniqueStopID:           "Franklin Ave",
-					UniqueTripID:           "C_NORTH",
-					UniqueTripServiceID:    "C_NORTH",
-					StopSequence:           5,
-					ArrivalTimeInSeconds:   now.Add(10 * time.Second).Unix(),
-					DepartureTimeInSeconds: now.Add(15 * time.Second).Unix(),
-				},
-				{
-					UniqueStopID:           "Jay Street",
-					UniqueTripID:           "C_NORTH",
-					UniqueTripServiceID:    "C_NORTH",
-					StopSequence:           6,
-					ArrivalTimeInSeconds:   now.Add(60 * time.Second).Unix(),
-					DepartureTimeInSeconds: now.Add(65 * time.Second).Unix(),
-				},
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			now := time.Now()
+			journeys := SimpleRaptor(
+				SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+					FromStops: []GtfsStopStruct[string]{
+						{UniqueID: "Franklin Ave"},
+					},
+					ToStops: []GtfsStopStruct[string]{
+						{UniqueID: "Jay Street"},
+					},
+					Transfers: []GtfsTransferStruct[string]{},
+					StopTimes: []GtfsStopTimeStruct[string]{
+						{
+							UniqueStopID:           "Franklin Ave",
+							UniqueTripID:           "C_NORTH",
+							UniqueTripServiceID:    "C_NORTH",
+							StopSequence:           5,
+							ArrivalTimeInSeconds:   now.Add(10 * time.Second).Unix(),
+							DepartureTimeInSeconds: now.Add(15 * time.Second).Unix(),
+						},
+						{
+							UniqueStopID:           "Jay Street",
+							UniqueTripID:           "C_NORTH",
+							UniqueTripServiceID:    "C_NORTH",
+							StopSequence:           6,
+							ArrivalTimeInSeconds:   now.Add(60 * time.Second).Unix(),
+							DepartureTimeInSeconds: now.Add(65 * time.Second).Unix(),
+						},
 
-				{
-					UniqueStopID:           "Franklin Ave",
-					UniqueTripID:           "C_SOUTH",
-					UniqueTripServiceID:    "C_SOUTH",
-					StopSequence:           5,
-					ArrivalTimeInSeconds:   now.Add(15 * time.Second).Unix(),
-					DepartureTimeInSeconds: now.Add(20 * time.Second).Unix(),
+						{
+							UniqueStopID:           "Franklin Ave",
+							UniqueTripID:           "C_SOUTH",
+							UniqueTripServiceID:    "C_SOUTH",
+							StopSequence:           5,
+							ArrivalTimeInSeconds:   now.Add(15 * time.Second).Unix(),
+							DepartureTimeInSeconds: now.Add(20 * time.Second).Unix(),
+						},
+						{
+							UniqueStopID:           "Nostrand",
+							UniqueTripID:           "C_SOUTH",
+							UniqueTripServiceID:    "C_SOUTH",
+							StopSequence:           6,
+							ArrivalTimeInSeconds:   now.Add(30 * time.Second).Unix(),
+							DepartureTimeInSeconds: now.Add(35 * time.Second).Unix(),
+						},
+						{
+							UniqueStopID:           "Nostrand",
+							UniqueTripID:           "A_NORTH",
+							UniqueTripServiceID:    "A_NORTH",
+							StopSequence:           6,
+							ArrivalTimeInSeconds:   now.Add(40 * time.Second).Unix(),
+							DepartureTimeInSeconds: now.Add(45 * time.Second).Unix(),
+						},
+						{
+							UniqueStopID:           "Jay Street",
+							UniqueTripID:           "A_NORTH",
+							UniqueTripServiceID:    "A_NORTH",
+							StopSequence:           7,
+							ArrivalTimeInSeconds:   now.Add(55 * time.Second).Unix(),
+							DepartureTimeInSeconds: now.Add(60 * time.Second).Unix(),
+						},
+					},
+					Mode:                 RaptorModeDepartAt,
+					TimeInSeconds:        now.Unix(),
+					MaximumTransfers:     4,
+					AllowTransferHopping: false,
+					Engine:               engine,
 				},
-				{
-					UniqueStopID:           "Nostrand",
-					UniqueTripID:           "C_SOUTH",
-					UniqueTripServiceID:    "C_SOUTH",
-					StopSequence:           6,
-					ArrivalTimeInSeconds:   now.Add(30 * time.Second).Unix(),
-					DepartureTimeInSeconds: now.Add(35 * time.Second).Unix(),
+			)
+
+			assert.Len(t, journeys, 2, "should return both journey options")
+		})
+	}
+}
+
+func TestSimpleForwardRaptor_MultiTrip(t *testing.T) {
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			var epoch_20250822_120000_edt int64 = 1755878400
+			var epoch_20250823_120000_edt int64 = 1755964800
+			var epoch_20250824_120000_edt int64 = 1756051200
+
+			journeys := SimpleRaptor(
+				SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+					FromStops: []GtfsStopStruct[string]{
+						{UniqueID: "High St"},
+					},
+					ToStops: []GtfsStopStruct[string]{
+						{UniqueID: "Franklin Av"},
+					},
+					Transfers: []GtfsTransferStruct[string]{},
+					StopTimes: []GtfsStopTimeStruct[string]{
+						{UniqueStopID: "High St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250822_120000_edt - 10, DepartureTimeInSeconds: epoch_20250822_120000_edt + 10},
+						{UniqueStopID: "Hoyt St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 120, DepartureTimeInSeconds: epoch_20250822_120000_edt + 130},
+						{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250822", UniqueTripServiceID: "C_20250822", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 125, DepartureTimeInSeconds: epoch_20250822_120000_edt + 135},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250822", UniqueTripServiceID: "C_20250822", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 200, DepartureTimeInSeconds: epoch_20250822_120000_edt + 210},
+
+						{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
+						{UniqueStopID: "Hoyt St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
+						{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 125, DepartureTimeInSeconds: epoch_20250823_120000_edt + 135},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 200, DepartureTimeInSeconds: epoch_20250823_120000_edt + 210},
+
+						{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
+						{UniqueStopID: "Hoyt St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
+						{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250824", UniqueTripServiceID: "C_20250824", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 125, DepartureTimeInSeconds: epoch_20250824_120000_edt + 135},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250824", UniqueTripServiceID: "C_20250824", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 200, DepartureTimeInSeconds: epoch_20250824_120000_edt + 210},
+					},
+					Mode: RaptorModeDepartAt,
+					/* 2025/08/23 12:00:00PM EDT */
+					TimeInSeconds:        epoch_20250823_120000_edt,
+					MaximumTransfers:     4,
+					AllowTransferHopping: false,
+					Engine:               engine,
 				},
-				{
-					UniqueStopID:           "Nostrand",
-					UniqueTripID:           "A_NORTH",
-					UniqueTripServiceID:    "A_NORTH",
-					StopSequence:           6,
-					ArrivalTimeInSeconds:   now.Add(40 * time.Second).Unix(),
-					DepartureTimeInSeconds: now.Add(45 * time.Second).Unix(),
+			)
+
+			if len(journeys) == 0 {
+				t.Fatalf(`did not find any journeys for stop times`)
+			}
+
+			if journeys[0].ArrivalTimeInSeconds != epoch_20250823_120000_edt+200 {
+				t.Fatalf(`expected raptor to find arrival time %v but got %v`, epoch_20250823_120000_edt+200, journeys[0].ArrivalTimeInSeconds)
+			}
+		})
+	}
+}
+
+func TestSimpleForwardRaptor_ManualTransfer(t *testing.T) {
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			var epoch_20250822_120000_edt int64 = 1755878400
+			var epoch_20250823_120000_edt int64 = 1755964800
+			var epoch_20250824_120000_edt int64 = 1756051200
+
+			journeys := SimpleRaptor(
+				SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+					FromStops: []GtfsStopStruct[string]{
+						{UniqueID: "High St"},
+					},
+					ToStops: []GtfsStopStruct[string]{
+						{UniqueID: "Franklin Av"},
+					},
+					Transfers: []GtfsTransferStruct[string]{
+						{FromUniqueStopID: "Jay St", ToUniqueStopID: "Hoyt St", MinimumTransferTimeInSeconds: 0},
+					},
+					StopTimes: []GtfsStopTimeStruct[string]{
+						{UniqueStopID: "High St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250822_120000_edt - 10, DepartureTimeInSeconds: epoch_20250822_120000_edt + 10},
+						{UniqueStopID: "Jay St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 120, DepartureTimeInSeconds: epoch_20250822_120000_edt + 130},
+						{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250822", UniqueTripServiceID: "C_20250822", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 125, DepartureTimeInSeconds: epoch_20250822_120000_edt + 135},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250822", UniqueTripServiceID: "C_20250822", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 200, DepartureTimeInSeconds: epoch_20250822_120000_edt + 210},
+
+						{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
+						{UniqueStopID: "Jay St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
+						{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 125, DepartureTimeInSeconds: epoch_20250823_120000_edt + 135},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 200, DepartureTimeInSeconds: epoch_20250823_120000_edt + 210},
+
+						{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
+						{UniqueStopID: "Jay St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
+						{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250824", UniqueTripServiceID: "C_20250824", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 125, DepartureTimeInSeconds: epoch_20250824_120000_edt + 135},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250824", UniqueTripServiceID: "C_20250824", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 200, DepartureTimeInSeconds: epoch_20250824_120000_edt + 210},
+					},
+					Mode: RaptorModeDepartAt,
+					/* 2025/08/23 12:00:00PM EDT */
+					TimeInSeconds:        epoch_20250823_120000_edt,
+					MaximumTransfers:     4,
+					AllowTransferHopping: false,
+					Engine:               engine,
 				},
-				{
-					UniqueStopID:           "Jay Street",
-					UniqueTripID:           "A_NORTH",
-					UniqueTripServiceID:    "A_NORTH",
-					StopSequence:           7,
-					ArrivalTimeInSeconds:   now.Add(55 * time.Second).Unix(),
-					DepartureTimeInSeconds: now.Add(60 * time.Second).Unix(),
+			)
+
+			if len(journeys) == 0 {
+				t.Fatalf(`did not find any journeys for stop times`)
+			}
+
+			if journeys[0].ArrivalTimeInSeconds != epoch_20250823_120000_edt+200 {
+				t.Fatalf(`expected raptor to find arrival time %v but got %v`, epoch_20250823_120000_edt+200, journeys[0].ArrivalTimeInSeconds)
+			}
+		})
+	}
+}
+
+func TestSimpleRaptor_DirectionalTransfers(t *testing.T) {
+	/* walking from Jay St to Hoyt St is faster than walking back - the arrive by searches should walk the transfer in the direction of the journey */
+	input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops: []GtfsStopStruct[string]{{UniqueID: "High St"}},
+		ToStops:   []GtfsStopStruct[string]{{UniqueID: "Franklin Av"}},
+		Transfers: []GtfsTransferStruct[string]{
+			{FromUniqueStopID: "Hoyt St", ToUniqueStopID: "Jay St", MinimumTransferTimeInSeconds: 600},
+			{FromUniqueStopID: "Jay St", ToUniqueStopID: "Hoyt St", MinimumTransferTimeInSeconds: 300},
+		},
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "High St", UniqueTripID: "A", UniqueTripServiceID: "A", StopSequence: 1, ArrivalTimeInSeconds: 0, DepartureTimeInSeconds: 0},
+			{UniqueStopID: "Jay St", UniqueTripID: "A", UniqueTripServiceID: "A", StopSequence: 2, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "Hoyt St", UniqueTripID: "C", UniqueTripServiceID: "C", StopSequence: 1, ArrivalTimeInSeconds: 1500, DepartureTimeInSeconds: 1500},
+			{UniqueStopID: "Franklin Av", UniqueTripID: "C", UniqueTripServiceID: "C", StopSequence: 2, ArrivalTimeInSeconds: 2500, DepartureTimeInSeconds: 2500},
+		},
+		MaximumTransfers: 4,
+	}
+	for _, engine := range testEngines {
+		for _, mode := range []RaptorMode{RaptorModeDepartAt, RaptorModeArriveBy} {
+			t.Run(fmt.Sprintf("%s_%s", engine, mode), func(t *testing.T) {
+				input.Engine = engine
+				input.Mode = mode
+				input.TimeInSeconds = 0
+				if mode == RaptorModeArriveBy {
+					input.TimeInSeconds = 3000
+				}
+				journeys := SimpleRaptor(input)
+				if !assert.Len(t, journeys, 1) || !assert.Len(t, journeys[0].Legs, 3) {
+					return
+				}
+				legs := journeys[0].Legs
+				assert.Equal(t, -1, legs[0].TransferIndex, "the trip legs should not refer to a transfer")
+				assert.Equal(t, 1, legs[1].TransferIndex, "should refer to the transfer in the direction of the leg")
+				assert.Equal(t, -1, legs[2].TransferIndex, "the trip legs should not refer to a transfer")
+				assert.Equal(t, int64(300), legs[1].ArrivalTimeInSecondsToUniqueStopID-legs[1].DepartureTimeInSecondsFromUniqueStopID)
+			})
+		}
+	}
+
+	/* without a transfer in the other direction the arrive by searches walk the transfer backwards */
+	input.Transfers = []GtfsTransferStruct[string]{{FromUniqueStopID: "Hoyt St", ToUniqueStopID: "Jay St", MinimumTransferTimeInSeconds: 400}}
+	input.Mode = RaptorModeArriveBy
+	input.TimeInSeconds = 3000
+	for _, engine := range testEngines {
+		input.Engine = engine
+		journeys := SimpleRaptor(input)
+		if assert.Len(t, journeys, 1, engine) && assert.Len(t, journeys[0].Legs, 3, engine) {
+			assert.Equal(t, 0, journeys[0].Legs[1].TransferIndex, engine)
+			assert.Equal(t, int64(1100), journeys[0].Legs[1].DepartureTimeInSecondsFromUniqueStopID, engine)
+		}
+	}
+}
+
+func TestSimpleForwardRaptor_NoTransferStart(t *testing.T) {
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			var epoch_20250823_120000_edt int64 = 1755964800
+			var epoch_20250824_120000_edt int64 = 1756051200
+
+			journeys := SimpleRaptor(
+				SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+					FromStops: []GtfsStopStruct[string]{
+						{UniqueID: "SANDS ST/PEARL ST "},
+						{UniqueID: "High St"},
+					},
+					ToStops: []GtfsStopStruct[string]{
+						{UniqueID: "Franklin Av"},
+					},
+					Transfers: []GtfsTransferStruct[string]{
+						{
+							FromUniqueStopID:             "SANDS ST/PEARL ST ",
+							ToUniqueStopID:               "High St",
+							MinimumTransferTimeInSeconds: 0,
+						},
+					},
+					StopTimes: []GtfsStopTimeStruct[string]{
+						{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
+
+						{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
+						{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
+					},
+					Mode: RaptorModeDepartAt,
+					/* 2025/08/23 12:00:00PM EDT */
+					TimeInSeconds:        epoch_20250823_120000_edt,
+					MaximumTransfers:     4,
+					AllowTransferHopping: false,
+					Engine:               engine,
 				},
-			},
-			Mode:                 RaptorModeDepartAt,
-			TimeInSeconds:        now.Unix(),
-			MaximumTransfers:     4,
-			AllowTransferHopping: false,
+			)
+
+			if len(journeys) == 0 {
+				t.Fatalf(`did not find any journeys for stop times`)
+			}
+
+			if len(journeys) > 1 {
+				t.Fatalf(`expected to find 1 journey - should not allow starting at Pearl St and then walking to High St`)
+			}
+
+			if journeys[0].ArrivalTimeInSeconds != epoch_20250823_120000_edt+120 {
+				t.Fatalf(`expected raptor to find arrival time %v but got %v`, epoch_20250823_120000_edt+120, journeys[0].ArrivalTimeInSeconds)
+			}
+		})
+	}
+}
+
+func TestSimpleRaptor_ViaStop(t *testing.T) {
+	for _, engine := range testEngines {
+		for _, mode := range []RaptorMode{RaptorModeDepartAt, RaptorModeArriveBy} {
+			t.Run(string(engine)+"_"+string(mode), func(t *testing.T) {
+				var epoch_20250823_120000_edt int64 = 1755964800
+
+				/* a train every 5 minutes from High St to Franklin Av via Jay St */
+				stop_times := []GtfsStopTimeStruct[string]{}
+				for _, offset := range []int64{0, 300, 600} {
+					trip_id := fmt.Sprintf("A_%d", offset)
+					stop_times = append(stop_times,
+						GtfsStopTimeStruct[string]{UniqueStopID: "High St", UniqueTripID: trip_id, UniqueTripServiceID: trip_id, StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt + offset, DepartureTimeInSeconds: epoch_20250823_120000_edt + offset + 10},
+						GtfsStopTimeStruct[string]{UniqueStopID: "Jay St", UniqueTripID: trip_id, UniqueTripServiceID: trip_id, StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + offset + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + offset + 130},
+						GtfsStopTimeStruct[string]{UniqueStopID: "Franklin Av", UniqueTripID: trip_id, UniqueTripServiceID: trip_id, StopSequence: 7, ArrivalTimeInSeconds: epoch_20250823_120000_edt + offset + 200, DepartureTimeInSeconds: epoch_20250823_120000_edt + offset + 210},
+					)
+				}
+				sort.SliceStable(stop_times, func(i, j int) bool {
+					return stop_times[i].ArrivalTimeInSeconds < stop_times[j].ArrivalTimeInSeconds
+				})
+
+				time_in_seconds := epoch_20250823_120000_edt
+				if mode == RaptorModeArriveBy {
+					time_in_seconds = epoch_20250823_120000_edt + 500
+				}
+
+				journeys := SimpleRaptor(
+					SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+						FromStops: []GtfsStopStruct[string]{
+							{UniqueID: "High St"},
+						},
+						ToStops: []GtfsStopStruct[string]{
+							{UniqueID: "Franklin Av"},
+						},
+						ViaStops: []RaptorViaStop[string, GtfsStopStruct[string]]{
+							{Stop: GtfsStopStruct[string]{UniqueID: "Jay St"}, MinimumDwellTimeInSeconds: 250},
+						},
+						Transfers:            []GtfsTransferStruct[string]{},
+						StopTimes:            stop_times,
+						Mode:                 mode,
+						TimeInSeconds:        time_in_seconds,
+						MaximumTransfers:     4,
+						AllowTransferHopping: false,
+						Engine:               engine,
+					},
+				)
+
+				if len(journeys) == 0 {
+					t.Fatalf(`did not find any journeys for stop times`)
+				}
+
+				/* the dwell time at Jay St means we have to take the next train */
+				assert.Equal(t, epoch_20250823_120000_edt+10, journeys[0].DepartureTimeInSeconds)
+				assert.Equal(t, epoch_20250823_120000_edt+500, journeys[0].ArrivalTimeInSeconds)
+				assert.Len(t, journeys[0].Legs, 2)
+				assert.Equal(t, "A_0", journeys[0].Legs[0].ViaTrip.UniqueTripID)
+				assert.Equal(t, "Jay St", journeys[0].Legs[0].ToUniqueStopID)
+				assert.Equal(t, "A_300", journeys[0].Legs[1].ViaTrip.UniqueTripID)
+			})
+		}
+	}
+}
+
+func TestSimpleRaptor_ViaStopTransfers(t *testing.T) {
+	getTripIds := func(journeys []Journey[string]) [][]string {
+		trip_ids := [][]string{}
+		for _, journey := range journeys {
+			journey_trip_ids := []string{}
+			for _, leg := range journey.Legs {
+				if leg.ViaTrip != nil {
+					journey_trip_ids = append(journey_trip_ids, leg.ViaTrip.UniqueTripID)
+				}
+			}
+			trip_ids = append(trip_ids, journey_trip_ids)
+		}
+		return trip_ids
+	}
+
+	for _, engine := range testEngines {
+		for _, mode := range []RaptorMode{RaptorModeDepartAt, RaptorModeArriveBy} {
+			t.Run(string(engine)+"_"+string(mode), func(t *testing.T) {
+				/* T1 goes directly from A to V - T2 and T3 go through X and arrive at V earlier (but leave A later) - T4 goes on from V to Z */
+				input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+					StopTimes: []GtfsStopTimeStruct[string]{
+						{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+						{UniqueStopID: "A", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 1, ArrivalTimeInSeconds: 1050, DepartureTimeInSeconds: 1050},
+						{UniqueStopID: "X", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 2, ArrivalTimeInSeconds: 1070, DepartureTimeInSeconds: 1070},
+						{UniqueStopID: "X", UniqueTripID: "T3", UniqueTripServiceID: "T3", StopSequence: 1, ArrivalTimeInSeconds: 1080, DepartureTimeInSeconds: 1080},
+						{UniqueStopID: "V", UniqueTripID: "T3", UniqueTripServiceID: "T3", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+						{UniqueStopID: "V", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 2, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+						{UniqueStopID: "V", UniqueTripID: "T4", UniqueTripServiceID: "T4", StopSequence: 1, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+						{UniqueStopID: "Z", UniqueTripID: "T4", UniqueTripServiceID: "T4", StopSequence: 2, ArrivalTimeInSeconds: 1400, DepartureTimeInSeconds: 1400},
+					},
+					FromStops:        []GtfsStopStruct[string]{{UniqueID: "A"}},
+					ToStops:          []GtfsStopStruct[string]{{UniqueID: "Z"}},
+					ViaStops:         []RaptorViaStop[string, GtfsStopStruct[string]]{{Stop: GtfsStopStruct[string]{UniqueID: "V"}}},
+					Mode:             mode,
+					TimeInSeconds:    900,
+					MaximumTransfers: 2,
+					Engine:           engine,
+				}
+				if mode == RaptorModeArriveBy {
+					input.TimeInSeconds = 1500
+				}
+
+				/* the earliest arrival at (or latest departure from) V uses up all the trips - the journey with fewer trips is still searched from V */
+				assert.Equal(t, [][]string{{"T1", "T4"}}, getTripIds(SimpleRaptor(input)))
+
+				input.MaximumTransfers = 1
+				assert.Empty(t, SimpleRaptor(input), "should share the maximum transfers between the parts")
+
+				input.MaximumTransfers = 3
+				if mode == RaptorModeDepartAt {
+					assert.Equal(t, [][]string{{"T1", "T4"}}, getTripIds(SimpleRaptor(input)), "should not keep the journey with more trips arriving at the same time")
+				} else {
+					assert.ElementsMatch(t, [][]string{{"T1", "T4"}, {"T2", "T3", "T4"}}, getTripIds(SimpleRaptor(input)))
+				}
+
+				/* a via stop which is also the origin or the destination is passed without any legs */
+				input.MaximumTransfers = 1
+				input.ToStops = []GtfsStopStruct[string]{{UniqueID: "V"}}
+				for via_stop_id, departure_time := range map[string]TimestampInSeconds{"A": 970, "V": 1000} {
+					input.ViaStops = []RaptorViaStop[string, GtfsStopStruct[string]]{{Stop: GtfsStopStruct[string]{UniqueID: via_stop_id}, MinimumDwellTimeInSeconds: 30}}
+					journeys := SimpleRaptor(input)
+					assert.Equal(t, [][]string{{"T1"}}, getTripIds(journeys), via_stop_id)
+					if len(journeys) == 1 && mode == RaptorModeDepartAt {
+						assert.Equal(t, departure_time, journeys[0].DepartureTimeInSeconds, "should only dwell at the origin instead of waiting from the search time")
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSimpleForwardRaptor_BannedAndUnpreferred(t *testing.T) {
+	now := time.Now()
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops: []GtfsStopStruct[string]{
+			{UniqueID: "Franklin Ave"},
+		},
+		ToStops: []GtfsStopStruct[string]{
+			{UniqueID: "Jay Street"},
 		},
-	)
+		Transfers: []GtfsTransferStruct[string]{},
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "Franklin Ave", UniqueTripID: "C_NORTH", UniqueTripServiceID: "C_NORTH", StopSequence: 5, ArrivalTimeInSeconds: now.Add(10 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(15 * time.Second).Unix()},
+			{UniqueStopID: "Franklin Ave", UniqueTripID: "C_SOUTH", UniqueTripServiceID: "C_SOUTH", StopSequence: 5, ArrivalTimeInSeconds: now.Add(15 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(20 * time.Second).Unix()},
+			{UniqueStopID: "Nostrand", UniqueTripID: "C_SOUTH", UniqueTripServiceID: "C_SOUTH", StopSequence: 6, ArrivalTimeInSeconds: now.Add(30 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(35 * time.Second).Unix()},
+			{UniqueStopID: "Nostrand", UniqueTripID: "A_NORTH", UniqueTripServiceID: "A_NORTH", StopSequence: 6, ArrivalTimeInSeconds: now.Add(40 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(45 * time.Second).Unix()},
+			{UniqueStopID: "Jay Street", UniqueTripID: "A_NORTH", UniqueTripServiceID: "A_NORTH", StopSequence: 7, ArrivalTimeInSeconds: now.Add(55 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(60 * time.Second).Unix()},
+			{UniqueStopID: "Jay Street", UniqueTripID: "C_NORTH", UniqueTripServiceID: "C_NORTH", StopSequence: 6, ArrivalTimeInSeconds: now.Add(60 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(65 * time.Second).Unix()},
+		},
+		TripsByUniqueTripId: map[string]GtfsTrip[string]{
+			"C_NORTH": GtfsTripStruct[string]{UniqueID: "C_NORTH", UniqueRouteID: "C"},
+			"C_SOUTH": GtfsTripStruct[string]{UniqueID: "C_SOUTH", UniqueRouteID: "C"},
+			"A_NORTH": GtfsTripStruct[string]{UniqueID: "A_NORTH", UniqueRouteID: "A"},
+		},
+		RoutesByUniqueRouteId: map[string]GtfsRoute[string]{
+			"A": GtfsRouteStruct[string]{UniqueID: "A", UniqueAgencyID: "MTA"},
+			"C": GtfsRouteStruct[string]{UniqueID: "C", UniqueAgencyID: "MTA"},
+		},
+		Mode:                 RaptorModeDepartAt,
+		TimeInSeconds:        now.Unix(),
+		MaximumTransfers:     4,
+		AllowTransferHopping: false,
+	}
+
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			input := base_input
+			input.Engine = engine
+			assert.Len(t, SimpleRaptor(input), 2, "should return both journey options without filters")
 
-	assert.Len(t, journeys, 2, "should return both journey options")
+			input.BannedUniqueRouteIds = []string{"A"}
+			journeys := SimpleRaptor(input)
+			assert.Len(t, journeys, 1, "should not use the banned A route")
+			assert.Equal(t, "C_NORTH", journeys[0].Legs[0].ViaTrip.UniqueTripID)
+
+			input.BannedUniqueRouteIds = nil
+			input.BannedUniqueStopIds = []string{"Nostrand"}
+			assert.Len(t, SimpleRaptor(input), 1, "should not transfer at the closed Nostrand stop")
+
+			input.BannedUniqueStopIds = nil
+			input.BannedUniqueAgencyIds = []string{"MTA"}
+			assert.Len(t, SimpleRaptor(input), 0, "should not use any trips of the banned agency")
+
+			input.BannedUniqueAgencyIds = nil
+			input.UnpreferredUniqueRouteIds = []string{"A"}
+			input.RoutePreferencePenaltyInSeconds = 60
+			journeys = SimpleRaptor(input)
+			assert.Len(t, journeys, 1, "the 5 second improvement should not outweigh the unpreferred route penalty")
+			assert.Equal(t, "C_NORTH", journeys[0].Legs[0].ViaTrip.UniqueTripID)
+		})
+	}
 }
 
-func TestSimpleForwardRaptor_MultiTrip(t *testing.T) {
-	var epoch_20250822_120000_edt int64 = 1755878400
-	var epoch_20250823_120000_edt int64 = 1755964800
-	var epoch_20250824_120000_edt int64 = 1756051200
-
-	journeys := SimpleRaptor(
-		SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
-			FromStops: []GtfsStopStruct[string]{
-				{UniqueID: "High St"},
-			},
-			ToStops: []GtfsStopStruct[string]{
-				{UniqueID: "Franklin Av"},
-			},
-			Transfers: []GtfsTransferStruct[string]{},
-			StopTimes: []GtfsStopTimeStruct[string]{
-				{UniqueStopID: "High St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250822_120000_edt - 10, DepartureTimeInSeconds: epoch_20250822_120000_edt + 10},
-				{UniqueStopID: "Hoyt St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 120, DepartureTimeInSeconds: epoch_20250822_120000_edt + 130},
-				{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250822", UniqueTripServiceID: "C_20250822", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 125, DepartureTimeInSeconds: epoch_20250822_120000_edt + 135},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250822", UniqueTripServiceID: "C_20250822", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 200, DepartureTimeInSeconds: epoch_20250822_120000_edt + 210},
-
-				{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
-				{UniqueStopID: "Hoyt St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
-				{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 125, DepartureTimeInSeconds: epoch_20250823_120000_edt + 135},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 200, DepartureTimeInSeconds: epoch_20250823_120000_edt + 210},
-
-				{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
-				{UniqueStopID: "Hoyt St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
-				{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250824", UniqueTripServiceID: "C_20250824", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 125, DepartureTimeInSeconds: epoch_20250824_120000_edt + 135},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250824", UniqueTripServiceID: "C_20250824", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 200, DepartureTimeInSeconds: epoch_20250824_120000_edt + 210},
-			},
-			Mode: RaptorModeDepartAt,
-			/* 2025/08/23 12:00:00PM EDT */
-			TimeInSeconds:        epoch_20250823_120000_edt,
-			MaximumTransfers:     4,
-			AllowTransferHopping: false,
+func TestSimpleForwardRaptor_AllowedTransitModes(t *testing.T) {
+	now := time.Now()
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops: []GtfsStopStruct[string]{
+			{UniqueID: "Franklin Ave"},
+		},
+		ToStops: []GtfsStopStruct[string]{
+			{UniqueID: "Jay Street"},
+		},
+		Transfers: []GtfsTransferStruct[string]{
+			{FromUniqueStopID: "Nostrand", ToUniqueStopID: "Nostrand Bus", MinimumTransferTimeInSeconds: 1},
+		},
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "Franklin Ave", UniqueTripID: "C_NORTH", UniqueTripServiceID: "C_NORTH", StopSequence: 5, ArrivalTimeInSeconds: now.Add(10 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(15 * time.Second).Unix()},
+			{UniqueStopID: "Franklin Ave", UniqueTripID: "C_SOUTH", UniqueTripServiceID: "C_SOUTH", StopSequence: 5, ArrivalTimeInSeconds: now.Add(15 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(20 * time.Second).Unix()},
+			{UniqueStopID: "Nostrand", UniqueTripID: "C_SOUTH", UniqueTripServiceID: "C_SOUTH", StopSequence: 6, ArrivalTimeInSeconds: now.Add(30 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(35 * time.Second).Unix()},
+			{UniqueStopID: "Nostrand Bus", UniqueTripID: "B44", UniqueTripServiceID: "B44", StopSequence: 1, ArrivalTimeInSeconds: now.Add(40 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(45 * time.Second).Unix()},
+			{UniqueStopID: "Jay Street", UniqueTripID: "B44", UniqueTripServiceID: "B44", StopSequence: 2, ArrivalTimeInSeconds: now.Add(55 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(60 * time.Second).Unix()},
+			{UniqueStopID: "Jay Street", UniqueTripID: "C_NORTH", UniqueTripServiceID: "C_NORTH", StopSequence: 6, ArrivalTimeInSeconds: now.Add(60 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(65 * time.Second).Unix()},
+		},
+		TripsByUniqueTripId: map[string]GtfsTrip[string]{
+			"C_NORTH": GtfsTripStruct[string]{UniqueID: "C_NORTH", UniqueRouteID: "C"},
+			"C_SOUTH": GtfsTripStruct[string]{UniqueID: "C_SOUTH", UniqueRouteID: "C"},
+			"B44":     GtfsTripStruct[string]{UniqueID: "B44", UniqueRouteID: "B44"},
+		},
+		RoutesByUniqueRouteId: map[string]GtfsRoute[string]{
+			"C": GtfsRouteStruct[string]{UniqueID: "C", UniqueAgencyID: "MTA", RouteType: GtfsRouteTypeSubway},
+			/* an extended route type - 702 is an express bus service */
+			"B44": GtfsRouteStruct[string]{UniqueID: "B44", UniqueAgencyID: "MTA", RouteType: 702},
 		},
-	)
+		Mode:                 RaptorModeDepartAt,
+		TimeInSeconds:        now.Unix(),
+		MaximumTransfers:     4,
+		AllowTransferHopping: false,
+	}
+
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			input := base_input
+			input.Engine = engine
+			journeys := SimpleRaptor(input)
+			assert.Len(t, journeys, 2, "should return both journey options without a mode filter")
+			for _, journey := range journeys {
+				if len(journey.Legs) == 3 {
+					assert.Equal(t, []TransitMode{TransitModeSubway, TransitModeWalk, TransitModeBus}, []TransitMode{journey.Legs[0].Mode, journey.Legs[1].Mode, journey.Legs[2].Mode})
+				} else {
+					assert.Equal(t, TransitModeSubway, journey.Legs[0].Mode)
+				}
+			}
+
+			input.AllowedTransitModes = []TransitMode{TransitModeSubway}
+			journeys = SimpleRaptor(input)
+			assert.Len(t, journeys, 1, "should not use the bus")
+			assert.Equal(t, "C_NORTH", journeys[0].Legs[0].ViaTrip.UniqueTripID)
+
+			input.AllowedTransitModes = []TransitMode{TransitModeBus}
+			assert.Len(t, SimpleRaptor(input), 0, "should not use the subway")
+
+			/* without route information the mode of the bus is unknown */
+			input.TripsByUniqueTripId = map[string]GtfsTrip[string]{
+				"C_NORTH": base_input.TripsByUniqueTripId["C_NORTH"],
+				"C_SOUTH": base_input.TripsByUniqueTripId["C_SOUTH"],
+			}
+			input.AllowedTransitModes = []TransitMode{TransitModeSubway, TransitModeBus}
+			journeys = SimpleRaptor(input)
+			assert.Len(t, journeys, 1, "should not use the trip of which the mode is unknown")
+			assert.Equal(t, "C_NORTH", journeys[0].Legs[0].ViaTrip.UniqueTripID)
 
-	if len(journeys) == 0 {
-		t.Fatalf(`did not find any journeys for stop times`)
+			input.AllowedTransitModes = nil
+			input.BannedUniqueRouteIds = []string{"B44"}
+			assert.Len(t, SimpleRaptor(input), 2, "should allow the trip without route information when only banning routes")
+		})
+	}
+}
+
+func TestGetTransitModeForRouteType(t *testing.T) {
+	assert.Equal(t, TransitModeRail, GetTransitModeForRouteType(GtfsRouteTypeRail))
+	assert.Equal(t, TransitModeTrolleybus, GetTransitModeForRouteType(GtfsRouteTypeTrolleybus))
+	assert.Equal(t, TransitModeRail, GetTransitModeForRouteType(109))
+	assert.Equal(t, TransitModeSubway, GetTransitModeForRouteType(401))
+	assert.Equal(t, TransitModeMonorail, GetTransitModeForRouteType(405))
+	assert.Equal(t, TransitModeBus, GetTransitModeForRouteType(200))
+	assert.Equal(t, TransitModeFerry, GetTransitModeForRouteType(1200))
+	assert.Equal(t, TransitModeOther, GetTransitModeForRouteType(1702))
+}
+
+func TestSimpleRaptor_WheelchairAccessibility(t *testing.T) {
+	now := time.Now()
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops: []GtfsStopStruct[string]{
+			{UniqueID: "Franklin Ave"},
+		},
+		ToStops: []GtfsStopStruct[string]{
+			{UniqueID: "Jay Street"},
+		},
+		Transfers: []GtfsTransferStruct[string]{
+			{FromUniqueStopID: "Nostrand", ToUniqueStopID: "Nostrand Bus", MinimumTransferTimeInSeconds: 1, WheelchairAccessible: GtfsWheelchairAccessibilityAccessible},
+			{FromUniqueStopID: "Nostrand Bus", ToUniqueStopID: "Nostrand", MinimumTransferTimeInSeconds: 1, WheelchairAccessible: GtfsWheelchairAccessibilityAccessible},
+		},
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "Franklin Ave", UniqueTripID: "C_NORTH", UniqueTripServiceID: "C_NORTH", StopSequence: 5, ArrivalTimeInSeconds: now.Add(10 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(15 * time.Second).Unix()},
+			{UniqueStopID: "Franklin Ave", UniqueTripID: "C_SOUTH", UniqueTripServiceID: "C_SOUTH", StopSequence: 5, ArrivalTimeInSeconds: now.Add(15 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(20 * time.Second).Unix()},
+			{UniqueStopID: "Nostrand", UniqueTripID: "C_SOUTH", UniqueTripServiceID: "C_SOUTH", StopSequence: 6, ArrivalTimeInSeconds: now.Add(30 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(35 * time.Second).Unix()},
+			{UniqueStopID: "Nostrand Bus", UniqueTripID: "B44", UniqueTripServiceID: "B44", StopSequence: 1, ArrivalTimeInSeconds: now.Add(40 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(45 * time.Second).Unix()},
+			{UniqueStopID: "Jay Street", UniqueTripID: "B44", UniqueTripServiceID: "B44", StopSequence: 2, ArrivalTimeInSeconds: now.Add(55 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(60 * time.Second).Unix()},
+			{UniqueStopID: "Jay Street", UniqueTripID: "C_NORTH", UniqueTripServiceID: "C_NORTH", StopSequence: 6, ArrivalTimeInSeconds: now.Add(60 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(65 * time.Second).Unix()},
+		},
+		StopsByUniqueStopId: map[string]GtfsStopStruct[string]{
+			"Franklin Ave": {UniqueID: "Franklin Ave", WheelchairBoarding: GtfsWheelchairAccessibilityAccessible},
+			"Nostrand":     {UniqueID: "Nostrand", WheelchairBoarding: GtfsWheelchairAccessibilityAccessible},
+			"Nostrand Bus": {UniqueID: "Nostrand Bus", WheelchairBoarding: GtfsWheelchairAccessibilityUnknown},
+			"Jay Street":   {UniqueID: "Jay Street", WheelchairBoarding: GtfsWheelchairAccessibilityAccessible},
+		},
+		TripsByUniqueTripId: map[string]GtfsTrip[string]{
+			/* the direct trip is not accessible - only the trip via the bus is */
+			"C_NORTH": GtfsTripStruct[string]{UniqueID: "C_NORTH", UniqueRouteID: "C", WheelchairAccessible: GtfsWheelchairAccessibilityNotAccessible},
+			"C_SOUTH": GtfsTripStruct[string]{UniqueID: "C_SOUTH", UniqueRouteID: "C", WheelchairAccessible: GtfsWheelchairAccessibilityAccessible},
+			"B44":     GtfsTripStruct[string]{UniqueID: "B44", UniqueRouteID: "B44", WheelchairAccessible: GtfsWheelchairAccessibilityAccessible},
+		},
+		MaximumTransfers:     4,
+		AllowTransferHopping: false,
 	}
 
-	if journeys[0].ArrivalTimeInSeconds != epoch_20250823_120000_edt+200 {
-		t.Fatalf(`expected raptor to find arrival time %v but got %v`, epoch_20250823_120000_edt+200, journeys[0].ArrivalTimeInSeconds)
+	for _, engine := range testEngines {
+		for _, mode := range []RaptorMode{RaptorModeDepartAt, RaptorModeArriveBy} {
+			t.Run(fmt.Sprintf("%s/%s", engine, mode), func(t *testing.T) {
+				input := base_input
+				input.Engine = engine
+				input.Mode = mode
+				input.TimeInSeconds = now.Unix()
+				if mode == RaptorModeArriveBy {
+					input.TimeInSeconds = now.Add(70 * time.Second).Unix()
+				}
+				assert.Len(t, SimpleRaptor(input), 2, "should return both journey options without a profile")
+
+				input.AccessibilityProfile = RaptorAccessibilityProfile{Wheelchair: true}
+				assert.Len(t, SimpleRaptor(input), 0, "should not board at the bus stop without accessibility information")
+
+				input.AccessibilityProfile.AllowUnknownAccessibility = true
+				journeys := SimpleRaptor(input)
+				assert.Len(t, journeys, 1, "should only use the accessible trips")
+				assert.Equal(t, "C_SOUTH", journeys[0].Legs[0].ViaTrip.UniqueTripID)
+
+				input.Transfers = []GtfsTransferStruct[string]{
+					{FromUniqueStopID: "Nostrand", ToUniqueStopID: "Nostrand Bus", MinimumTransferTimeInSeconds: 1, WheelchairAccessible: GtfsWheelchairAccessibilityNotAccessible},
+					{FromUniqueStopID: "Nostrand Bus", ToUniqueStopID: "Nostrand", MinimumTransferTimeInSeconds: 1, WheelchairAccessible: GtfsWheelchairAccessibilityNotAccessible},
+				}
+				assert.Len(t, SimpleRaptor(input), 0, "should not use the inaccessible transfer")
+			})
+		}
 	}
 }
 
-func TestSimpleForwardRaptor_ManualTransfer(t *testing.T) {
-	var epoch_20250822_120000_edt int64 = 1755878400
-	var epoch_20250823_120000_edt int64 = 1755964800
-	var epoch_20250824_120000_edt int64 = 1756051200
-
-	journeys := SimpleRaptor(
-		SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
-			FromStops: []GtfsStopStruct[string]{
-				{UniqueID: "High St"},
-			},
-			ToStops: []GtfsStopStruct[string]{
-				{UniqueID: "Franklin Av"},
-			},
-			Transfers: []GtfsTransferStruct[string]{
-				{FromUniqueStopID: "Jay St", ToUniqueStopID: "Hoyt St", MinimumTransferTimeInSeconds: 0},
-			},
-			StopTimes: []GtfsStopTimeStruct[string]{
-				{UniqueStopID: "High St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250822_120000_edt - 10, DepartureTimeInSeconds: epoch_20250822_120000_edt + 10},
-				{UniqueStopID: "Jay St", UniqueTripID: "A_20250822", UniqueTripServiceID: "A_20250822", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 120, DepartureTimeInSeconds: epoch_20250822_120000_edt + 130},
-				{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250822", UniqueTripServiceID: "C_20250822", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 125, DepartureTimeInSeconds: epoch_20250822_120000_edt + 135},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250822", UniqueTripServiceID: "C_20250822", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250822_120000_edt + 200, DepartureTimeInSeconds: epoch_20250822_120000_edt + 210},
-
-				{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
-				{UniqueStopID: "Jay St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
-				{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 125, DepartureTimeInSeconds: epoch_20250823_120000_edt + 135},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 200, DepartureTimeInSeconds: epoch_20250823_120000_edt + 210},
-
-				{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
-				{UniqueStopID: "Jay St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
-				{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250824", UniqueTripServiceID: "C_20250824", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 125, DepartureTimeInSeconds: epoch_20250824_120000_edt + 135},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250824", UniqueTripServiceID: "C_20250824", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 200, DepartureTimeInSeconds: epoch_20250824_120000_edt + 210},
-			},
-			Mode: RaptorModeDepartAt,
-			/* 2025/08/23 12:00:00PM EDT */
-			TimeInSeconds:        epoch_20250823_120000_edt,
-			MaximumTransfers:     4,
-			AllowTransferHopping: false,
+/* a stop and transfer only implementing the required methods - without any accessibility information */
+type minimalTestStop struct {
+	UniqueID string
+}
+
+func (s minimalTestStop) GetUniqueID() string {
+	return s.UniqueID
+}
+
+type minimalTestTransfer struct {
+	FromUniqueStopID string
+	ToUniqueStopID   string
+}
+
+func (t minimalTestTransfer) GetFromUniqueStopID() string {
+	return t.FromUniqueStopID
+}
+
+func (t minimalTestTransfer) GetToUniqueStopID() string {
+	return t.ToUniqueStopID
+}
+
+func (t minimalTestTransfer) GetMinimumTransferTimeInSeconds() int {
+	return 60
+}
+
+func TestPreparedRaptorInput_UnknownWheelchairAccessibility(t *testing.T) {
+	input := SimpleRaptorInput[string, minimalTestStop, minimalTestTransfer, GtfsStopTimeStruct[string]]{
+		StopsByUniqueStopId:  map[string]minimalTestStop{"A": {UniqueID: "A"}, "B": {UniqueID: "B"}},
+		AccessibilityProfile: RaptorAccessibilityProfile{Wheelchair: true},
+	}
+	transfer := minimalTestTransfer{FromUniqueStopID: "A", ToUniqueStopID: "B"}
+	prepared_input := PrepareRaptorInput(input)
+	assert.False(t, prepared_input.IsStopAllowed("A"), "should treat the stop accessibility as unknown")
+	assert.False(t, prepared_input.IsTransferAllowed(transfer), "should treat the transfer accessibility as unknown")
+
+	input.AccessibilityProfile.AllowUnknownAccessibility = true
+	prepared_input = PrepareRaptorInput(input)
+	assert.True(t, prepared_input.IsStopAllowed("A"))
+	assert.True(t, prepared_input.IsTransferAllowed(transfer))
+}
+
+func TestSimpleRaptor_BikeProfile(t *testing.T) {
+	now := time.Now()
+	stops := []GtfsStopStruct[string]{
+		{UniqueID: "Franklin Ave", Latitude: 40.681, Longitude: -73.956, HasCoordinates: true},
+		{UniqueID: "Nostrand", Latitude: 40.680, Longitude: -73.950, HasCoordinates: true},
+		/* roughly 450 meters from Nostrand */
+		{UniqueID: "Nostrand Bus", Latitude: 40.684, Longitude: -73.950, HasCoordinates: true},
+		{UniqueID: "Jay Street", Latitude: 40.692, Longitude: -73.987, HasCoordinates: true},
+	}
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops: []GtfsStopStruct[string]{stops[0]},
+		ToStops:   []GtfsStopStruct[string]{stops[3]},
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "Franklin Ave", UniqueTripID: "C_NORTH", UniqueTripServiceID: "C_NORTH", StopSequence: 5, ArrivalTimeInSeconds: now.Add(110 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(115 * time.Second).Unix()},
+			{UniqueStopID: "Franklin Ave", UniqueTripID: "C_SOUTH", UniqueTripServiceID: "C_SOUTH", StopSequence: 5, ArrivalTimeInSeconds: now.Add(115 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(120 * time.Second).Unix()},
+			{UniqueStopID: "Nostrand", UniqueTripID: "C_SOUTH", UniqueTripServiceID: "C_SOUTH", StopSequence: 6, ArrivalTimeInSeconds: now.Add(130 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(135 * time.Second).Unix()},
+			{UniqueStopID: "Nostrand Bus", UniqueTripID: "B44", UniqueTripServiceID: "B44", StopSequence: 1, ArrivalTimeInSeconds: now.Add(300 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(305 * time.Second).Unix()},
+			{UniqueStopID: "Jay Street", UniqueTripID: "B44", UniqueTripServiceID: "B44", StopSequence: 2, ArrivalTimeInSeconds: now.Add(900 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(905 * time.Second).Unix()},
+			{UniqueStopID: "Jay Street", UniqueTripID: "C_NORTH", UniqueTripServiceID: "C_NORTH", StopSequence: 6, ArrivalTimeInSeconds: now.Add(1000 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(1005 * time.Second).Unix()},
 		},
-	)
+		TripsByUniqueTripId: map[string]GtfsTrip[string]{
+			"C_NORTH": GtfsTripStruct[string]{UniqueID: "C_NORTH", UniqueRouteID: "C", BikesAllowed: GtfsBikesAllowedNotAllowed},
+			"C_SOUTH": GtfsTripStruct[string]{UniqueID: "C_SOUTH", UniqueRouteID: "C", BikesAllowed: GtfsBikesAllowedAllowed},
+			"B44":     GtfsTripStruct[string]{UniqueID: "B44", UniqueRouteID: "B44", BikesAllowed: GtfsBikesAllowedAllowed},
+		},
+		Mode:                                   RaptorModeDepartAt,
+		TimeInSeconds:                          now.Unix(),
+		MaximumTransfers:                       4,
+		AllowTransferHopping:                   false,
+		AccessDurationsInSecondsByUniqueStopId: map[string]int{"Franklin Ave": 100},
+		EgressDurationsInSecondsByUniqueStopId: map[string]int{"Jay Street": 50},
+	}
+
+	for _, engine := range testEngines {
+		t.Run(string(engine), func(t *testing.T) {
+			input := base_input
+			input.Engine = engine
+			/* walking the 450 meters takes too long to make the bus */
+			input.Transfers = GenerateTransfersFromCoordinates[string](stops, 500, input.GetTransferSpeedInMetersPerSecond())
+			journeys := SimpleRaptor(input)
+			assert.Len(t, journeys, 1, "should only be able to make the direct trip when walking")
+			assert.Equal(t, "C_NORTH", journeys[0].Legs[0].ViaTrip.UniqueTripID)
+			assert.Equal(t, now.Add(15*time.Second).Unix(), journeys[0].DepartureTimeInSeconds, "should include the access duration")
+			assert.Equal(t, now.Add(1050*time.Second).Unix(), journeys[0].ArrivalTimeInSeconds, "should include the egress duration")
+			assert.False(t, journeys[0].Legs[0].WithBike)
 
-	if len(journeys) == 0 {
-		t.Fatalf(`did not find any journeys for stop times`)
+			input.BikeProfile = RaptorBikeProfile{Enabled: true}
+			input.Transfers = GenerateTransfersFromCoordinates[string](stops, 500, input.GetTransferSpeedInMetersPerSecond())
+			journeys = SimpleRaptor(input)
+			assert.Len(t, journeys, 1, "should cycle to the bus and not board the trip without bikes")
+			assert.Equal(t, "C_SOUTH", journeys[0].Legs[0].ViaTrip.UniqueTripID)
+			assert.Equal(t, now.Add(950*time.Second).Unix(), journeys[0].ArrivalTimeInSeconds)
+			for _, leg := range journeys[0].Legs {
+				assert.True(t, leg.WithBike, "should indicate the bike is carried")
+			}
+		})
 	}
+}
 
-	if journeys[0].ArrivalTimeInSeconds != epoch_20250823_120000_edt+200 {
-		t.Fatalf(`expected raptor to find arrival time %v but got %v`, epoch_20250823_120000_edt+200, journeys[0].ArrivalTimeInSeconds)
+func TestGenerateTransfersFromCoordinates(t *testing.T) {
+	stops := []GtfsStopStruct[string]{
+		{UniqueID: "A", Latitude: 40.680, Longitude: -73.950, HasCoordinates: true},
+		{UniqueID: "B", Latitude: 40.684, Longitude: -73.950, HasCoordinates: true},
+		{UniqueID: "C", Latitude: 40.700, Longitude: -73.950, HasCoordinates: true},
+		/* stops without coordinates are skipped */
+		{UniqueID: "D"},
 	}
+	transfers := GenerateTransfersFromCoordinates[string](stops, 500, DefaultWalkingSpeedInMetersPerSecond)
+	assert.Len(t, transfers, 2, "should only generate transfers between A and B")
+	assert.Equal(t, transfers[0].MinimumTransferTimeInSeconds, transfers[1].MinimumTransferTimeInSeconds)
+	assert.Equal(t, 318, transfers[0].MinimumTransferTimeInSeconds)
+
+	durations := GetDurationsToStopsFromCoordinates[string](40.682, -73.950, stops, 500, DefaultCyclingSpeedInMetersPerSecond)
+	assert.Equal(t, map[string]int{"A": 50, "B": 50}, durations)
+
+	/* (0, 0) is a valid coordinate when it is set */
+	null_island_stops := []GtfsStopStruct[string]{{UniqueID: "E", HasCoordinates: true}, {UniqueID: "F", Latitude: 0.001, HasCoordinates: true}}
+	assert.Len(t, GenerateTransfersFromCoordinates[string](null_island_stops, 500, DefaultWalkingSpeedInMetersPerSecond), 2)
 }
 
-func TestSimpleForwardRaptor_NoTransferStart(t *testing.T) {
-	var epoch_20250823_120000_edt int64 = 1755964800
-	var epoch_20250824_120000_edt int64 = 1756051200
-
-	journeys := SimpleRaptor(
-		SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
-			FromStops: []GtfsStopStruct[string]{
-				{UniqueID: "SANDS ST/PEARL ST "},
-				{UniqueID: "High St"},
-			},
-			ToStops: []GtfsStopStruct[string]{
-				{UniqueID: "Franklin Av"},
-			},
-			Transfers: []GtfsTransferStruct[string]{
-				{
-					FromUniqueStopID:             "SANDS ST/PEARL ST ",
-					ToUniqueStopID:               "High St",
-					MinimumTransferTimeInSeconds: 0,
-				},
-			},
-			StopTimes: []GtfsStopTimeStruct[string]{
-				{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
-
-				{UniqueStopID: "High St", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250824_120000_edt - 10, DepartureTimeInSeconds: epoch_20250824_120000_edt + 10},
-				{UniqueStopID: "Franklin Av", UniqueTripID: "A_20250824", UniqueTripServiceID: "A_20250824", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250824_120000_edt + 120, DepartureTimeInSeconds: epoch_20250824_120000_edt + 130},
-			},
-			Mode: RaptorModeDepartAt,
-			/* 2025/08/23 12:00:00PM EDT */
-			TimeInSeconds:        epoch_20250823_120000_edt,
-			MaximumTransfers:     4,
-			AllowTransferHopping: false,
+func TestSimpleRaptor_IntermediateStops(t *testing.T) {
+	now := time.Now()
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops: []GtfsStopStruct[string]{
+			{UniqueID: "Nostrand"},
+		},
+		ToStops: []GtfsStopStruct[string]{
+			{UniqueID: "Jay Street"},
+		},
+		Transfers: []GtfsTransferStruct[string]{},
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "Franklin Ave", UniqueTripID: "A", UniqueTripServiceID: "A", StopSequence: 1, ArrivalTimeInSeconds: now.Add(10 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(15 * time.Second).Unix()},
+			{UniqueStopID: "Nostrand", UniqueTripID: "A", UniqueTripServiceID: "A", StopSequence: 2, ArrivalTimeInSeconds: now.Add(20 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(25 * time.Second).Unix()},
+			{UniqueStopID: "Hoyt", UniqueTripID: "A", UniqueTripServiceID: "A", StopSequence: 3, ArrivalTimeInSeconds: now.Add(30 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(35 * time.Second).Unix()},
+			{UniqueStopID: "Lafayette", UniqueTripID: "A", UniqueTripServiceID: "A", StopSequence: 4, ArrivalTimeInSeconds: now.Add(40 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(45 * time.Second).Unix()},
+			{UniqueStopID: "Jay Street", UniqueTripID: "A", UniqueTripServiceID: "A", StopSequence: 5, ArrivalTimeInSeconds: now.Add(50 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(55 * time.Second).Unix()},
+			{UniqueStopID: "High St", UniqueTripID: "A", UniqueTripServiceID: "A", StopSequence: 6, ArrivalTimeInSeconds: now.Add(60 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(65 * time.Second).Unix()},
 		},
-	)
+		MaximumTransfers: 4,
+	}
+
+	for _, engine := range testEngines {
+		for _, mode := range []RaptorMode{RaptorModeDepartAt, RaptorModeArriveBy} {
+			t.Run(fmt.Sprintf("%s/%s", engine, mode), func(t *testing.T) {
+				input := base_input
+				input.Engine = engine
+				input.Mode = mode
+				input.TimeInSeconds = now.Unix()
+				if mode == RaptorModeArriveBy {
+					input.TimeInSeconds = now.Add(70 * time.Second).Unix()
+				}
+				journeys := SimpleRaptor(input)
+				assert.Len(t, journeys, 1)
+				assert.Nil(t, journeys[0].Legs[0].ViaTrip.IntermediateStops, "should not include the intermediate stops by default")
 
-	if len(journeys) == 0 {
-		t.Fatalf(`did not find any journeys for stop times`)
+				input.IncludeIntermediateStops = true
+				journeys = SimpleRaptor(input)
+				assert.Len(t, journeys, 1)
+				assert.Equal(t, []StopCall[string]{
+					{UniqueStopID: "Hoyt", StopSequence: 3, ArrivalTimeInSeconds: now.Add(30 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(35 * time.Second).Unix()},
+					{UniqueStopID: "Lafayette", StopSequence: 4, ArrivalTimeInSeconds: now.Add(40 * time.Second).Unix(), DepartureTimeInSeconds: now.Add(45 * time.Second).Unix()},
+				}, journeys[0].Legs[0].ViaTrip.IntermediateStops)
+			})
+		}
 	}
+}
 
-	if len(journeys) > 1 {
-		t.Fatalf(`expected to find 1 journey - should not allow starting at Pearl St and then walking to High St`)
+func TestSimpleRaptor_ScansPastDestination(t *testing.T) {
+	/* A -> C -> D on T1 and D -> F on T2 - C is both a destination and an origin */
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "C", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "D", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 3, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+			{UniqueStopID: "D", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 1, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+			{UniqueStopID: "F", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 2, ArrivalTimeInSeconds: 1400, DepartureTimeInSeconds: 1400},
+		},
+		MaximumTransfers: 4,
 	}
 
-	if journeys[0].ArrivalTimeInSeconds != epoch_20250823_120000_edt+120 {
-		t.Fatalf(`expected raptor to find arrival time %v but got %v`, epoch_20250823_120000_edt+120, journeys[0].ArrivalTimeInSeconds)
+	for _, engine := range testEngines {
+		t.Run(string(engine)+"/depart_at", func(t *testing.T) {
+			if engine == RaptorEngineTripBased {
+				t.Skip("the trip based engine only keeps the journeys improving on the best arrival at any destination")
+			}
+			input := base_input
+			input.Engine = engine
+			input.FromStops = []GtfsStopStruct[string]{{UniqueID: "A"}}
+			input.ToStops = []GtfsStopStruct[string]{{UniqueID: "C"}, {UniqueID: "F"}}
+			input.Mode = RaptorModeDepartAt
+			input.TimeInSeconds = 900
+			journeys := SimpleRaptor(input)
+			to_stop_ids := []string{}
+			for _, journey := range journeys {
+				to_stop_ids = append(to_stop_ids, journey.ToUniqueStopID)
+			}
+			assert.ElementsMatch(t, []string{"C", "F"}, to_stop_ids, "should continue on T1 past the destination C")
+		})
+		t.Run(string(engine)+"/arrive_by", func(t *testing.T) {
+			input := base_input
+			input.Engine = engine
+			input.FromStops = []GtfsStopStruct[string]{{UniqueID: "A"}, {UniqueID: "C"}}
+			input.ToStops = []GtfsStopStruct[string]{{UniqueID: "F"}}
+			input.Mode = RaptorModeArriveBy
+			input.TimeInSeconds = 1500
+			journeys := SimpleRaptor(input)
+			from_stop_ids := []string{}
+			for _, journey := range journeys {
+				from_stop_ids = append(from_stop_ids, journey.FromUniqueStopID)
+			}
+			assert.ElementsMatch(t, []string{"A", "C"}, from_stop_ids, "should continue back on T1 past the origin C")
+		})
 	}
 }
+
+func TestSimpleForwardRaptor_RescansTripBoardedEarlierInLaterRound(t *testing.T) {
+	/*
+	 * T1 is boarded at X in the first round and passes the destination C - in the second round it can be boarded earlier at V
+	 * (reached by T2) which should not stop T1 from reaching D and from there the destination F
+	 */
+	input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "Y", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 1, ArrivalTimeInSeconds: 950, DepartureTimeInSeconds: 950},
+			{UniqueStopID: "V", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 2, ArrivalTimeInSeconds: 990, DepartureTimeInSeconds: 990},
+			{UniqueStopID: "V", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "X", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "C", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 3, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+			{UniqueStopID: "D", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 4, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+			{UniqueStopID: "D", UniqueTripID: "T3", UniqueTripServiceID: "T3", StopSequence: 1, ArrivalTimeInSeconds: 1400, DepartureTimeInSeconds: 1400},
+			{UniqueStopID: "F", UniqueTripID: "T3", UniqueTripServiceID: "T3", StopSequence: 2, ArrivalTimeInSeconds: 1500, DepartureTimeInSeconds: 1500},
+		},
+		FromStops:        []GtfsStopStruct[string]{{UniqueID: "X"}, {UniqueID: "Y"}},
+		ToStops:          []GtfsStopStruct[string]{{UniqueID: "C"}, {UniqueID: "F"}},
+		Mode:             RaptorModeDepartAt,
+		TimeInSeconds:    900,
+		MaximumTransfers: 4,
+	}
+	/* the trip based engine only keeps the journeys improving on the best arrival at any destination */
+	for _, engine := range []RaptorEngine{RaptorEngineRaptor, RaptorEngineCsa} {
+		t.Run(string(engine), func(t *testing.T) {
+			input.Engine = engine
+			journeys := SimpleRaptor(input)
+			arrival_times_by_to_stop_id := map[string]TimestampInSeconds{}
+			for _, journey := range journeys {
+				arrival_times_by_to_stop_id[journey.ToUniqueStopID] = journey.ArrivalTimeInSeconds
+			}
+			assert.Equal(t, map[string]TimestampInSeconds{"C": 1200, "F": 1500}, arrival_times_by_to_stop_id)
+		})
+	}
+}
+
+func TestSimpleRaptor_StopSequenceGaps(t *testing.T) {
+	/* the stop sequences only need to be increasing - boarding in the middle of the trip should not depend on them being consecutive */
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 10, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "B", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 20, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "C", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 30, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+			{UniqueStopID: "D", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 45, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+		},
+		FromStops:        []GtfsStopStruct[string]{{UniqueID: "B"}},
+		ToStops:          []GtfsStopStruct[string]{{UniqueID: "C"}},
+		MaximumTransfers: 4,
+	}
+	for _, engine := range testEngines {
+		for _, mode := range []RaptorMode{RaptorModeDepartAt, RaptorModeArriveBy} {
+			t.Run(fmt.Sprintf("%s/%s", engine, mode), func(t *testing.T) {
+				input := base_input
+				input.Engine = engine
+				input.Mode = mode
+				input.TimeInSeconds = 900
+				if mode == RaptorModeArriveBy {
+					input.TimeInSeconds = 1250
+				}
+				journeys := SimpleRaptor(input)
+				assert.Len(t, journeys, 1)
+				assert.Equal(t, 20, journeys[0].Legs[0].ViaTrip.FromStopSequenceInTrip)
+				assert.Equal(t, 30, journeys[0].Legs[0].ViaTrip.ToStopSequenceInTrip)
+			})
+		}
+	}
+}
+
+func TestSimpleRaptor_TargetPruning(t *testing.T) {
+	/* Z is reached directly by T1 - T2 only reaches D much later */
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "A", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "Z", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "B", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 2, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+			{UniqueStopID: "C", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 3, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+			{UniqueStopID: "D", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 4, ArrivalTimeInSeconds: 1400, DepartureTimeInSeconds: 1400},
+		},
+		FromStops:          []GtfsStopStruct[string]{{UniqueID: "A"}},
+		ToStops:            []GtfsStopStruct[string]{{UniqueID: "Z"}, {UniqueID: "D"}},
+		Mode:               RaptorModeDepartAt,
+		TimeInSeconds:      900,
+		MaximumTransfers:   4,
+		PruneByBestArrival: true,
+	}
+	get_stop_ids := func(journeys []Journey[string], mode RaptorMode) []string {
+		stop_ids := []string{}
+		for _, journey := range journeys {
+			if mode == RaptorModeArriveBy {
+				stop_ids = append(stop_ids, journey.FromUniqueStopID)
+			} else {
+				stop_ids = append(stop_ids, journey.ToUniqueStopID)
+			}
+		}
+		return stop_ids
+	}
+
+	for _, engine := range []RaptorEngine{RaptorEngineRaptor, RaptorEngineCsa} {
+		t.Run(string(engine)+"/depart_at", func(t *testing.T) {
+			input := base_input
+			input.Engine = engine
+			input.PruneByBestArrival = false
+			assert.ElementsMatch(t, []string{"Z", "D"}, get_stop_ids(SimpleRaptor(input), input.Mode), "should not prune unless asked to")
+			input.PruneByBestArrival = true
+			assert.Equal(t, []string{"Z"}, get_stop_ids(SimpleRaptor(input), input.Mode), "should prune D arriving after Z")
+			input.BestArrivalSlackInSeconds = 200
+			assert.Equal(t, []string{"Z"}, get_stop_ids(SimpleRaptor(input), input.Mode), "should prune D arriving more than 200 seconds after Z")
+			input.BestArrivalSlackInSeconds = 600
+			assert.ElementsMatch(t, []string{"Z", "D"}, get_stop_ids(SimpleRaptor(input), input.Mode), "should include D arriving within 600 seconds of Z")
+		})
+		t.Run(string(engine)+"/arrive_by", func(t *testing.T) {
+			input := base_input
+			input.Engine = engine
+			input.Mode = RaptorModeArriveBy
+			input.FromStops = []GtfsStopStruct[string]{{UniqueID: "A"}, {UniqueID: "C"}}
+			input.ToStops = []GtfsStopStruct[string]{{UniqueID: "D"}}
+			input.TimeInSeconds = 1500
+			assert.Equal(t, []string{"C"}, get_stop_ids(SimpleRaptor(input), input.Mode), "should prune A departing before C")
+			input.BestArrivalSlackInSeconds = 600
+			assert.ElementsMatch(t, []string{"A", "C"}, get_stop_ids(SimpleRaptor(input), input.Mode), "should include A departing within 600 seconds of C")
+		})
+	}
+
+	/* once Z is reached none of the stops of T2 are labelled */
+	get_labels_improved := func(slack TimestampInSeconds) int {
+		collector := &RaptorStatsCollector{}
+		input := base_input
+		input.Observer = collector
+		input.BestArrivalSlackInSeconds = slack
+		SimpleRaptor(input)
+		labels_improved := 0
+		for _, round := range collector.GetLastQuery().Rounds {
+			labels_improved += round.LabelsImproved
+		}
+		return labels_improved
+	}
+	assert.Equal(t, 1, get_labels_improved(0))
+	assert.Equal(t, 4, get_labels_improved(600))
+}
+
+func TestFilterJourneysWithinSlack_SearchMode(t *testing.T) {
+	/* SimpleRaptor runs arrive by for any mode other than depart at - so the filter uses the direction of the search and not the input mode */
+	input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{PruneByBestArrival: true, BestArrivalSlackInSeconds: 50}
+	prepared_input := PrepareRaptorInput(input)
+	journeys := []Journey[string]{
+		{FromUniqueStopID: "A", DepartureTimeInSeconds: 1000, ArrivalTimeInSeconds: 1400},
+		{FromUniqueStopID: "C", DepartureTimeInSeconds: 1100, ArrivalTimeInSeconds: 1500},
+	}
+	arrive_by_journeys := prepared_input.filterJourneysWithinSlack(journeys, RaptorModeArriveBy)
+	assert.Len(t, arrive_by_journeys, 1)
+	assert.Equal(t, "C", arrive_by_journeys[0].FromUniqueStopID, "should keep the latest departure")
+
+	depart_at_journeys := prepared_input.filterJourneysWithinSlack(journeys, RaptorModeDepartAt)
+	assert.Len(t, depart_at_journeys, 1)
+	assert.Equal(t, "A", depart_at_journeys[0].FromUniqueStopID, "should keep the earliest arrival")
+}
@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/rpc/raptorpb"
+)
+
+func toProtoJourney(journey raptor.Journey[string]) *raptorpb.Journey {
+	proto_journey := &raptorpb.Journey{
+		FromStopId:            journey.FromUniqueStopID,
+		ToStopId:              journey.ToUniqueStopID,
+		DepartureTime:         int64(journey.DepartureTimeInSeconds),
+		ArrivalTime:           int64(journey.ArrivalTimeInSeconds),
+		AccessDurationSeconds: int64(journey.AccessDurationInSeconds),
+		EgressDurationSeconds: int64(journey.EgressDurationInSeconds),
+		PenaltySeconds:        int64(journey.PenaltyInSeconds),
+		Legs:                  make([]*raptorpb.Leg, len(journey.Legs)),
+	}
+	for index, leg := range journey.Legs {
+		proto_journey.Legs[index] = toProtoLeg(leg)
+	}
+	return proto_journey
+}
+
+func toProtoLeg(leg raptor.RoundSegmentSpan[string]) *raptorpb.Leg {
+	proto_leg := &raptorpb.Leg{
+		FromStopId:    leg.FromUniqueStopID,
+		ToStopId:      leg.ToUniqueStopID,
+		DepartureTime: int64(leg.DepartureTimeInSecondsFromUniqueStopID),
+		ArrivalTime:   int64(leg.ArrivalTimeInSecondsToUniqueStopID),
+		Mode:          string(leg.Mode),
+		WithBike:      leg.WithBike,
+	}
+	if leg.ViaTrip == nil {
+		return proto_leg
+	}
+	proto_leg.Trip = &raptorpb.Trip{
+		TripId:           leg.ViaTrip.UniqueTripID,
+		TripServiceId:    leg.ViaTrip.UniqueTripServiceID,
+		FromStopSequence: int32(leg.ViaTrip.FromStopSequenceInTrip),
+		ToStopSequence:   int32(leg.ViaTrip.ToStopSequenceInTrip),
+		IsEstimated:      leg.ViaTrip.IsEstimated,
+	}
+	for _, stop_call := range leg.ViaTrip.IntermediateStops {
+		proto_leg.Trip.IntermediateStops = append(proto_leg.Trip.IntermediateStops, &raptorpb.StopCall{
+			StopId:        stop_call.UniqueStopID,
+			StopSequence:  int32(stop_call.StopSequence),
+			ArrivalTime:   int64(stop_call.ArrivalTimeInSeconds),
+			DepartureTime: int64(stop_call.DepartureTimeInSeconds),
+		})
+	}
+	return proto_leg
+}
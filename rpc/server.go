@@ -0,0 +1,281 @@
+package rpc
+
+//go:generate buf generate
+
+import (
+	"context"
+	"slices"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/rpc/raptorpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultMaximumTransfers     = 4
+	maximumMaximumTransfers     = 8
+	defaultRangeIntervalSeconds = 60
+	maximumRangeSearches        = 1440
+)
+
+var enginesByProtoEngine = map[raptorpb.Engine]raptor.RaptorEngine{
+	raptorpb.Engine_ENGINE_RAPTOR:     raptor.RaptorEngineRaptor,
+	raptorpb.Engine_ENGINE_CSA:        raptor.RaptorEngineCsa,
+	raptorpb.Engine_ENGINE_TRIP_BASED: raptor.RaptorEngineTripBased,
+}
+
+/**
+ * serves the RaptorService by running the searches on the inputs of GetInput - the stops, time, mode,
+ * maximum transfers and engine of the input are set per request
+ */
+type Server[StopType raptor.GtfsStop[string], TransferType raptor.GtfsTransfer[string], StopTimeType raptor.GtfsStopTime[string]] struct {
+	raptorpb.UnimplementedRaptorServiceServer
+	/* gets the (ideally prepared) input for searches at the time */
+	GetInput func(time raptor.TimestampInSeconds) (raptor.SimpleRaptorInput[string, StopType, TransferType, StopTimeType], error)
+	/* gets the stops to route from or to for a requested stop ID - ie. to expand stations into their platforms */
+	GetStops func(stop_id string) ([]StopType, error)
+	/* the engine used when the request does not specify one - defaults to raptor */
+	Engine raptor.RaptorEngine
+	/* the maximum transfers used when the request does not specify them - defaults to 4 */
+	MaximumTransfers int
+}
+
+func (s *Server[StopType, TransferType, StopTimeType]) Plan(ctx context.Context, request *raptorpb.PlanRequest) (*raptorpb.PlanResponse, error) {
+	input, err := s.getPlanInput(request)
+	if err != nil {
+		return nil, err
+	}
+	return s.plan(input, request.GetTime())
+}
+
+/** runs the plan request for every interval of the window - the journeys which were already sent are left out of the later responses */
+func (s *Server[StopType, TransferType, StopTimeType]) PlanRange(request *raptorpb.PlanRangeRequest, stream raptorpb.RaptorService_PlanRangeServer) error {
+	input, err := s.getPlanInput(request.GetRequest())
+	if err != nil {
+		return err
+	}
+	start_time := request.GetRequest().GetTime()
+	interval := request.GetIntervalSeconds()
+	if interval == 0 {
+		interval = defaultRangeIntervalSeconds
+	}
+	if interval < 0 || request.GetEndTime() < start_time {
+		return status.Error(codes.InvalidArgument, "the end time should not be before the start time and the interval should be positive")
+	}
+	if (request.GetEndTime()-start_time)/interval >= maximumRangeSearches {
+		return status.Errorf(codes.InvalidArgument, "the window should contain at most %d searches", maximumRangeSearches)
+	}
+
+	sent_journeys_by_key := map[sentJourneyKey][]raptor.Journey[string]{}
+	for time := start_time; time <= request.GetEndTime(); time += interval {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		journeys, err := s.search(input, time)
+		if err != nil {
+			return err
+		}
+		response := &raptorpb.PlanResponse{Time: time, Journeys: []*raptorpb.Journey{}}
+		for _, journey := range journeys {
+			key := sentJourneyKey{
+				DepartureTimeInSeconds: journey.DepartureTimeInSeconds,
+				ArrivalTimeInSeconds:   journey.ArrivalTimeInSeconds,
+				FingerPrintHash:        journey.GetFingerPrintHash(),
+			}
+			if slices.ContainsFunc(sent_journeys_by_key[key], journey.HasSameLegs) {
+				continue
+			}
+			sent_journeys_by_key[key] = append(sent_journeys_by_key[key], journey)
+			response.Journeys = append(response.Journeys, toProtoJourney(journey))
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/** runs a depart at one-to-all search per origin and picks the earliest arrival at each destination */
+func (s *Server[StopType, TransferType, StopTimeType]) Matrix(ctx context.Context, request *raptorpb.MatrixRequest) (*raptorpb.MatrixResponse, error) {
+	if len(request.GetFromStopIds()) == 0 || len(request.GetToStopIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "the from and to stop IDs are required")
+	}
+	if request.GetMaxDurationSeconds() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "the maximum duration should not be negative")
+	}
+	maximum_transfers, err := s.getMaximumTransfers(request.MaxTransfers)
+	if err != nil {
+		return nil, err
+	}
+	to_stops_by_stop_id := make(map[string][]StopType, len(request.GetToStopIds()))
+	for _, to_stop_id := range request.GetToStopIds() {
+		if to_stops_by_stop_id[to_stop_id], err = s.getStops(to_stop_id); err != nil {
+			return nil, err
+		}
+	}
+	time := raptor.TimestampInSeconds(request.GetTime())
+	input, err := s.GetInput(time)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get the input: %v", err)
+	}
+	input.TimeInSeconds = time
+	input.Mode = raptor.RaptorModeDepartAt
+	input.MaximumTransfers = maximum_transfers
+	if request.GetMaxDurationSeconds() > 0 {
+		input.StopTimeCutOffTimestamp = time + raptor.TimestampInSeconds(request.GetMaxDurationSeconds())
+	}
+
+	response := &raptorpb.MatrixResponse{Rows: make([]*raptorpb.MatrixRow, len(request.GetFromStopIds()))}
+	for row_index, from_stop_id := range request.GetFromStopIds() {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		if input.FromStops, err = s.getStops(from_stop_id); err != nil {
+			return nil, err
+		}
+		segments_by_unique_stop_id := raptor.SimpleRaptorDepartAtOneToAll(input)
+		row := &raptorpb.MatrixRow{FromStopId: from_stop_id, Cells: make([]*raptorpb.MatrixCell, len(request.GetToStopIds()))}
+		for cell_index, to_stop_id := range request.GetToStopIds() {
+			cell := &raptorpb.MatrixCell{ToStopId: to_stop_id}
+			for _, to_stop := range to_stops_by_stop_id[to_stop_id] {
+				segment, has_segment := segments_by_unique_stop_id[to_stop.GetUniqueID()]
+				duration := segment.ArrivalTimeInSeconds - time
+				if !has_segment || (request.GetMaxDurationSeconds() > 0 && duration > raptor.TimestampInSeconds(request.GetMaxDurationSeconds())) {
+					continue
+				}
+				if cell.Reachable && int64(segment.ArrivalTimeInSeconds) >= cell.ArrivalTime {
+					continue
+				}
+				cell.Reachable = true
+				cell.ArrivalTime = int64(segment.ArrivalTimeInSeconds)
+				cell.DurationSeconds = int64(duration)
+				cell.Transfers = int32(getTransfers(segment.Spans))
+			}
+			row.Cells[cell_index] = cell
+		}
+		response.Rows[row_index] = row
+	}
+	return response, nil
+}
+
+/** validates the request and gets the input without the time - which is set per search */
+func (s *Server[StopType, TransferType, StopTimeType]) getPlanInput(request *raptorpb.PlanRequest) (raptor.SimpleRaptorInput[string, StopType, TransferType, StopTimeType], error) {
+	var input raptor.SimpleRaptorInput[string, StopType, TransferType, StopTimeType]
+	if request == nil || len(request.GetFromStopIds()) == 0 || len(request.GetToStopIds()) == 0 {
+		return input, status.Error(codes.InvalidArgument, "the from and to stop IDs are required")
+	}
+	maximum_transfers, err := s.getMaximumTransfers(request.MaxTransfers)
+	if err != nil {
+		return input, err
+	}
+	engine := s.Engine
+	if request.GetEngine() != raptorpb.Engine_ENGINE_UNSPECIFIED {
+		var has_engine bool
+		if engine, has_engine = enginesByProtoEngine[request.GetEngine()]; !has_engine {
+			return input, status.Errorf(codes.InvalidArgument, "unknown engine %v", request.GetEngine())
+		}
+	}
+	from_stops, err := s.getStopsOfStopIDs(request.GetFromStopIds())
+	if err != nil {
+		return input, err
+	}
+	to_stops, err := s.getStopsOfStopIDs(request.GetToStopIds())
+	if err != nil {
+		return input, err
+	}
+
+	input.FromStops = from_stops
+	input.ToStops = to_stops
+	input.MaximumTransfers = maximum_transfers
+	input.Engine = engine
+	input.Mode = raptor.RaptorModeDepartAt
+	if request.GetArriveBy() {
+		input.Mode = raptor.RaptorModeArriveBy
+	}
+	return input, nil
+}
+
+/** runs the search at the time using the stops and options of the input */
+func (s *Server[StopType, TransferType, StopTimeType]) plan(request_input raptor.SimpleRaptorInput[string, StopType, TransferType, StopTimeType], time int64) (*raptorpb.PlanResponse, error) {
+	journeys, err := s.search(request_input, time)
+	if err != nil {
+		return nil, err
+	}
+	response := &raptorpb.PlanResponse{Time: time, Journeys: make([]*raptorpb.Journey, len(journeys))}
+	for index, journey := range journeys {
+		response.Journeys[index] = toProtoJourney(journey)
+	}
+	return response, nil
+}
+
+func (s *Server[StopType, TransferType, StopTimeType]) search(request_input raptor.SimpleRaptorInput[string, StopType, TransferType, StopTimeType], time int64) ([]raptor.Journey[string], error) {
+	input, err := s.GetInput(raptor.TimestampInSeconds(time))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get the input: %v", err)
+	}
+	input.FromStops = request_input.FromStops
+	input.ToStops = request_input.ToStops
+	input.MaximumTransfers = request_input.MaximumTransfers
+	input.Engine = request_input.Engine
+	input.Mode = request_input.Mode
+	input.TimeInSeconds = raptor.TimestampInSeconds(time)
+
+	return raptor.SimpleRaptor(input), nil
+}
+
+func (s *Server[StopType, TransferType, StopTimeType]) getMaximumTransfers(requested_maximum_transfers *int32) (int, error) {
+	if requested_maximum_transfers == nil {
+		if s.MaximumTransfers == 0 {
+			return defaultMaximumTransfers, nil
+		}
+		return s.MaximumTransfers, nil
+	}
+	if *requested_maximum_transfers < 0 || *requested_maximum_transfers > maximumMaximumTransfers {
+		return 0, status.Errorf(codes.InvalidArgument, "max_transfers should be between 0 and %d", maximumMaximumTransfers)
+	}
+	return int(*requested_maximum_transfers), nil
+}
+
+func (s *Server[StopType, TransferType, StopTimeType]) getStopsOfStopIDs(stop_ids []string) ([]StopType, error) {
+	stops := []StopType{}
+	for _, stop_id := range stop_ids {
+		stop_id_stops, err := s.getStops(stop_id)
+		if err != nil {
+			return nil, err
+		}
+		stops = append(stops, stop_id_stops...)
+	}
+	return stops, nil
+}
+
+func (s *Server[StopType, TransferType, StopTimeType]) getStops(stop_id string) ([]StopType, error) {
+	stops, err := s.GetStops(stop_id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return stops, nil
+}
+
+/**
+ * the journeys of different searches in a window are the same when they use the same trips at the same times - the journeys with the same
+ * key are compared using HasSameLegs since different legs can have the same fingerprint hash
+ */
+type sentJourneyKey struct {
+	DepartureTimeInSeconds raptor.TimestampInSeconds
+	ArrivalTimeInSeconds   raptor.TimestampInSeconds
+	FingerPrintHash        uint64
+}
+
+func getTransfers(spans []raptor.RoundSegmentSpan[string]) int {
+	trips := 0
+	for _, span := range spans {
+		if span.ViaTrip != nil {
+			trips++
+		}
+	}
+	if trips == 0 {
+		return 0
+	}
+	return trips - 1
+}
@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/rpc/raptorpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+type testInput = raptor.SimpleRaptorInput[string, raptor.GtfsStopStruct[string], raptor.GtfsTransferStruct[string], raptor.GtfsStopTimeStruct[string]]
+
+/* starts the server on an in-memory listener and returns a client connected to it */
+func getTestClient(t *testing.T) raptorpb.RaptorServiceClient {
+	stop_times := []raptor.GtfsStopTimeStruct[string]{}
+	for _, trip := range []struct {
+		id    string
+		start int64
+	}{{"T1", 1000}, {"T2", 2000}} {
+		for index, stop_id := range []string{"A", "B", "C"} {
+			time := trip.start + int64(index)*100
+			stop_times = append(stop_times, raptor.GtfsStopTimeStruct[string]{
+				UniqueStopID: stop_id, UniqueTripID: trip.id, UniqueTripServiceID: trip.id, StopSequence: index + 1,
+				ArrivalTimeInSeconds: time, DepartureTimeInSeconds: time,
+			})
+		}
+	}
+	input := testInput{
+		StopTimes:                stop_times,
+		Transfers:                []raptor.GtfsTransferStruct[string]{{FromUniqueStopID: "C", ToUniqueStopID: "D", MinimumTransferTimeInSeconds: 60}},
+		IncludeIntermediateStops: true,
+	}
+	input = input.WithPreparedInput(raptor.PrepareRaptorInput(input))
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpc_server := grpc.NewServer()
+	raptorpb.RegisterRaptorServiceServer(grpc_server, &Server[raptor.GtfsStopStruct[string], raptor.GtfsTransferStruct[string], raptor.GtfsStopTimeStruct[string]]{
+		GetInput: func(time raptor.TimestampInSeconds) (testInput, error) { return input, nil },
+		GetStops: func(stop_id string) ([]raptor.GtfsStopStruct[string], error) {
+			switch stop_id {
+			case "A", "B", "C", "D":
+				return []raptor.GtfsStopStruct[string]{{UniqueID: stop_id}}, nil
+			}
+			return nil, fmt.Errorf("unknown stop %q", stop_id)
+		},
+	})
+	go grpc_server.Serve(listener)
+	t.Cleanup(grpc_server.Stop)
+
+	connection, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { connection.Close() })
+	return raptorpb.NewRaptorServiceClient(connection)
+}
+
+func TestServer_Plan(t *testing.T) {
+	client := getTestClient(t)
+	for _, engine := range []raptorpb.Engine{raptorpb.Engine_ENGINE_UNSPECIFIED, raptorpb.Engine_ENGINE_CSA, raptorpb.Engine_ENGINE_TRIP_BASED} {
+		t.Run(engine.String(), func(t *testing.T) {
+			response, err := client.Plan(context.Background(), &raptorpb.PlanRequest{FromStopIds: []string{"A"}, ToStopIds: []string{"C"}, Time: 900, Engine: engine})
+			assert.NoError(t, err)
+			assert.Len(t, response.Journeys, 1)
+			assert.Equal(t, int64(1000), response.Journeys[0].DepartureTime)
+			assert.Equal(t, int64(1200), response.Journeys[0].ArrivalTime)
+			assert.Equal(t, "T1", response.Journeys[0].Legs[0].Trip.TripId)
+			assert.Equal(t, "B", response.Journeys[0].Legs[0].Trip.IntermediateStops[0].StopId)
+
+			response, err = client.Plan(context.Background(), &raptorpb.PlanRequest{FromStopIds: []string{"A"}, ToStopIds: []string{"C"}, Time: 2100, ArriveBy: true, Engine: engine})
+			assert.NoError(t, err)
+			assert.Len(t, response.Journeys, 1)
+			assert.Equal(t, int64(1200), response.Journeys[0].ArrivalTime)
+		})
+	}
+
+	_, err := client.Plan(context.Background(), &raptorpb.PlanRequest{FromStopIds: []string{"A"}, ToStopIds: []string{"X"}, Time: 900})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.Plan(context.Background(), &raptorpb.PlanRequest{FromStopIds: []string{"A"}, ToStopIds: []string{"C"}, MaxTransfers: proto.Int32(100)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Plan(context.Background(), &raptorpb.PlanRequest{ToStopIds: []string{"C"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_PlanRange(t *testing.T) {
+	client := getTestClient(t)
+	stream, err := client.PlanRange(context.Background(), &raptorpb.PlanRangeRequest{
+		Request:         &raptorpb.PlanRequest{FromStopIds: []string{"A"}, ToStopIds: []string{"C"}, Time: 300},
+		EndTime:         1500,
+		IntervalSeconds: 600,
+	})
+	assert.NoError(t, err)
+	times := []int64{}
+	trip_ids := [][]string{}
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		times = append(times, response.Time)
+		response_trip_ids := []string{}
+		for _, journey := range response.Journeys {
+			response_trip_ids = append(response_trip_ids, journey.Legs[0].Trip.TripId)
+		}
+		trip_ids = append(trip_ids, response_trip_ids)
+	}
+	assert.Equal(t, []int64{300, 900, 1500}, times)
+	assert.Equal(t, [][]string{{"T1"}, {}, {"T2"}}, trip_ids, "should only stream the journeys which were not found before")
+
+	stream, err = client.PlanRange(context.Background(), &raptorpb.PlanRangeRequest{
+		Request: &raptorpb.PlanRequest{FromStopIds: []string{"A"}, ToStopIds: []string{"C"}, Time: 300},
+		EndTime: 200,
+	})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_Matrix(t *testing.T) {
+	client := getTestClient(t)
+	response, err := client.Matrix(context.Background(), &raptorpb.MatrixRequest{FromStopIds: []string{"A", "B"}, ToStopIds: []string{"B", "C", "D"}, Time: 900})
+	assert.NoError(t, err)
+	assert.Len(t, response.Rows, 2)
+	assert.Equal(t, "A", response.Rows[0].FromStopId)
+	assert.Equal(t, []int64{200, 300, 360}, []int64{response.Rows[0].Cells[0].DurationSeconds, response.Rows[0].Cells[1].DurationSeconds, response.Rows[0].Cells[2].DurationSeconds})
+	assert.Equal(t, int64(1200), response.Rows[0].Cells[1].ArrivalTime)
+	assert.True(t, response.Rows[1].Cells[0].Reachable, "should reach the origin itself")
+	assert.Equal(t, int64(0), response.Rows[1].Cells[0].DurationSeconds)
+	assert.Equal(t, int64(300), response.Rows[1].Cells[1].DurationSeconds, "should board the trip at B")
+
+	response, err = client.Matrix(context.Background(), &raptorpb.MatrixRequest{FromStopIds: []string{"A"}, ToStopIds: []string{"B", "C"}, Time: 900, MaxDurationSeconds: 250})
+	assert.NoError(t, err)
+	assert.True(t, response.Rows[0].Cells[0].Reachable)
+	assert.False(t, response.Rows[0].Cells[1].Reachable, "should not reach the destinations beyond the maximum duration")
+
+	_, err = client.Matrix(context.Background(), &raptorpb.MatrixRequest{FromStopIds: []string{"X"}, ToStopIds: []string{"C"}, Time: 900})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,179 @@
+package go_raptor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/** the work done in a single round - which is the search for journeys with one more trip than the previous round */
+type RaptorRoundStats struct {
+	/* starting at 0 for the journeys with a single trip */
+	Round int
+	/* the stops from which trips were scanned - for csa these are all stops reached so far and for trip based the queued trip segments */
+	MarkedStops int
+	/* the stop times visited at the marked stops and along the scanned trips - for csa these are the connections */
+	StopTimesScanned int
+	/* the trips which were boarded */
+	TripsScanned int
+	/* the arrival labels at stops which were improved - for trip based the trip segments which were queued */
+	LabelsImproved int
+	/* the walking transfers which were followed */
+	TransfersRelaxed int
+	Duration         time.Duration
+}
+
+type RaptorQueryStats struct {
+	/* the ID of the query handle passed to the observer */
+	QueryID  uint64
+	Engine   RaptorEngine
+	Mode     RaptorMode
+	Rounds   []RaptorRoundStats
+	Journeys int
+	Duration time.Duration
+}
+
+/** identifies a query to the observer - the ID is unique within the process so concurrent queries can be told apart */
+type RaptorQuery struct {
+	ID     uint64
+	Engine RaptorEngine
+	Mode   RaptorMode
+}
+
+var raptor_query_id atomic.Uint64
+
+/**
+ * receives the progress of the queries run with the input - ie. to start and end tracing spans or to record metrics
+ * the callbacks are called synchronously from the query so they should not block and may be called concurrently when the observer is shared
+ * the context returned by OnQueryStart is passed to the other callbacks of the query - ie. to carry the span of the query
+ * a via query runs a query per via stop with the context of the input
+ */
+type RaptorObserver interface {
+	OnQueryStart(ctx context.Context, query RaptorQuery) context.Context
+	OnRoundEnd(ctx context.Context, query RaptorQuery, stats RaptorRoundStats)
+	OnQueryEnd(ctx context.Context, query RaptorQuery, stats RaptorQueryStats)
+}
+
+/**
+ * an observer which keeps the stats of the queries - for when the stats of a single query are needed after running it. safe to share between queries
+ * only the last MaxQueries queries are kept so a shared collector does not grow without bound - Drain takes the kept stats ie. to report them periodically
+ */
+type RaptorStatsCollector struct {
+	/* defaults to DefaultRaptorStatsCollectorMaxQueries */
+	MaxQueries int
+	mutex      sync.Mutex
+	queries    []RaptorQueryStats
+}
+
+func (collector *RaptorStatsCollector) OnQueryStart(ctx context.Context, query RaptorQuery) context.Context {
+	return ctx
+}
+
+func (collector *RaptorStatsCollector) OnRoundEnd(ctx context.Context, query RaptorQuery, stats RaptorRoundStats) {
+}
+
+func (collector *RaptorStatsCollector) OnQueryEnd(ctx context.Context, query RaptorQuery, stats RaptorQueryStats) {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	max_queries := collector.MaxQueries
+	if max_queries <= 0 {
+		max_queries = DefaultRaptorStatsCollectorMaxQueries
+	}
+	/* the dropped stats are released once append moves the kept ones to a new array */
+	if len(collector.queries) >= max_queries {
+		collector.queries = collector.queries[len(collector.queries)-max_queries+1:]
+	}
+	collector.queries = append(collector.queries, stats)
+}
+
+/** gets a copy of the stats of the kept queries in the order they ended */
+func (collector *RaptorStatsCollector) GetQueries() []RaptorQueryStats {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	queries := make([]RaptorQueryStats, len(collector.queries))
+	copy(queries, collector.queries)
+	return queries
+}
+
+/** gets the stats of the last query - or empty stats when no query was run */
+func (collector *RaptorStatsCollector) GetLastQuery() RaptorQueryStats {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	if len(collector.queries) == 0 {
+		return RaptorQueryStats{}
+	}
+	return collector.queries[len(collector.queries)-1]
+}
+
+/** gets the stats of the kept queries in the order they ended and removes them from the collector */
+func (collector *RaptorStatsCollector) Drain() []RaptorQueryStats {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	queries := collector.queries
+	collector.queries = nil
+	if queries == nil {
+		return []RaptorQueryStats{}
+	}
+	return queries
+}
+
+/** removes the stats of the kept queries */
+func (collector *RaptorStatsCollector) Reset() {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	collector.queries = nil
+}
+
+/** keeps track of the stats of a query - the engines increment the counters of the current round directly */
+type raptorStatsRecorder struct {
+	observer         RaptorObserver
+	ctx              context.Context
+	query            RaptorQuery
+	stats            RaptorQueryStats
+	round            RaptorRoundStats
+	query_started_at time.Time
+	round_started_at time.Time
+}
+
+/** the context defaults to the background context when the input has none */
+func newRaptorStatsRecorder(ctx context.Context, observer RaptorObserver, engine RaptorEngine, mode RaptorMode) *raptorStatsRecorder {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	query := RaptorQuery{ID: raptor_query_id.Add(1), Engine: engine, Mode: mode}
+	recorder := &raptorStatsRecorder{
+		observer:         observer,
+		ctx:              ctx,
+		query:            query,
+		stats:            RaptorQueryStats{QueryID: query.ID, Engine: engine, Mode: mode, Rounds: []RaptorRoundStats{}},
+		query_started_at: time.Now(),
+	}
+	if observer != nil {
+		if observer_ctx := observer.OnQueryStart(ctx, query); observer_ctx != nil {
+			recorder.ctx = observer_ctx
+		}
+	}
+	return recorder
+}
+
+func (recorder *raptorStatsRecorder) startRound(marked_stops int) {
+	recorder.round = RaptorRoundStats{Round: len(recorder.stats.Rounds), MarkedStops: marked_stops}
+	recorder.round_started_at = time.Now()
+}
+
+func (recorder *raptorStatsRecorder) endRound() {
+	recorder.round.Duration = time.Since(recorder.round_started_at)
+	recorder.stats.Rounds = append(recorder.stats.Rounds, recorder.round)
+	if recorder.observer != nil {
+		recorder.observer.OnRoundEnd(recorder.ctx, recorder.query, recorder.round)
+	}
+}
+
+func (recorder *raptorStatsRecorder) endQuery(journeys int) {
+	recorder.stats.Journeys = journeys
+	recorder.stats.Duration = time.Since(recorder.query_started_at)
+	if recorder.observer != nil {
+		recorder.observer.OnQueryEnd(recorder.ctx, recorder.query, recorder.stats)
+	}
+}
@@ -0,0 +1,168 @@
+package go_raptor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statsTestContextKey struct{}
+
+type recordingRaptorObserver struct {
+	events []string
+}
+
+func (observer *recordingRaptorObserver) OnQueryStart(ctx context.Context, query RaptorQuery) context.Context {
+	observer.events = append(observer.events, fmt.Sprintf("start %s %v", query.Engine, ctx.Value(statsTestContextKey{})))
+	return context.WithValue(ctx, statsTestContextKey{}, query.ID)
+}
+
+func (observer *recordingRaptorObserver) OnRoundEnd(ctx context.Context, query RaptorQuery, stats RaptorRoundStats) {
+	observer.events = append(observer.events, fmt.Sprintf("round %t", ctx.Value(statsTestContextKey{}) == query.ID))
+}
+
+func (observer *recordingRaptorObserver) OnQueryEnd(ctx context.Context, query RaptorQuery, stats RaptorQueryStats) {
+	observer.events = append(observer.events, fmt.Sprintf("end %t", ctx.Value(statsTestContextKey{}) == query.ID && stats.QueryID == query.ID))
+}
+
+func getStatsTestInput() SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]] {
+	return SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "B", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "C", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 3, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+			{UniqueStopID: "D", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 1, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+			{UniqueStopID: "E", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 2, ArrivalTimeInSeconds: 1400, DepartureTimeInSeconds: 1400},
+		},
+		Transfers: []GtfsTransferStruct[string]{
+			{FromUniqueStopID: "C", ToUniqueStopID: "D", MinimumTransferTimeInSeconds: 60},
+			{FromUniqueStopID: "D", ToUniqueStopID: "C", MinimumTransferTimeInSeconds: 60},
+		},
+		FromStops:        []GtfsStopStruct[string]{{UniqueID: "A"}},
+		ToStops:          []GtfsStopStruct[string]{{UniqueID: "E"}},
+		TimeInSeconds:    900,
+		MaximumTransfers: 4,
+		Mode:             RaptorModeDepartAt,
+	}
+}
+
+func TestSimpleRaptor_Stats(t *testing.T) {
+	collector := &RaptorStatsCollector{}
+	input := getStatsTestInput()
+	input.Observer = collector
+	journeys := SimpleRaptor(input)
+	assert.Len(t, journeys, 1)
+
+	stats := collector.GetLastQuery()
+	assert.Equal(t, RaptorEngineRaptor, stats.Engine)
+	assert.Equal(t, RaptorModeDepartAt, stats.Mode)
+	assert.Equal(t, 1, stats.Journeys)
+	/* the durations are not deterministic */
+	for index := range stats.Rounds {
+		stats.Rounds[index].Duration = 0
+	}
+	assert.Equal(t, []RaptorRoundStats{
+		/* boards T1 at A and arrives at B and C - then walks to D */
+		{Round: 0, MarkedStops: 1, StopTimesScanned: 3, TripsScanned: 1, LabelsImproved: 3, TransfersRelaxed: 1},
+		/* T1 is scanned again from B without improvements - T2 is boarded at D */
+		{Round: 1, MarkedStops: 3, StopTimesScanned: 5, TripsScanned: 2, LabelsImproved: 1, TransfersRelaxed: 0},
+		/* nothing is left to scan */
+		{Round: 2},
+	}, stats.Rounds)
+}
+
+func TestSimpleRaptor_StatsEngines(t *testing.T) {
+	for _, engine := range []RaptorEngine{RaptorEngineRaptor, RaptorEngineCsa, RaptorEngineTripBased} {
+		for _, mode := range []RaptorMode{RaptorModeDepartAt, RaptorModeArriveBy} {
+			collector := &RaptorStatsCollector{}
+			input := getStatsTestInput()
+			input.Engine = engine
+			input.Mode = mode
+			input.Observer = collector
+			if mode == RaptorModeArriveBy {
+				input.TimeInSeconds = 1500
+			}
+			journeys := SimpleRaptor(input)
+			assert.Len(t, journeys, 1, "%s %s", engine, mode)
+
+			stats := collector.GetLastQuery()
+			assert.Equal(t, mode, stats.Mode, "%s %s", engine, mode)
+			assert.Equal(t, 1, stats.Journeys, "%s %s", engine, mode)
+			assert.GreaterOrEqual(t, len(stats.Rounds), 2, "%s %s should need a round per trip", engine, mode)
+			assert.Equal(t, 1, stats.Rounds[0].TripsScanned, "%s %s", engine, mode)
+			assert.Equal(t, 1, stats.Rounds[0].TransfersRelaxed+stats.Rounds[1].TransfersRelaxed, "%s %s", engine, mode)
+		}
+	}
+}
+
+func TestSimpleRaptor_Observer(t *testing.T) {
+	observer := &recordingRaptorObserver{}
+	input := getStatsTestInput()
+	input.Engine = RaptorEngineCsa
+	input.Observer = observer
+	input.Context = context.WithValue(context.Background(), statsTestContextKey{}, "parent")
+	SimpleRaptor(input)
+	assert.Equal(t, []string{"start csa parent", "round true", "round true", "round true", "end true"}, observer.events, "should pass the context returned by the start of the query")
+
+	observer.events = nil
+	input.Context = nil
+	SimpleRaptor(input)
+	assert.Equal(t, "start csa <nil>", observer.events[0], "should default to the background context")
+
+	assert.Equal(t, RaptorQueryStats{}, (&RaptorStatsCollector{}).GetLastQuery(), "should be empty without queries")
+}
+
+func TestRaptorStatsCollector_Concurrent(t *testing.T) {
+	collector := &RaptorStatsCollector{}
+	var wait_group sync.WaitGroup
+	for range 8 {
+		wait_group.Add(1)
+		go func() {
+			defer wait_group.Done()
+			input := getStatsTestInput()
+			input.Observer = collector
+			SimpleRaptor(input)
+			collector.GetLastQuery()
+		}()
+	}
+	wait_group.Wait()
+
+	queries := collector.GetQueries()
+	assert.Len(t, queries, 8)
+	query_ids := map[uint64]bool{}
+	for _, query := range queries {
+		query_ids[query.QueryID] = true
+	}
+	assert.Len(t, query_ids, 8, "should give every query its own ID")
+}
+
+func TestRaptorStatsCollector_MaxQueries(t *testing.T) {
+	collector := &RaptorStatsCollector{MaxQueries: 3}
+	input := getStatsTestInput()
+	input.Observer = collector
+	for range 5 {
+		SimpleRaptor(input)
+	}
+	queries := collector.GetQueries()
+	assert.Len(t, queries, 3, "should only keep the last queries")
+	assert.Less(t, queries[0].QueryID, queries[2].QueryID, "should keep the queries in the order they ended")
+	assert.Equal(t, queries[2], collector.GetLastQuery())
+
+	assert.Equal(t, queries, collector.Drain())
+	assert.Empty(t, collector.GetQueries(), "should remove the drained queries")
+	assert.Equal(t, []RaptorQueryStats{}, collector.Drain())
+
+	SimpleRaptor(input)
+	collector.Reset()
+	assert.Equal(t, RaptorQueryStats{}, collector.GetLastQuery(), "should remove the queries on reset")
+
+	collector = &RaptorStatsCollector{}
+	input.Observer = collector
+	for range DefaultRaptorStatsCollectorMaxQueries + 1 {
+		SimpleRaptor(input)
+	}
+	assert.Len(t, collector.GetQueries(), DefaultRaptorStatsCollectorMaxQueries, "should default to keeping DefaultRaptorStatsCollectorMaxQueries")
+}
@@ -0,0 +1,107 @@
+package go_raptor
+
+import (
+	"math"
+	"sort"
+)
+
+/** roughly the meters per degree of latitude - used to limit the stops which need their distance calculated */
+const metersPerDegreeOfLatitude = 111320.0
+
+/** the coordinates of a stop implementing GtfsStopWithCoordinates */
+type stopCoordinate[ID UniqueGtfsIdLike] struct {
+	UniqueStopID ID
+	Latitude     float64
+	Longitude    float64
+}
+
+/** gets the coordinates of the stops which implement GtfsStopWithCoordinates and have coordinates */
+func getStopCoordinates[ID UniqueGtfsIdLike, StopType GtfsStop[ID]](stops []StopType) []stopCoordinate[ID] {
+	stop_coordinates := make([]stopCoordinate[ID], 0, len(stops))
+	for _, stop := range stops {
+		stop_with_coordinates, is_stop_with_coordinates := any(stop).(GtfsStopWithCoordinates[ID])
+		if !is_stop_with_coordinates {
+			continue
+		}
+		if latitude, longitude, has_coordinates := stop_with_coordinates.GetCoordinates(); has_coordinates {
+			stop_coordinates = append(stop_coordinates, stopCoordinate[ID]{UniqueStopID: stop.GetUniqueID(), Latitude: latitude, Longitude: longitude})
+		}
+	}
+	return stop_coordinates
+}
+
+/**
+ * generates transfers (in both directions) between all the stops within the maximum distance of each other
+ * the transfer time is based on the straight line distance and the speed - use GetTransferSpeedInMetersPerSecond
+ * to get the walking or cycling speed for the input's profile. only the stops with coordinates (see GtfsStopWithCoordinates) are used
+ */
+func GenerateTransfersFromCoordinates[ID UniqueGtfsIdLike, StopType GtfsStop[ID]](
+	stops []StopType,
+	maximum_distance_in_meters float64,
+	speed_in_meters_per_second float64,
+) []GtfsTransferStruct[ID] {
+	/* by sorting the stops by latitude we only need to compare the stops within the latitude range */
+	sorted_stops := getStopCoordinates[ID](stops)
+	sort.SliceStable(sorted_stops, func(i, j int) bool {
+		return sorted_stops[i].Latitude < sorted_stops[j].Latitude
+	})
+	maximum_latitude_delta := maximum_distance_in_meters / metersPerDegreeOfLatitude
+
+	transfers := []GtfsTransferStruct[ID]{}
+	for from_index, from_stop := range sorted_stops {
+		for _, to_stop := range sorted_stops[from_index+1:] {
+			if to_stop.Latitude-from_stop.Latitude > maximum_latitude_delta {
+				break
+			}
+			if to_stop.UniqueStopID == from_stop.UniqueStopID {
+				continue
+			}
+			distance := GetHaversineDistanceInMeters(from_stop.Latitude, from_stop.Longitude, to_stop.Latitude, to_stop.Longitude)
+			if distance > maximum_distance_in_meters {
+				continue
+			}
+			transfer_time := getTravelTimeInSeconds(distance, speed_in_meters_per_second)
+			transfers = append(transfers,
+				GtfsTransferStruct[ID]{FromUniqueStopID: from_stop.UniqueStopID, ToUniqueStopID: to_stop.UniqueStopID, MinimumTransferTimeInSeconds: transfer_time},
+				GtfsTransferStruct[ID]{FromUniqueStopID: to_stop.UniqueStopID, ToUniqueStopID: from_stop.UniqueStopID, MinimumTransferTimeInSeconds: transfer_time},
+			)
+		}
+	}
+	return transfers
+}
+
+/**
+ * calculates the durations from a coordinate to all the stops within the maximum distance - these can be used as either the
+ * AccessDurationsInSecondsByUniqueStopId or the EgressDurationsInSecondsByUniqueStopId of the input. only the stops with coordinates are used
+ */
+func GetDurationsToStopsFromCoordinates[ID UniqueGtfsIdLike, StopType GtfsStop[ID]](
+	latitude float64,
+	longitude float64,
+	stops []StopType,
+	maximum_distance_in_meters float64,
+	speed_in_meters_per_second float64,
+) map[ID]int {
+	durations_by_unique_stop_id := map[ID]int{}
+	for _, stop := range getStopCoordinates[ID](stops) {
+		distance := GetHaversineDistanceInMeters(latitude, longitude, stop.Latitude, stop.Longitude)
+		if distance <= maximum_distance_in_meters {
+			durations_by_unique_stop_id[stop.UniqueStopID] = getTravelTimeInSeconds(distance, speed_in_meters_per_second)
+		}
+	}
+	return durations_by_unique_stop_id
+}
+
+func getTravelTimeInSeconds(distance_in_meters float64, speed_in_meters_per_second float64) int {
+	return int(math.Ceil(distance_in_meters / speed_in_meters_per_second))
+}
+
+/** the speed to use for transfers and access and egress durations generated from coordinates - cycling when using the bike profile */
+func (input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType]) GetTransferSpeedInMetersPerSecond() float64 {
+	if !input.BikeProfile.Enabled {
+		return DefaultWalkingSpeedInMetersPerSecond
+	}
+	if input.BikeProfile.CyclingSpeedInMetersPerSecond > 0 {
+		return input.BikeProfile.CyclingSpeedInMetersPerSecond
+	}
+	return DefaultCyclingSpeedInMetersPerSecond
+}
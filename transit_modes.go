@@ -0,0 +1,81 @@
+package go_raptor
+
+/** maps both the basic and the extended GTFS route types onto a transit mode */
+func GetTransitModeForRouteType(route_type GtfsRouteType) TransitMode {
+	switch route_type {
+	case GtfsRouteTypeTram:
+		return TransitModeTram
+	case GtfsRouteTypeSubway:
+		return TransitModeSubway
+	case GtfsRouteTypeRail:
+		return TransitModeRail
+	case GtfsRouteTypeBus:
+		return TransitModeBus
+	case GtfsRouteTypeFerry:
+		return TransitModeFerry
+	case GtfsRouteTypeCableTram:
+		return TransitModeCableTram
+	case GtfsRouteTypeAerialLift:
+		return TransitModeAerialLift
+	case GtfsRouteTypeFunicular:
+		return TransitModeFunicular
+	case GtfsRouteTypeTrolleybus:
+		return TransitModeTrolleybus
+	case GtfsRouteTypeMonorail:
+		return TransitModeMonorail
+	}
+
+	/* the extended route types are grouped by hundreds - https://developers.google.com/transit/gtfs/reference/extended-route-types */
+	switch {
+	case route_type == 405:
+		return TransitModeMonorail
+	case route_type >= 100 && route_type < 200, route_type >= 300 && route_type < 400:
+		return TransitModeRail
+	case route_type >= 200 && route_type < 300, route_type >= 700 && route_type < 800:
+		return TransitModeBus
+	case route_type >= 400 && route_type < 700:
+		return TransitModeSubway
+	case route_type >= 800 && route_type < 900:
+		return TransitModeTrolleybus
+	case route_type >= 900 && route_type < 1000:
+		return TransitModeTram
+	case route_type >= 1000 && route_type < 1100, route_type >= 1200 && route_type < 1300:
+		return TransitModeFerry
+	case route_type >= 1100 && route_type < 1200:
+		return TransitModeAir
+	case route_type >= 1300 && route_type < 1400:
+		return TransitModeAerialLift
+	case route_type >= 1400 && route_type < 1500:
+		return TransitModeFunicular
+	case route_type >= 1500 && route_type < 1600:
+		return TransitModeTaxi
+	}
+	return TransitModeOther
+}
+
+/** gets the transit mode of a trip - unknown if the trip or route lookups are not available */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) GetTripTransitMode(unique_trip_id ID) TransitMode {
+	trip, has_trip := prepared_input.Input.TripsByUniqueTripId[unique_trip_id]
+	if !has_trip {
+		return TransitModeUnknown
+	}
+	route, has_route := prepared_input.Input.RoutesByUniqueRouteId[trip.GetUniqueRouteID()]
+	if !has_route {
+		return TransitModeUnknown
+	}
+	return GetTransitModeForRouteType(route.GetRouteType())
+}
+
+/** sets the mode of every leg of the journeys - this is done after the search to avoid the lookups for legs which are never used */
+func (prepared_input *PreparedRaptorInput[ID, StopType, TransferType, StopTimeType]) annotateJourneyModes(journeys []Journey[ID]) []Journey[ID] {
+	for _, journey := range journeys {
+		for index := range journey.Legs {
+			if journey.Legs[index].ViaTrip == nil {
+				journey.Legs[index].Mode = TransitModeWalk
+			} else {
+				journey.Legs[index].Mode = prepared_input.GetTripTransitMode(journey.Legs[index].ViaTrip.UniqueTripID)
+			}
+		}
+	}
+	return journeys
+}
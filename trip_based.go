@@ -0,0 +1,565 @@
+package go_raptor
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+/**
+ * below is a Trip-Based public transit routing implementation (Witt, 2015)
+ * the timetable is preprocessed into lines (trips with the same stops which do not overtake each other) and
+ * a set of transfers between stop events of trips - which is then reduced by removing u-turn transfers and
+ * any transfer which never leads to an improved arrival. the query itself is a breadth first search over trip segments
+ * where every round corresponds to one additional trip - just like the raptor rounds
+ */
+
+type TripBasedTrip[ID UniqueGtfsIdLike] struct {
+	UniqueTripServiceID ID
+	LineIndex           int
+	/* position of the trip in the line - trips later in the line never depart earlier at any stop */
+	PositionInLine int
+	/* indexes of the stop times in the input StopTimes ordered by stop sequence */
+	StopTimeIndexes []int
+}
+
+type TripBasedLine[ID UniqueGtfsIdLike] struct {
+	UniqueStopIDs []ID
+	/* trip indexes ordered by departure */
+	TripIndexes []int
+}
+
+type TripBasedLineStop struct {
+	LineIndex int
+	StopIndex int
+}
+
+type TripBasedTransfer struct {
+	ToTripIndex          int
+	ToStopIndex          int
+	WalkingTimeInSeconds int
+	/* the index of the walked transfer in the input Transfers - -1 when staying at the same stop */
+	TransferIndex int
+}
+
+/**
+ * the preprocessed trip based transfer set - this is only valid for the exact StopTimes and Transfers (in the same order) it was prepared with
+ * it can be stored using Encode and loaded using DecodeTripBasedTransfers to skip the preprocessing at start up
+ */
+type TripBasedTransfers[ID UniqueGtfsIdLike] struct {
+	Trips               []TripBasedTrip[ID]
+	Lines               []TripBasedLine[ID]
+	LineStopsByUniqueId map[ID][]TripBasedLineStop
+	/* the transfers for each trip by stop index in the trip */
+	TransfersByTripIndex [][][]TripBasedTransfer
+}
+
+func PrepareTripBasedTransfers[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	prepared_input PreparedRaptorInput[ID, StopType, TransferType, StopTimeType],
+) TripBasedTransfers[ID] {
+	stop_times := prepared_input.Input.StopTimes
+
+	/* group the trips by their stop pattern */
+	trip_indexes_by_pattern := map[string][]int{}
+	patterns := []string{}
+	trips := make([]TripBasedTrip[ID], 0, len(prepared_input.StopTimesByUniqueTripServiceId))
+	/* the trips are added in the order of their IDs so the same input always gives the same (encoded) transfer set */
+	for _, unique_trip_service_id := range getSortedKeys(prepared_input.StopTimesByUniqueTripServiceId) {
+		stop_time_indexes := prepared_input.StopTimesByUniqueTripServiceId[unique_trip_service_id]
+		if len(stop_time_indexes) < 2 {
+			/* a trip with a single stop can not be used to travel anywhere */
+			continue
+		}
+		pattern_parts := make([]string, len(stop_time_indexes))
+		for index, stop_time_index := range stop_time_indexes {
+			pattern_parts[index] = fmt.Sprintf("%v", stop_times[stop_time_index].GetUniqueStopID())
+		}
+		pattern := strings.Join(pattern_parts, "|")
+		if _, has_pattern := trip_indexes_by_pattern[pattern]; !has_pattern {
+			patterns = append(patterns, pattern)
+		}
+		trip_indexes_by_pattern[pattern] = append(trip_indexes_by_pattern[pattern], len(trips))
+		trips = append(trips, TripBasedTrip[ID]{
+			UniqueTripServiceID: unique_trip_service_id,
+			StopTimeIndexes:     stop_time_indexes,
+		})
+	}
+	sort.Strings(patterns)
+
+	/* split every pattern into lines in which no trip overtakes another */
+	lines := []TripBasedLine[ID]{}
+	for _, pattern := range patterns {
+		pattern_trip_indexes := trip_indexes_by_pattern[pattern]
+		sort.SliceStable(pattern_trip_indexes, func(i, j int) bool {
+			trip, other_trip := trips[pattern_trip_indexes[i]], trips[pattern_trip_indexes[j]]
+			departure_time, other_departure_time := stop_times[trip.StopTimeIndexes[0]].GetDepartureTimeInSeconds(), stop_times[other_trip.StopTimeIndexes[0]].GetDepartureTimeInSeconds()
+			if departure_time != other_departure_time {
+				return departure_time < other_departure_time
+			}
+			return trip.UniqueTripServiceID < other_trip.UniqueTripServiceID
+		})
+		pattern_line_indexes := []int{}
+		for _, trip_index := range pattern_trip_indexes {
+			line_index := -1
+			for _, pattern_line_index := range pattern_line_indexes {
+				line_trip_indexes := lines[pattern_line_index].TripIndexes
+				if !tripBasedOvertakes(stop_times, trips[line_trip_indexes[len(line_trip_indexes)-1]], trips[trip_index]) {
+					line_index = pattern_line_index
+					break
+				}
+			}
+			if line_index == -1 {
+				line_index = len(lines)
+				pattern_line_indexes = append(pattern_line_indexes, line_index)
+				unique_stop_ids := make([]ID, len(trips[trip_index].StopTimeIndexes))
+				for index, stop_time_index := range trips[trip_index].StopTimeIndexes {
+					unique_stop_ids[index] = stop_times[stop_time_index].GetUniqueStopID()
+				}
+				lines = append(lines, TripBasedLine[ID]{UniqueStopIDs: unique_stop_ids})
+			}
+			trips[trip_index].LineIndex = line_index
+			trips[trip_index].PositionInLine = len(lines[line_index].TripIndexes)
+			lines[line_index].TripIndexes = append(lines[line_index].TripIndexes, trip_index)
+		}
+	}
+
+	trip_based_transfers := TripBasedTransfers[ID]{
+		Trips:                trips,
+		Lines:                lines,
+		LineStopsByUniqueId:  getTripBasedLineStops(lines),
+		TransfersByTripIndex: make([][][]TripBasedTransfer, len(trips)),
+	}
+
+	for trip_index, trip := range trips {
+		transfers_by_stop_index := make([][]TripBasedTransfer, len(trip.StopTimeIndexes))
+		/* there is no point in transferring at the first stop of a trip */
+		for stop_index := 1; stop_index < len(trip.StopTimeIndexes); stop_index++ {
+			arrival_stop_time := stop_times[trip.StopTimeIndexes[stop_index]]
+			for _, footpath := range getTripBasedFootpaths(prepared_input, arrival_stop_time.GetUniqueStopID()) {
+				earliest_departure_time := arrival_stop_time.GetArrivalTimeInSeconds() + int64(footpath.WalkingTimeInSeconds)
+				for _, line_stop := range trip_based_transfers.LineStopsByUniqueId[footpath.ToUniqueStopID] {
+					/* there is no point in boarding at the last stop of a line */
+					if line_stop.StopIndex == len(lines[line_stop.LineIndex].UniqueStopIDs)-1 {
+						continue
+					}
+					to_trip_index, has_to_trip := getTripBasedEarliestTrip(&trip_based_transfers, stop_times, line_stop.LineIndex, line_stop.StopIndex, earliest_departure_time)
+					if !has_to_trip {
+						continue
+					}
+					to_trip := trips[to_trip_index]
+					/* staying seated is always at least as good as transferring to the same (or a later) trip of the same line further down */
+					if line_stop.LineIndex == trip.LineIndex && line_stop.StopIndex >= stop_index && to_trip.PositionInLine >= trip.PositionInLine {
+						continue
+					}
+					/* u-turn transfers can be replaced by transferring one stop earlier */
+					if line_stop.StopIndex+1 < len(to_trip.StopTimeIndexes) {
+						previous_stop_time := stop_times[trip.StopTimeIndexes[stop_index-1]]
+						next_stop_time := stop_times[to_trip.StopTimeIndexes[line_stop.StopIndex+1]]
+						if previous_stop_time.GetUniqueStopID() == next_stop_time.GetUniqueStopID() && previous_stop_time.GetArrivalTimeInSeconds() <= next_stop_time.GetDepartureTimeInSeconds() {
+							continue
+						}
+					}
+					transfers_by_stop_index[stop_index] = append(transfers_by_stop_index[stop_index], TripBasedTransfer{
+						ToTripIndex:          to_trip_index,
+						ToStopIndex:          line_stop.StopIndex,
+						WalkingTimeInSeconds: footpath.WalkingTimeInSeconds,
+						TransferIndex:        footpath.TransferIndex,
+					})
+				}
+			}
+		}
+		trip_based_transfers.TransfersByTripIndex[trip_index] = transfers_by_stop_index
+	}
+
+	reduceTripBasedTransfers(&trip_based_transfers, prepared_input)
+
+	return trip_based_transfers
+}
+
+/**
+ * removes every transfer which does not improve the arrival time at any stop compared to
+ * staying seated or any transfer at a later stop of the same trip
+ */
+func reduceTripBasedTransfers[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	tb *TripBasedTransfers[ID],
+	prepared_input PreparedRaptorInput[ID, StopType, TransferType, StopTimeType],
+) {
+	stop_times := prepared_input.Input.StopTimes
+	for trip_index, trip := range tb.Trips {
+		earliest_arrival_time_by_unique_stop_id := map[ID]TimestampInSeconds{}
+		improve := func(unique_stop_id ID, arrival_time TimestampInSeconds) bool {
+			if existing_arrival_time, has_existing_arrival_time := earliest_arrival_time_by_unique_stop_id[unique_stop_id]; has_existing_arrival_time && existing_arrival_time <= arrival_time {
+				return false
+			}
+			earliest_arrival_time_by_unique_stop_id[unique_stop_id] = arrival_time
+			return true
+		}
+		improveWithFootpaths := func(unique_stop_id ID, arrival_time TimestampInSeconds) bool {
+			improved := false
+			for _, footpath := range getTripBasedFootpaths(prepared_input, unique_stop_id) {
+				if improve(footpath.ToUniqueStopID, arrival_time+int64(footpath.WalkingTimeInSeconds)) {
+					improved = true
+				}
+			}
+			return improved
+		}
+
+		for stop_index := len(trip.StopTimeIndexes) - 1; stop_index > 0; stop_index-- {
+			arrival_stop_time := stop_times[trip.StopTimeIndexes[stop_index]]
+			improveWithFootpaths(arrival_stop_time.GetUniqueStopID(), arrival_stop_time.GetArrivalTimeInSeconds())
+
+			/* transfers with shorter walking times are more likely to be useful so they are checked first */
+			transfers := tb.TransfersByTripIndex[trip_index][stop_index]
+			sort.SliceStable(transfers, func(i, j int) bool {
+				return transfers[i].WalkingTimeInSeconds < transfers[j].WalkingTimeInSeconds
+			})
+			kept_transfers := transfers[:0]
+			for _, transfer := range transfers {
+				is_useful := false
+				to_trip := tb.Trips[transfer.ToTripIndex]
+				for to_stop_index := transfer.ToStopIndex + 1; to_stop_index < len(to_trip.StopTimeIndexes); to_stop_index++ {
+					to_stop_time := stop_times[to_trip.StopTimeIndexes[to_stop_index]]
+					if improveWithFootpaths(to_stop_time.GetUniqueStopID(), to_stop_time.GetArrivalTimeInSeconds()) {
+						is_useful = true
+					}
+				}
+				if is_useful {
+					kept_transfers = append(kept_transfers, transfer)
+				}
+			}
+			tb.TransfersByTripIndex[trip_index][stop_index] = kept_transfers
+		}
+	}
+}
+
+type tripBasedFootpath[ID UniqueGtfsIdLike] struct {
+	ToUniqueStopID       ID
+	WalkingTimeInSeconds int
+	TransferIndex        int
+}
+
+/** the footpaths from a stop - always including staying at the stop itself */
+func getTripBasedFootpaths[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	prepared_input PreparedRaptorInput[ID, StopType, TransferType, StopTimeType],
+	unique_stop_id ID,
+) []tripBasedFootpath[ID] {
+	footpaths := []tripBasedFootpath[ID]{{ToUniqueStopID: unique_stop_id, WalkingTimeInSeconds: 0, TransferIndex: -1}}
+	for _, transfer_index := range prepared_input.TransfersByUniqueStopId[unique_stop_id] {
+		transfer := prepared_input.Input.Transfers[transfer_index]
+		if transfer.GetToUniqueStopID() == unique_stop_id {
+			continue
+		}
+		footpaths = append(footpaths, tripBasedFootpath[ID]{
+			ToUniqueStopID:       transfer.GetToUniqueStopID(),
+			WalkingTimeInSeconds: transfer.GetMinimumTransferTimeInSeconds(),
+			TransferIndex:        transfer_index,
+		})
+	}
+	return footpaths
+}
+
+/** finds the earliest trip of the line which departs from the stop index at or after the given time */
+func getTripBasedEarliestTrip[ID UniqueGtfsIdLike, StopTimeType GtfsStopTime[ID]](tb *TripBasedTransfers[ID], stop_times []StopTimeType, line_index int, stop_index int, earliest_departure_time TimestampInSeconds) (int, bool) {
+	line_trip_indexes := tb.Lines[line_index].TripIndexes
+	position := sort.Search(len(line_trip_indexes), func(i int) bool {
+		return stop_times[tb.Trips[line_trip_indexes[i]].StopTimeIndexes[stop_index]].GetDepartureTimeInSeconds() >= earliest_departure_time
+	})
+	if position == len(line_trip_indexes) {
+		return 0, false
+	}
+	return line_trip_indexes[position], true
+}
+
+/** checks whether the next trip would overtake (or be overtaken by) the previous trip of the line */
+func tripBasedOvertakes[ID UniqueGtfsIdLike, StopTimeType GtfsStopTime[ID]](stop_times []StopTimeType, previous_trip TripBasedTrip[ID], next_trip TripBasedTrip[ID]) bool {
+	for index := range previous_trip.StopTimeIndexes {
+		previous_stop_time := stop_times[previous_trip.StopTimeIndexes[index]]
+		next_stop_time := stop_times[next_trip.StopTimeIndexes[index]]
+		if next_stop_time.GetArrivalTimeInSeconds() < previous_stop_time.GetArrivalTimeInSeconds() || next_stop_time.GetDepartureTimeInSeconds() < previous_stop_time.GetDepartureTimeInSeconds() {
+			return true
+		}
+	}
+	return false
+}
+
+/** the stops of the lines by their unique stop ID */
+func getTripBasedLineStops[ID UniqueGtfsIdLike](lines []TripBasedLine[ID]) map[ID][]TripBasedLineStop {
+	line_stops_by_unique_stop_id := map[ID][]TripBasedLineStop{}
+	for line_index, line := range lines {
+		for stop_index, unique_stop_id := range line.UniqueStopIDs {
+			line_stops_by_unique_stop_id[unique_stop_id] = append(line_stops_by_unique_stop_id[unique_stop_id], TripBasedLineStop{LineIndex: line_index, StopIndex: stop_index})
+		}
+	}
+	return line_stops_by_unique_stop_id
+}
+
+/** the stored transfer set - without the line stops lookup since gob writes maps in a random order and it can be derived from the lines */
+type encodedTripBasedTransfers[ID UniqueGtfsIdLike] struct {
+	Trips                []TripBasedTrip[ID]
+	Lines                []TripBasedLine[ID]
+	TransfersByTripIndex [][][]TripBasedTransfer
+}
+
+/** stores the transfer set so it can be loaded with DecodeTripBasedTransfers - the same transfer set is always stored as the same bytes */
+func (tb TripBasedTransfers[ID]) Encode(writer io.Writer) error {
+	return gob.NewEncoder(writer).Encode(encodedTripBasedTransfers[ID]{
+		Trips:                tb.Trips,
+		Lines:                tb.Lines,
+		TransfersByTripIndex: tb.TransfersByTripIndex,
+	})
+}
+
+func DecodeTripBasedTransfers[ID UniqueGtfsIdLike](reader io.Reader) (TripBasedTransfers[ID], error) {
+	var encoded encodedTripBasedTransfers[ID]
+	if err := gob.NewDecoder(reader).Decode(&encoded); err != nil {
+		return TripBasedTransfers[ID]{}, err
+	}
+	return TripBasedTransfers[ID]{
+		Trips:                encoded.Trips,
+		Lines:                encoded.Lines,
+		LineStopsByUniqueId:  getTripBasedLineStops(encoded.Lines),
+		TransfersByTripIndex: encoded.TransfersByTripIndex,
+	}, nil
+}
+
+type tripBasedQueueEntry struct {
+	TripIndex int
+	/* the stop index at which the trip was boarded */
+	FromStopIndex int
+	/* the (exclusive) stop index up to which this entry needs to be scanned - the rest was already reached earlier */
+	ToStopIndex int
+	/* the queue entry we transferred from and the stop index we alighted it at (-1 for the first trip) */
+	ParentEntryIndex     int
+	ParentStopIndex      int
+	WalkingTimeInSeconds int
+	/* the index of the transfer walked from the parent entry - -1 when staying at the same stop */
+	TransferIndex int
+}
+
+func SimpleTripBasedDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) []Journey[ID] {
+	prepared_input := PrepareRaptorInput(input)
+	tb := input.TripBasedTransfers
+	if tb == nil {
+		prepared_transfers := PrepareTripBasedTransfers(prepared_input)
+		tb = &prepared_transfers
+	}
+	stop_times := prepared_input.Input.StopTimes
+	stats_recorder := newRaptorStatsRecorder(input.Context, input.Observer, RaptorEngineTripBased, RaptorModeDepartAt)
+
+	/* the lines which pass through a destination - and at which stop indexes */
+	target_stop_indexes_by_line_index := map[int][]int{}
+	for _, unique_stop_id := range getSortedKeys(prepared_input.ToStopsByUniqueStopId) {
+		if !prepared_input.IsStopAllowed(unique_stop_id) {
+			continue
+		}
+		for _, line_stop := range tb.LineStopsByUniqueId[unique_stop_id] {
+			target_stop_indexes_by_line_index[line_stop.LineIndex] = append(target_stop_indexes_by_line_index[line_stop.LineIndex], line_stop.StopIndex)
+		}
+	}
+
+	/* the first stop index at which each trip was reached - every trip later in the same line is reached at least as early */
+	first_reached_stop_index_by_trip_index := make([]int, len(tb.Trips))
+	for trip_index, trip := range tb.Trips {
+		first_reached_stop_index_by_trip_index[trip_index] = len(trip.StopTimeIndexes)
+	}
+	queue := []tripBasedQueueEntry{}
+	enqueue := func(trip_index int, stop_index int, parent_entry_index int, parent_stop_index int, walking_time int, transfer_index int) {
+		/* banned trips are skipped in favour of the next trip of the line */
+		line := tb.Lines[tb.Trips[trip_index].LineIndex]
+		if !prepared_input.IsStopAllowed(line.UniqueStopIDs[stop_index]) {
+			return
+		}
+		for !prepared_input.IsTripAllowed(stop_times[tb.Trips[trip_index].StopTimeIndexes[stop_index]]) {
+			next_position := tb.Trips[trip_index].PositionInLine + 1
+			if next_position == len(line.TripIndexes) {
+				return
+			}
+			trip_index = line.TripIndexes[next_position]
+		}
+		if stop_index >= first_reached_stop_index_by_trip_index[trip_index] {
+			return
+		}
+		/* the later trips of the line depart even later so none of them can be boarded either */
+		if input.StopTimeCutOffTimestamp != 0 && stop_times[tb.Trips[trip_index].StopTimeIndexes[stop_index]].GetDepartureTimeInSeconds() > input.StopTimeCutOffTimestamp {
+			return
+		}
+		queue = append(queue, tripBasedQueueEntry{
+			TripIndex:            trip_index,
+			FromStopIndex:        stop_index,
+			ToStopIndex:          first_reached_stop_index_by_trip_index[trip_index],
+			ParentEntryIndex:     parent_entry_index,
+			ParentStopIndex:      parent_stop_index,
+			WalkingTimeInSeconds: walking_time,
+			TransferIndex:        transfer_index,
+		})
+		stats_recorder.round.LabelsImproved++
+		for _, later_trip_index := range line.TripIndexes[tb.Trips[trip_index].PositionInLine:] {
+			if first_reached_stop_index_by_trip_index[later_trip_index] <= stop_index {
+				break
+			}
+			first_reached_stop_index_by_trip_index[later_trip_index] = stop_index
+		}
+	}
+
+	/* we start by boarding the earliest trip of every line at the from stops */
+	for _, unique_stop_id := range getSortedKeys(prepared_input.FromStopsByUniqueStopId) {
+		for _, line_stop := range tb.LineStopsByUniqueId[unique_stop_id] {
+			if line_stop.StopIndex == len(tb.Lines[line_stop.LineIndex].UniqueStopIDs)-1 {
+				continue
+			}
+			trip_index, has_trip := getTripBasedEarliestTrip(tb, stop_times, line_stop.LineIndex, line_stop.StopIndex, input.TimeInSeconds+prepared_input.GetAccessDurationInSeconds(unique_stop_id))
+			if has_trip {
+				enqueue(trip_index, line_stop.StopIndex, -1, -1, 0, -1)
+			}
+		}
+	}
+
+	potential_journeys_found := []Journey[ID]{}
+	best_arrival_time := TimestampInSeconds(math.MaxInt64)
+	round_start_index := 0
+	for range input.MaximumTransfers {
+		round_end_index := len(queue)
+		if round_start_index == round_end_index {
+			break
+		}
+		/* every queued trip segment is scanned once */
+		stats_recorder.startRound(round_end_index - round_start_index)
+		stats_recorder.round.TripsScanned = round_end_index - round_start_index
+
+		/* the best arrival at a destination found in this round */
+		best_entry_index, best_stop_index := -1, -1
+		for entry_index := round_start_index; entry_index < round_end_index; entry_index++ {
+			entry := queue[entry_index]
+			trip := tb.Trips[entry.TripIndex]
+			for _, target_stop_index := range target_stop_indexes_by_line_index[trip.LineIndex] {
+				if target_stop_index <= entry.FromStopIndex || target_stop_index >= entry.ToStopIndex {
+					continue
+				}
+				/* the egress is included so the best destination is picked when there are multiple */
+				arrival_time := stop_times[trip.StopTimeIndexes[target_stop_index]].GetArrivalTimeInSeconds() + prepared_input.GetEgressDurationInSeconds(tb.Lines[trip.LineIndex].UniqueStopIDs[target_stop_index])
+				if arrival_time < best_arrival_time {
+					best_arrival_time = arrival_time
+					best_entry_index, best_stop_index = entry_index, target_stop_index
+				}
+			}
+		}
+		if best_entry_index != -1 {
+			potential_journeys_found = append(potential_journeys_found, buildTripBasedJourney(tb, stop_times, queue, best_entry_index, best_stop_index))
+		}
+
+		/* follow the transfers of every stop which is reached before the best known arrival */
+		for entry_index := round_start_index; entry_index < round_end_index; entry_index++ {
+			entry := queue[entry_index]
+			trip := tb.Trips[entry.TripIndex]
+			for stop_index := entry.FromStopIndex + 1; stop_index < entry.ToStopIndex; stop_index++ {
+				stats_recorder.round.StopTimesScanned++
+				if stop_times[trip.StopTimeIndexes[stop_index]].GetArrivalTimeInSeconds() >= best_arrival_time {
+					break
+				}
+				if !prepared_input.IsStopAllowed(tb.Lines[trip.LineIndex].UniqueStopIDs[stop_index]) {
+					continue
+				}
+				for _, transfer := range tb.TransfersByTripIndex[entry.TripIndex][stop_index] {
+					if transfer.TransferIndex != -1 && !prepared_input.IsTransferAllowed(prepared_input.Input.Transfers[transfer.TransferIndex]) {
+						continue
+					}
+					stats_recorder.round.TransfersRelaxed++
+					enqueue(transfer.ToTripIndex, transfer.ToStopIndex, entry_index, stop_index, transfer.WalkingTimeInSeconds, transfer.TransferIndex)
+				}
+			}
+		}
+
+		stats_recorder.endRound()
+		round_start_index = round_end_index
+	}
+
+	journeys := prepared_input.finalizeJourneys(potential_journeys_found, RaptorModeDepartAt)
+	stats_recorder.endQuery(len(journeys))
+	return journeys
+}
+
+/** walks back up the queue entries to build the journey spans */
+func buildTripBasedJourney[ID UniqueGtfsIdLike, StopTimeType GtfsStopTime[ID]](tb *TripBasedTransfers[ID], stop_times []StopTimeType, queue []tripBasedQueueEntry, entry_index int, to_stop_index int) Journey[ID] {
+	spans := []RoundSegmentSpan[ID]{}
+	for entry_index != -1 {
+		entry := queue[entry_index]
+		trip := tb.Trips[entry.TripIndex]
+		from_stop_time := stop_times[trip.StopTimeIndexes[entry.FromStopIndex]]
+		to_stop_time := stop_times[trip.StopTimeIndexes[to_stop_index]]
+		spans = append(spans, RoundSegmentSpan[ID]{
+			FromUniqueStopID: from_stop_time.GetUniqueStopID(),
+			ToUniqueStopID:   to_stop_time.GetUniqueStopID(),
+			ViaTrip: &ViaTrip[ID]{
+				UniqueTripID:           to_stop_time.GetUniqueTripID(),
+				UniqueTripServiceID:    to_stop_time.GetUniqueTripServiceID(),
+				FromStopSequenceInTrip: from_stop_time.GetStopSequence(),
+				ToStopSequenceInTrip:   to_stop_time.GetStopSequence(),
+			},
+			DepartureTimeInSecondsFromUniqueStopID: from_stop_time.GetDepartureTimeInSeconds(),
+			ArrivalTimeInSecondsToUniqueStopID:     to_stop_time.GetArrivalTimeInSeconds(),
+			TransferIndex:                          -1,
+		})
+
+		if entry.ParentEntryIndex != -1 {
+			parent_trip := tb.Trips[queue[entry.ParentEntryIndex].TripIndex]
+			parent_stop_time := stop_times[parent_trip.StopTimeIndexes[entry.ParentStopIndex]]
+			if parent_stop_time.GetUniqueStopID() != from_stop_time.GetUniqueStopID() {
+				spans = append(spans, RoundSegmentSpan[ID]{
+					FromUniqueStopID:                       parent_stop_time.GetUniqueStopID(),
+					ToUniqueStopID:                         from_stop_time.GetUniqueStopID(),
+					ViaTrip:                                nil,
+					DepartureTimeInSecondsFromUniqueStopID: parent_stop_time.GetArrivalTimeInSeconds(),
+					ArrivalTimeInSecondsToUniqueStopID:     parent_stop_time.GetArrivalTimeInSeconds() + int64(entry.WalkingTimeInSeconds),
+					TransferIndex:                          entry.TransferIndex,
+				})
+			}
+		}
+		to_stop_index = entry.ParentStopIndex
+		entry_index = entry.ParentEntryIndex
+	}
+
+	/* the spans were collected from the destination backwards */
+	for i, j := 0, len(spans)-1; i < j; i, j = i+1, j-1 {
+		spans[i], spans[j] = spans[j], spans[i]
+	}
+	return RoundSegment[ID]{Spans: spans}.ToJourney()
+}
+
+/**
+ * the trip based engine only supports depart at queries - arrive by queries are answered by the raptor implementation (and are reported as such
+ * to the observer) so they are not any faster. see BenchmarkSimpleTripBasedDepartAt for the speed up of the depart at queries
+ * the transfer set is reduced without the banned trips, routes, agencies and stops, the mode, accessibility and bike filters and the route preferences
+ * so the queries using any of them are answered by raptor as well - filtering the reduced set could miss the journeys which were only dominated by filtered trips.
+ * the search is always pruned by the best arrival - with PruneByBestArrival the BestArrivalSlackInSeconds only drops the journeys outside of it
+ */
+func SimpleTripBased[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) []Journey[ID] {
+	if input.Mode == RaptorModeArriveBy {
+		return SimpleRaptorArriveBy(input)
+	}
+	if hasTripBasedUnsupportedFilters(input) {
+		return SimpleRaptorDepartAt(input)
+	}
+	return SimpleTripBasedDepartAt(input)
+}
+
+/** whether the input filters or penalizes trips, stops or transfers - which the reduced trip based transfer set does not account for */
+func hasTripBasedUnsupportedFilters[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) bool {
+	return len(input.BannedUniqueTripIds) > 0 ||
+		len(input.BannedUniqueRouteIds) > 0 ||
+		len(input.BannedUniqueAgencyIds) > 0 ||
+		len(input.BannedUniqueStopIds) > 0 ||
+		len(input.PreferredUniqueRouteIds) > 0 ||
+		len(input.UnpreferredUniqueRouteIds) > 0 ||
+		len(input.AllowedTransitModes) > 0 ||
+		input.AccessibilityProfile.Wheelchair ||
+		input.BikeProfile.Enabled
+}
@@ -0,0 +1,73 @@
+package go_raptor_test
+
+import (
+	"testing"
+	"time"
+
+	raptor "github.com/liammartens/go-raptor"
+	"github.com/liammartens/go-raptor/gtfs"
+)
+
+type benchmarkInput = raptor.SimpleRaptorInput[string, gtfs.Stop, gtfs.Transfer, raptor.GtfsStopTimeStruct[string]]
+
+/** the prepared input of the LIRR feed for the 23rd of August 2025 - the preprocessing of the engine is not part of the benchmarks */
+func getBenchmarkInput(b *testing.B, engine raptor.RaptorEngine) benchmarkInput {
+	b.Helper()
+	feed, err := gtfs.LoadFeedZip("gtfslirr.zip")
+	if err != nil {
+		b.Fatal(err)
+	}
+	location, err := feed.GetLocation()
+	if err != nil {
+		b.Fatal(err)
+	}
+	service_date := time.Date(2025, 8, 23, 0, 0, 0, 0, location)
+	timetable, err := feed.BuildTimetable([]time.Time{service_date.AddDate(0, 0, -1), service_date, service_date.AddDate(0, 0, 1)})
+	if err != nil {
+		b.Fatal(err)
+	}
+	input := benchmarkInput{
+		FromStops:        []gtfs.Stop{{StopID: "237"}},
+		ToStops:          []gtfs.Stop{{StopID: "27"}},
+		Transfers:        feed.GetFootpathTransfers(),
+		StopTimes:        timetable.StopTimes,
+		Mode:             raptor.RaptorModeDepartAt,
+		TimeInSeconds:    service_date.Add(8 * time.Hour).Unix(),
+		MaximumTransfers: 4,
+		Engine:           engine,
+	}
+	prepared_input := raptor.PrepareRaptorInput(input)
+	input = input.WithPreparedInput(prepared_input)
+	switch engine {
+	case raptor.RaptorEngineCsa:
+		connections := raptor.PrepareCsaConnections(prepared_input)
+		input.CsaConnections = &connections
+	case raptor.RaptorEngineTripBased:
+		transfers := raptor.PrepareTripBasedTransfers(prepared_input)
+		input.TripBasedTransfers = &transfers
+	}
+	return input
+}
+
+/** searches every 15 minutes of the day so the result does not depend on a single departure */
+func benchmarkDepartAt(b *testing.B, engine raptor.RaptorEngine) {
+	input := getBenchmarkInput(b, engine)
+	start_time := input.TimeInSeconds - 8*3600
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		input.TimeInSeconds = start_time + int64(i%96)*15*60
+		raptor.SimpleRaptor(input)
+	}
+}
+
+func BenchmarkSimpleRaptorDepartAt(b *testing.B) {
+	benchmarkDepartAt(b, raptor.RaptorEngineRaptor)
+}
+
+func BenchmarkSimpleCsaDepartAt(b *testing.B) {
+	benchmarkDepartAt(b, raptor.RaptorEngineCsa)
+}
+
+func BenchmarkSimpleTripBasedDepartAt(b *testing.B) {
+	benchmarkDepartAt(b, raptor.RaptorEngineTripBased)
+}
@@ -0,0 +1,132 @@
+package go_raptor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTripBasedTransfers_EncodeDecode(t *testing.T) {
+	var epoch_20250823_120000_edt int64 = 1755964800
+
+	input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops: []GtfsStopStruct[string]{
+			{UniqueID: "High St"},
+		},
+		ToStops: []GtfsStopStruct[string]{
+			{UniqueID: "Franklin Av"},
+		},
+		Transfers: []GtfsTransferStruct[string]{
+			{FromUniqueStopID: "Jay St", ToUniqueStopID: "Hoyt St", MinimumTransferTimeInSeconds: 0},
+		},
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "High St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 5, ArrivalTimeInSeconds: epoch_20250823_120000_edt - 10, DepartureTimeInSeconds: epoch_20250823_120000_edt + 10},
+			{UniqueStopID: "Jay St", UniqueTripID: "A_20250823", UniqueTripServiceID: "A_20250823", StopSequence: 6, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 120, DepartureTimeInSeconds: epoch_20250823_120000_edt + 130},
+			{UniqueStopID: "Hoyt St", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 8, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 125, DepartureTimeInSeconds: epoch_20250823_120000_edt + 135},
+			{UniqueStopID: "Franklin Av", UniqueTripID: "C_20250823", UniqueTripServiceID: "C_20250823", StopSequence: 9, ArrivalTimeInSeconds: epoch_20250823_120000_edt + 200, DepartureTimeInSeconds: epoch_20250823_120000_edt + 210},
+		},
+		Mode:             RaptorModeDepartAt,
+		TimeInSeconds:    epoch_20250823_120000_edt,
+		MaximumTransfers: 4,
+		Engine:           RaptorEngineTripBased,
+	}
+
+	transfers := PrepareTripBasedTransfers(PrepareRaptorInput(input))
+	buffer := bytes.Buffer{}
+	assert.NoError(t, transfers.Encode(&buffer))
+	encoded := bytes.Clone(buffer.Bytes())
+	decoded_transfers, err := DecodeTripBasedTransfers[string](&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, transfers, decoded_transfers)
+
+	/* the trips are ordered by their IDs rather than by the random order of the lookups */
+	for range 5 {
+		other_buffer := bytes.Buffer{}
+		assert.NoError(t, PrepareTripBasedTransfers(PrepareRaptorInput(input)).Encode(&other_buffer))
+		assert.Equal(t, encoded, other_buffer.Bytes(), "should store the same transfer set as the same bytes")
+	}
+
+	input.TripBasedTransfers = &decoded_transfers
+	journeys := SimpleRaptor(input)
+	assert.Len(t, journeys, 1)
+	assert.Equal(t, epoch_20250823_120000_edt+200, journeys[0].ArrivalTimeInSeconds)
+	assert.Len(t, journeys[0].Legs, 3, "should ride A, walk from Jay St to Hoyt St and ride C")
+}
+
+func TestSimpleTripBased_StopTimeCutOff(t *testing.T) {
+	input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops: []GtfsStopStruct[string]{{UniqueID: "A"}},
+		ToStops:   []GtfsStopStruct[string]{{UniqueID: "C"}},
+		Transfers: []GtfsTransferStruct[string]{},
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "B", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "B", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 1, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+			{UniqueStopID: "C", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 2, ArrivalTimeInSeconds: 1400, DepartureTimeInSeconds: 1400},
+		},
+		Mode:             RaptorModeDepartAt,
+		TimeInSeconds:    900,
+		MaximumTransfers: 4,
+		Engine:           RaptorEngineTripBased,
+	}
+	assert.Len(t, SimpleRaptor(input), 1)
+
+	input.StopTimeCutOffTimestamp = 1200
+	assert.Len(t, SimpleRaptor(input), 0, "should not board T2 departing after the cut off")
+}
+
+func TestSimpleTripBased_Filters(t *testing.T) {
+	/* T1 passes B and C - transferring to T2 at C arrives at D earlier than transferring to T3 at B so the latter is not in the reduced transfer set */
+	base_input := SimpleRaptorInput[string, GtfsStopStruct[string], GtfsTransferStruct[string], GtfsStopTimeStruct[string]]{
+		FromStops: []GtfsStopStruct[string]{{UniqueID: "A"}},
+		ToStops:   []GtfsStopStruct[string]{{UniqueID: "D"}},
+		Transfers: []GtfsTransferStruct[string]{},
+		StopTimes: []GtfsStopTimeStruct[string]{
+			{UniqueStopID: "A", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 1, ArrivalTimeInSeconds: 1000, DepartureTimeInSeconds: 1000},
+			{UniqueStopID: "B", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 2, ArrivalTimeInSeconds: 1100, DepartureTimeInSeconds: 1100},
+			{UniqueStopID: "B", UniqueTripID: "T3", UniqueTripServiceID: "T3", StopSequence: 1, ArrivalTimeInSeconds: 1110, DepartureTimeInSeconds: 1110},
+			{UniqueStopID: "C", UniqueTripID: "T1", UniqueTripServiceID: "T1", StopSequence: 3, ArrivalTimeInSeconds: 1200, DepartureTimeInSeconds: 1200},
+			{UniqueStopID: "C", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 1, ArrivalTimeInSeconds: 1210, DepartureTimeInSeconds: 1210},
+			{UniqueStopID: "D", UniqueTripID: "T2", UniqueTripServiceID: "T2", StopSequence: 2, ArrivalTimeInSeconds: 1300, DepartureTimeInSeconds: 1300},
+			{UniqueStopID: "D", UniqueTripID: "T3", UniqueTripServiceID: "T3", StopSequence: 2, ArrivalTimeInSeconds: 1400, DepartureTimeInSeconds: 1400},
+		},
+		TripsByUniqueTripId: map[string]GtfsTrip[string]{
+			"T1": GtfsTripStruct[string]{UniqueID: "T1", UniqueRouteID: "R1"},
+			"T2": GtfsTripStruct[string]{UniqueID: "T2", UniqueRouteID: "R2"},
+			"T3": GtfsTripStruct[string]{UniqueID: "T3", UniqueRouteID: "R3"},
+		},
+		RoutesByUniqueRouteId: map[string]GtfsRoute[string]{
+			"R1": GtfsRouteStruct[string]{UniqueID: "R1"},
+			"R2": GtfsRouteStruct[string]{UniqueID: "R2"},
+			"R3": GtfsRouteStruct[string]{UniqueID: "R3"},
+		},
+		Mode:             RaptorModeDepartAt,
+		TimeInSeconds:    900,
+		MaximumTransfers: 4,
+	}
+
+	for _, engine := range []RaptorEngine{RaptorEngineRaptor, RaptorEngineCsa, RaptorEngineTripBased} {
+		input := base_input
+		input.Engine = engine
+		journeys := SimpleRaptor(input)
+		assert.Len(t, journeys, 1, engine)
+		assert.Equal(t, TimestampInSeconds(1300), journeys[0].ArrivalTimeInSeconds, engine)
+
+		input.BannedUniqueTripIds = []string{"T2"}
+		journeys = SimpleRaptor(input)
+		assert.Len(t, journeys, 1, "%s should transfer to T3 when T2 is banned", engine)
+		if len(journeys) == 1 {
+			assert.Equal(t, "T3", journeys[0].Legs[1].ViaTrip.UniqueTripID, engine)
+		}
+
+		input.BannedUniqueTripIds = nil
+		input.UnpreferredUniqueRouteIds = []string{"R2"}
+		input.RoutePreferencePenaltyInSeconds = 200
+		journeys = SimpleRaptor(input)
+		assert.Len(t, journeys, 1, engine)
+		if len(journeys) == 1 {
+			assert.Equal(t, "T3", journeys[0].Legs[1].ViaTrip.UniqueTripID, "%s should avoid the unpreferred route", engine)
+		}
+	}
+}
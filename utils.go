@@ -1,5 +1,7 @@
 package go_raptor
 
+import "math"
+
 func GetTimePartition(timestamp TimestampInSeconds, interval TimestampInSeconds, upper bool) TimestampInSeconds {
 	lower := timestamp - (timestamp % interval)
 	if !upper || lower == timestamp {
@@ -7,3 +9,109 @@ func GetTimePartition(timestamp TimestampInSeconds, interval TimestampInSeconds,
 	}
 	return ((lower / interval) + 1) * interval
 }
+
+const earthRadiusInMeters = 6371000.0
+
+/** the great circle distance between two WGS84 coordinates */
+func GetHaversineDistanceInMeters(from_latitude float64, from_longitude float64, to_latitude float64, to_longitude float64) float64 {
+	delta_latitude := (to_latitude - from_latitude) * math.Pi / 180
+	delta_longitude := (to_longitude - from_longitude) * math.Pi / 180
+	a := math.Sin(delta_latitude/2)*math.Sin(delta_latitude/2) +
+		math.Cos(from_latitude*math.Pi/180)*math.Cos(to_latitude*math.Pi/180)*math.Sin(delta_longitude/2)*math.Sin(delta_longitude/2)
+	return 2 * earthRadiusInMeters * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+/* the FNV-1a offset basis and prime used for the fingerprints */
+const (
+	fingerPrintOffsetBasis uint64 = 14695981039346656037
+	fingerPrintPrime       uint64 = 1099511628211
+)
+
+/** hashes the from stop, trip and to stop of every span - the trip is left out for walking transfers */
+func getSpansFingerPrint[ID UniqueGtfsIdLike](spans []RoundSegmentSpan[ID]) uint64 {
+	fingerprint := fingerPrintOffsetBasis
+	for _, span := range spans {
+		fingerprint = addIdToFingerPrint(fingerprint, span.FromUniqueStopID)
+		if span.ViaTrip != nil {
+			fingerprint = addToFingerPrint(fingerprint, 1)
+			fingerprint = addIdToFingerPrint(fingerprint, span.ViaTrip.UniqueTripID)
+		} else {
+			fingerprint = addToFingerPrint(fingerprint, 0)
+		}
+		fingerprint = addIdToFingerPrint(fingerprint, span.ToUniqueStopID)
+	}
+	return fingerprint
+}
+
+/** whether the spans take the same trips between the same stops - which is what the fingerprint is a hash of */
+func hasSameSpans[ID UniqueGtfsIdLike](spans []RoundSegmentSpan[ID], other_spans []RoundSegmentSpan[ID]) bool {
+	if len(spans) != len(other_spans) {
+		return false
+	}
+	for index, span := range spans {
+		other_span := other_spans[index]
+		if span.FromUniqueStopID != other_span.FromUniqueStopID || span.ToUniqueStopID != other_span.ToUniqueStopID || (span.ViaTrip == nil) != (other_span.ViaTrip == nil) {
+			return false
+		}
+		if span.ViaTrip != nil && span.ViaTrip.UniqueTripID != other_span.ViaTrip.UniqueTripID {
+			return false
+		}
+	}
+	return true
+}
+
+/** a set of spans keyed by their fingerprint - the spans with the same fingerprint are compared so a collision does not drop a journey */
+type journeySpansSet[ID UniqueGtfsIdLike] struct {
+	spans_by_fingerprint map[uint64][][]RoundSegmentSpan[ID]
+}
+
+func newJourneySpansSet[ID UniqueGtfsIdLike]() journeySpansSet[ID] {
+	return journeySpansSet[ID]{spans_by_fingerprint: map[uint64][][]RoundSegmentSpan[ID]{}}
+}
+
+func (set journeySpansSet[ID]) Has(spans []RoundSegmentSpan[ID]) bool {
+	for _, existing_spans := range set.spans_by_fingerprint[getSpansFingerPrint(spans)] {
+		if hasSameSpans(existing_spans, spans) {
+			return true
+		}
+	}
+	return false
+}
+
+/** adds the spans - returns false when the same spans were added before */
+func (set journeySpansSet[ID]) Add(spans []RoundSegmentSpan[ID]) bool {
+	if set.Has(spans) {
+		return false
+	}
+	fingerprint := getSpansFingerPrint(spans)
+	set.spans_by_fingerprint[fingerprint] = append(set.spans_by_fingerprint[fingerprint], spans)
+	return true
+}
+
+/** strings are prefixed with their length so that the boundaries between the IDs are part of the fingerprint */
+func addIdToFingerPrint[ID UniqueGtfsIdLike](fingerprint uint64, id ID) uint64 {
+	switch value := any(id).(type) {
+	case string:
+		fingerprint = addToFingerPrint(fingerprint, uint64(len(value)))
+		for index := 0; index < len(value); index++ {
+			fingerprint = (fingerprint ^ uint64(value[index])) * fingerPrintPrime
+		}
+		return fingerprint
+	case uint32:
+		return addToFingerPrint(fingerprint, uint64(value))
+	case uint64:
+		return addToFingerPrint(fingerprint, value)
+	case int32:
+		return addToFingerPrint(fingerprint, uint64(value))
+	case int64:
+		return addToFingerPrint(fingerprint, uint64(value))
+	}
+	return fingerprint
+}
+
+func addToFingerPrint(fingerprint uint64, value uint64) uint64 {
+	for shift := 0; shift < 64; shift += 8 {
+		fingerprint = (fingerprint ^ (value >> shift & 0xff)) * fingerPrintPrime
+	}
+	return fingerprint
+}
@@ -0,0 +1,267 @@
+package go_raptor
+
+import "slices"
+
+/**
+ * below are the via stop implementations which chain multiple searches (one for every part of the journey)
+ * every part is seeded with all the labels of the previous part which are pareto optimal on their time at the via stop and the trips they used
+ * (with the minimum dwell time applied) after which the parts are stitched together into a single journey. the MaximumTransfers are shared by all the parts
+ * the access durations only apply to the first part and the egress durations to the last part. the ranking is applied to the stitched journeys
+ */
+
+/** a journey through a part of the via stops - the times include the access and egress durations */
+type viaJourneyLabel[ID UniqueGtfsIdLike] struct {
+	legs                      []RoundSegmentSpan[ID]
+	from_unique_stop_id       ID
+	to_unique_stop_id         ID
+	departure_time_in_seconds TimestampInSeconds
+	arrival_time_in_seconds   TimestampInSeconds
+	access_duration           TimestampInSeconds
+	egress_duration           TimestampInSeconds
+	penalty                   TimestampInSeconds
+	trips                     int
+}
+
+func SimpleRaptorViaDepartAt[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) []Journey[ID] {
+	part_input := prepareViaPartInput(input)
+	part_input.FromStops = input.FromStops
+	part_input.ToStops = []StopType{input.ViaStops[0].Stop}
+	part_input.AccessDurationsInSecondsByUniqueStopId = input.AccessDurationsInSecondsByUniqueStopId
+	labels := searchViaPart(part_input, input.TimeInSeconds, input.MaximumTransfers)
+	part_input.AccessDurationsInSecondsByUniqueStopId = nil
+
+	for index, via_stop := range input.ViaStops {
+		/* the next part starts at the via stop once we have dwelled there long enough */
+		part_input.FromStops = []StopType{via_stop.Stop}
+		if index == len(input.ViaStops)-1 {
+			part_input.ToStops = input.ToStops
+			part_input.EgressDurationsInSecondsByUniqueStopId = input.EgressDurationsInSecondsByUniqueStopId
+		} else {
+			part_input.ToStops = []StopType{input.ViaStops[index+1].Stop}
+		}
+		next_labels := []viaJourneyLabel[ID]{}
+		for _, label := range labels {
+			for _, part_label := range searchViaPart(part_input, label.arrival_time_in_seconds+int64(via_stop.MinimumDwellTimeInSeconds), input.MaximumTransfers-label.trips) {
+				next_labels = append(next_labels, joinViaJourneyLabels(label, part_label, int64(via_stop.MinimumDwellTimeInSeconds)))
+			}
+		}
+		labels = getParetoViaJourneyLabels(next_labels, RaptorModeDepartAt)
+	}
+	return RankJourneys(stitchViaJourneys(labels), input.Ranking)
+}
+
+func SimpleRaptorViaArriveBy[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) []Journey[ID] {
+	/* for arrive by we work backwards from the last via stop - the labels are the parts after the current via stop */
+	last_via_stop := input.ViaStops[len(input.ViaStops)-1]
+	part_input := prepareViaPartInput(input)
+	part_input.FromStops = []StopType{last_via_stop.Stop}
+	part_input.ToStops = input.ToStops
+	part_input.EgressDurationsInSecondsByUniqueStopId = input.EgressDurationsInSecondsByUniqueStopId
+	labels := searchViaPart(part_input, input.TimeInSeconds, input.MaximumTransfers)
+	part_input.EgressDurationsInSecondsByUniqueStopId = nil
+
+	for index := len(input.ViaStops) - 1; index >= 0; index-- {
+		/* the previous part needs to arrive at the via stop early enough to dwell there */
+		via_stop := input.ViaStops[index]
+		part_input.ToStops = []StopType{via_stop.Stop}
+		if index == 0 {
+			part_input.FromStops = input.FromStops
+			part_input.AccessDurationsInSecondsByUniqueStopId = input.AccessDurationsInSecondsByUniqueStopId
+		} else {
+			part_input.FromStops = []StopType{input.ViaStops[index-1].Stop}
+		}
+		next_labels := []viaJourneyLabel[ID]{}
+		for _, label := range labels {
+			for _, part_label := range searchViaPart(part_input, label.departure_time_in_seconds-int64(via_stop.MinimumDwellTimeInSeconds), input.MaximumTransfers-label.trips) {
+				next_labels = append(next_labels, joinViaJourneyLabels(part_label, label, int64(via_stop.MinimumDwellTimeInSeconds)))
+			}
+		}
+		labels = getParetoViaJourneyLabels(next_labels, RaptorModeArriveBy)
+	}
+	return RankJourneys(stitchViaJourneys(labels), input.Ranking)
+}
+
+/**
+ * searches a part of the via journey at the time with the trips which are left. the destinations which are also
+ * origins - ie. a via stop which is also the origin, the destination or the previous via stop - are reached without any legs
+ */
+func searchViaPart[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	part_input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+	time_in_seconds TimestampInSeconds,
+	maximum_trips int,
+) []viaJourneyLabel[ID] {
+	labels := []viaJourneyLabel[ID]{}
+	for _, to_stop := range part_input.ToStops {
+		unique_stop_id := to_stop.GetUniqueID()
+		if !slices.ContainsFunc(part_input.FromStops, func(from_stop StopType) bool { return from_stop.GetUniqueID() == unique_stop_id }) {
+			continue
+		}
+		label := viaJourneyLabel[ID]{
+			from_unique_stop_id:       unique_stop_id,
+			to_unique_stop_id:         unique_stop_id,
+			departure_time_in_seconds: time_in_seconds,
+			arrival_time_in_seconds:   time_in_seconds,
+			access_duration:           TimestampInSeconds(part_input.AccessDurationsInSecondsByUniqueStopId[unique_stop_id]),
+			egress_duration:           TimestampInSeconds(part_input.EgressDurationsInSecondsByUniqueStopId[unique_stop_id]),
+		}
+		if part_input.Mode == RaptorModeDepartAt {
+			label.arrival_time_in_seconds += label.access_duration + label.egress_duration
+		} else {
+			label.departure_time_in_seconds -= label.access_duration + label.egress_duration
+		}
+		labels = append(labels, label)
+	}
+	if maximum_trips <= 0 {
+		return labels
+	}
+
+	part_input.TimeInSeconds = time_in_seconds
+	part_input.MaximumTransfers = maximum_trips
+	for _, journey := range SimpleRaptor(part_input) {
+		label := viaJourneyLabel[ID]{
+			legs:                      journey.Legs,
+			from_unique_stop_id:       journey.FromUniqueStopID,
+			to_unique_stop_id:         journey.ToUniqueStopID,
+			departure_time_in_seconds: journey.DepartureTimeInSeconds,
+			arrival_time_in_seconds:   journey.ArrivalTimeInSeconds,
+			access_duration:           journey.AccessDurationInSeconds,
+			egress_duration:           journey.EgressDurationInSeconds,
+			penalty:                   journey.PenaltyInSeconds,
+		}
+		for _, leg := range journey.Legs {
+			if leg.ViaTrip != nil {
+				label.trips++
+			}
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+/**
+ * the label of the first part followed by the label of the second part after dwelling at the via stop between them
+ * a part without legs does not wait for the search time - it is at the via stop just in time for the other part
+ */
+func joinViaJourneyLabels[ID UniqueGtfsIdLike](first viaJourneyLabel[ID], second viaJourneyLabel[ID], dwell_time_in_seconds TimestampInSeconds) viaJourneyLabel[ID] {
+	if len(first.legs) == 0 {
+		first.departure_time_in_seconds, first.arrival_time_in_seconds = second.departure_time_in_seconds-dwell_time_in_seconds-(first.arrival_time_in_seconds-first.departure_time_in_seconds), second.departure_time_in_seconds-dwell_time_in_seconds
+	}
+	if len(second.legs) == 0 {
+		second.departure_time_in_seconds, second.arrival_time_in_seconds = first.arrival_time_in_seconds+dwell_time_in_seconds, first.arrival_time_in_seconds+dwell_time_in_seconds+(second.arrival_time_in_seconds-second.departure_time_in_seconds)
+	}
+	legs := make([]RoundSegmentSpan[ID], 0, len(first.legs)+len(second.legs))
+	legs = append(legs, first.legs...)
+	legs = append(legs, second.legs...)
+	return viaJourneyLabel[ID]{
+		legs:                      legs,
+		from_unique_stop_id:       first.from_unique_stop_id,
+		to_unique_stop_id:         second.to_unique_stop_id,
+		departure_time_in_seconds: first.departure_time_in_seconds,
+		arrival_time_in_seconds:   second.arrival_time_in_seconds,
+		access_duration:           first.access_duration + second.access_duration,
+		egress_duration:           first.egress_duration + second.egress_duration,
+		penalty:                   first.penalty + second.penalty,
+		trips:                     first.trips + second.trips,
+	}
+}
+
+/** the stop at which the label continues - the next part starts there for depart at and ends there for arrive by */
+func (label viaJourneyLabel[ID]) getFrontierStopID(mode RaptorMode) ID {
+	if mode == RaptorModeArriveBy {
+		return label.from_unique_stop_id
+	}
+	return label.to_unique_stop_id
+}
+
+/**
+ * keeps the labels which are not dominated by another label at the same stop - a label dominates when it is at the stop
+ * at least as early (or leaves it at least as late for arrive by) with at most as many trips
+ */
+func getParetoViaJourneyLabels[ID UniqueGtfsIdLike](labels []viaJourneyLabel[ID], mode RaptorMode) []viaJourneyLabel[ID] {
+	/* between labels with the same time and trips the other end of the journey and then the fewest legs decide */
+	isAtLeastAsGood := func(label viaJourneyLabel[ID], other viaJourneyLabel[ID]) bool {
+		if label.getFrontierStopID(mode) != other.getFrontierStopID(mode) || label.trips > other.trips {
+			return false
+		}
+		time, other_time := label.arrival_time_in_seconds, other.arrival_time_in_seconds
+		end_time, other_end_time := -label.departure_time_in_seconds, -other.departure_time_in_seconds
+		if mode == RaptorModeArriveBy {
+			time, other_time = -label.departure_time_in_seconds, -other.departure_time_in_seconds
+			end_time, other_end_time = label.arrival_time_in_seconds, other.arrival_time_in_seconds
+		}
+		if time != other_time || label.trips != other.trips {
+			return time <= other_time
+		}
+		if end_time != other_end_time {
+			return end_time < other_end_time
+		}
+		return len(label.legs) <= len(other.legs)
+	}
+
+	pareto_labels := []viaJourneyLabel[ID]{}
+	for index, label := range labels {
+		is_dominated := false
+		for other_index, other := range labels {
+			/* of the labels which are equally good only the first one is kept */
+			if other_index != index && isAtLeastAsGood(other, label) && (other_index < index || !isAtLeastAsGood(label, other)) {
+				is_dominated = true
+				break
+			}
+		}
+		if !is_dominated {
+			pareto_labels = append(pareto_labels, label)
+		}
+	}
+	return pareto_labels
+}
+
+/** builds the journeys of the labels which went through all the parts - a journey needs at least one leg */
+func stitchViaJourneys[ID UniqueGtfsIdLike](labels []viaJourneyLabel[ID]) []Journey[ID] {
+	journeys := []Journey[ID]{}
+	for _, label := range labels {
+		if len(label.legs) == 0 {
+			continue
+		}
+		journey := RoundSegment[ID]{Spans: label.legs, PenaltyInSeconds: label.penalty}.ToJourney()
+		journey.AccessDurationInSeconds = label.access_duration
+		journey.EgressDurationInSeconds = label.egress_duration
+		journey.DepartureTimeInSeconds = label.departure_time_in_seconds
+		journey.ArrivalTimeInSeconds = label.arrival_time_in_seconds
+		journeys = append(journeys, journey)
+	}
+	return journeys
+}
+
+/** prepares the lookups once so they can be shared by the searches of all the parts */
+func prepareViaPartInput[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) SimpleRaptorInput[ID, StopType, TransferType, StopTimeType] {
+	prepared_input := PrepareRaptorInput(input)
+	part_input := input.WithPreparedInput(prepared_input)
+	part_input.ViaStops = nil
+	part_input.AccessDurationsInSecondsByUniqueStopId = nil
+	part_input.EgressDurationsInSecondsByUniqueStopId = nil
+	part_input.Ranking = RaptorJourneyRanking{}
+	if part_input.Engine == RaptorEngineCsa && part_input.CsaConnections == nil {
+		connections := PrepareCsaConnections(prepared_input)
+		part_input.CsaConnections = &connections
+	}
+	if part_input.Engine == RaptorEngineTripBased && part_input.TripBasedTransfers == nil {
+		transfers := PrepareTripBasedTransfers(prepared_input)
+		part_input.TripBasedTransfers = &transfers
+	}
+	return part_input
+}
+
+func SimpleRaptorVia[ID UniqueGtfsIdLike, StopType GtfsStop[ID], TransferType GtfsTransfer[ID], StopTimeType GtfsStopTime[ID]](
+	input SimpleRaptorInput[ID, StopType, TransferType, StopTimeType],
+) []Journey[ID] {
+	if input.Mode == RaptorModeDepartAt {
+		return SimpleRaptorViaDepartAt(input)
+	}
+	return SimpleRaptorViaArriveBy(input)
+}